@@ -0,0 +1,635 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ============================================================================
+// *arr Helpers
+// ============================================================================
+
+// arrRequestFunc is the signature shared by sonarrRequest and radarrRequest.
+type arrRequestFunc func(ctx context.Context, method, endpoint string, body io.Reader) ([]byte, error)
+
+// waitForCommand polls an *arr command until it finishes or the timeout
+// elapses, returning the final status.
+func waitForCommand(ctx context.Context, request arrRequestFunc, commandID int, timeout time.Duration) (string, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		data, err := request(ctx, "GET", fmt.Sprintf("/command/%d", commandID), nil)
+		if err != nil {
+			return "", err
+		}
+
+		var cmd map[string]interface{}
+		json.Unmarshal(data, &cmd)
+
+		status, _ := cmd["status"].(string)
+		switch status {
+		case "completed", "failed", "aborted", "cancelled":
+			return status, nil
+		}
+		if time.Now().After(deadline) {
+			return status, nil
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+// runCommand starts an *arr command and waits for it to finish.
+func runCommand(ctx context.Context, request arrRequestFunc, payload map[string]interface{}, timeout time.Duration) (string, error) {
+	body, _ := json.Marshal(payload)
+	data, err := request(ctx, "POST", "/command", strings.NewReader(string(body)))
+	if err != nil {
+		return "", err
+	}
+
+	var result map[string]interface{}
+	json.Unmarshal(data, &result)
+
+	id, ok := result["id"].(float64)
+	if !ok {
+		return "", fmt.Errorf("no command ID in response")
+	}
+	return waitForCommand(ctx, request, int(id), timeout)
+}
+
+// searchCommand is the structured result of the search-triggering tools.
+// When quiet hours defer a search, Deferred is set and nothing has run yet.
+type searchCommand struct {
+	CommandIDs []int      `json:"commandIds"`
+	Seasons    []int      `json:"seasons,omitempty"` // seasons searched one by one
+	Deferred   bool       `json:"deferred,omitempty"`
+	RunAt      *time.Time `json:"runAt,omitempty"`
+}
+
+// releaseGrab is the structured result of the *_download_release tools.
+type releaseGrab struct {
+	GUID      string `json:"guid"`
+	IndexerID int    `json:"indexerId"`
+	SeriesID  int    `json:"seriesId,omitempty"`
+	MovieID   int    `json:"movieId,omitempty"`
+}
+
+// queueItem is one entry of the *_queue tools.
+type queueItem struct {
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	SizeLeft int64  `json:"sizeLeft"`
+}
+
+type queueList struct {
+	Items []queueItem `json:"items"`
+}
+
+// verifyResult is the structured result of the *_refresh_and_verify tools.
+type verifyResult struct {
+	Title    string   `json:"title"`
+	Refresh  string   `json:"refresh"` // final status of the refresh command
+	Episodes int      `json:"episodes,omitempty"`
+	Files    int      `json:"files"`
+	Issues   []string `json:"issues"`
+}
+
+// formatVerifyResult renders a verifyResult for the text result.
+func formatVerifyResult(v verifyResult, summary string) string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("**%s**: refresh %s, %s", v.Title, v.Refresh, summary))
+	if len(v.Issues) == 0 {
+		lines = append(lines, "No mismatches found.")
+	} else {
+		lines = append(lines, fmt.Sprintf("\nMismatches (%d):", len(v.Issues)))
+		for _, issue := range v.Issues {
+			lines = append(lines, "  "+issue)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// arrService describes a configured *arr instance for tools that work across
+// Sonarr and Radarr.
+type arrService struct {
+	Name     string
+	Request  arrRequestFunc
+	Category string // download client field holding this service's category
+}
+
+// arrServices returns the configured *arr services.
+func arrServices() []arrService {
+	var arrs []arrService
+	for _, svc := range configured {
+		if arr, ok := svc.(arrBackend); ok {
+			arrs = append(arrs, arr.arr())
+		}
+	}
+	return arrs
+}
+
+// providerField returns the value of a named entry in an *arr provider's
+// "fields" array (download clients, indexers, notifications, ...).
+func providerField(provider map[string]interface{}, name string) (interface{}, bool) {
+	fields, _ := provider["fields"].([]interface{})
+	for _, f := range fields {
+		field, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if field["name"] == name {
+			v, ok := field["value"]
+			return v, ok
+		}
+	}
+	return nil, false
+}
+
+// missingArtwork reports poster/fanart images absent from an *arr item.
+func missingArtwork(item map[string]interface{}) []string {
+	found := map[string]bool{}
+	if images, ok := item["images"].([]interface{}); ok {
+		for _, img := range images {
+			if m, ok := img.(map[string]interface{}); ok {
+				if ct, ok := m["coverType"].(string); ok {
+					found[ct] = true
+				}
+			}
+		}
+	}
+
+	var issues []string
+	for _, ct := range []string{"poster", "fanart"} {
+		if !found[ct] {
+			issues = append(issues, fmt.Sprintf("No %s artwork", ct))
+		}
+	}
+	return issues
+}
+
+// artwork holds an *arr item's poster and fanart: the remote (TMDB/TVDB)
+// URLs for the structured result, and downscaled images for rich clients.
+type artwork struct {
+	PosterURL string
+	FanartURL string
+	Images    []mcp.Content
+}
+
+// Sizes of the resized copies Sonarr and Radarr keep of each cover type
+var artworkSizes = map[string]string{"poster": "500", "fanart": "360"}
+
+// itemArtwork collects the poster and fanart of an *arr series or movie and,
+// if fetch is set, downloads them. It prefers the *arr's own resized copies
+// (baseURL and keys are the service's) and falls back to the remote URL,
+// requesting a smaller TMDB size. Images that can't be fetched are skipped.
+func itemArtwork(ctx context.Context, item map[string]interface{}, baseURL string, keys *apiKeyPair, fetch bool) artwork {
+	var art artwork
+	images, _ := item["images"].([]interface{})
+	for _, img := range images {
+		m, ok := img.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		coverType, _ := m["coverType"].(string)
+		size, ok := artworkSizes[coverType]
+		if !ok {
+			continue
+		}
+		local, _ := m["url"].(string)
+		remote, _ := m["remoteUrl"].(string)
+		if coverType == "poster" {
+			art.PosterURL = remote
+		} else {
+			art.FanartURL = remote
+		}
+		if !fetch {
+			continue
+		}
+
+		var data []byte
+		var err error
+		if local != "" {
+			// Image URLs already include the *arr's URL base, so only keep the origin
+			if base, perr := url.Parse(baseURL); perr == nil {
+				resized := strings.Replace(local, coverType+".", coverType+"-"+size+".", 1)
+				data, err = doAPIKeyRequest(ctx, "GET", base.Scheme+"://"+base.Host+resized, keys, nil)
+			}
+		}
+		if len(data) == 0 && remote != "" {
+			width := map[string]string{"poster": "/w342/", "fanart": "/w780/"}[coverType]
+			data, err = doRequest(ctx, "GET", strings.Replace(remote, "/original/", width, 1), nil, nil)
+		}
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		art.Images = append(art.Images, mcp.NewImageContent(base64.StdEncoding.EncodeToString(data), http.DetectContentType(data)))
+	}
+	return art
+}
+
+// registerSystemTools adds the admin-only restart and shutdown tools for an
+// *arr service, e.g. sonarr_restart and sonarr_shutdown.
+func registerSystemTools(s *server.MCPServer, prefix, service string, request arrRequestFunc) {
+	s.AddTool(
+		mcp.NewTool(prefix+"_restart",
+			mcp.WithDescription(fmt.Sprintf("Restart %s. Fixes many stuck states, but interrupts imports and searches in progress. Admin only.", service)),
+			mcp.WithBoolean("confirm", mcp.Required(), mcp.Description("Must be true to confirm the restart")),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithOutputSchema[systemActionResult](),
+		),
+		systemActionHandler(service, "restart", request),
+	)
+
+	s.AddTool(
+		mcp.NewTool(prefix+"_shutdown",
+			mcp.WithDescription(fmt.Sprintf("Shut down %s. It will stay down until restarted outside of this server. Admin only.", service)),
+			mcp.WithBoolean("confirm", mcp.Required(), mcp.Description("Must be true to confirm the shutdown")),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithOutputSchema[systemActionResult](),
+		),
+		systemActionHandler(service, "shutdown", request),
+	)
+}
+
+// systemActionResult is the structured result of a restart or shutdown.
+type systemActionResult struct {
+	Service string `json:"service"`
+	Action  string `json:"action"`
+}
+
+func systemActionHandler(service, action string, request arrRequestFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+		if confirm, _ := args["confirm"].(bool); !confirm {
+			return mcp.NewToolResultError(fmt.Sprintf("Not confirmed. Call again with confirm=true to %s %s.", action, service)), nil
+		}
+
+		if _, err := request(ctx, "POST", "/system/"+action, nil); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result := systemActionResult{Service: service, Action: action}
+		if action == "restart" {
+			return mcp.NewToolResultStructured(result, fmt.Sprintf("%s is restarting. It usually takes 10-30 seconds to come back.", service)), nil
+		}
+		return mcp.NewToolResultStructured(result, fmt.Sprintf("%s is shutting down.", service)), nil
+	}
+}
+
+// markFailedResult is the structured result of the *_mark_failed tools.
+type markFailedResult struct {
+	Service   string `json:"service"`
+	HistoryID int    `json:"historyId"`
+	Release   string `json:"release"`
+}
+
+// markFailedHandler marks a grab failed so the *arr blocklists the release,
+// removes the download per its failed-download settings, and searches again.
+// The grab is given by history_id, by download_id, or as the latest grab of
+// the item named by idArg ("series_id" or "movie_id"), looked up through
+// historyEndpoint (e.g. "/history/series?seriesId=%d").
+func markFailedHandler(service string, request arrRequestFunc, idArg, historyEndpoint string) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+		result := markFailedResult{Service: service}
+
+		var records []map[string]interface{}
+		switch {
+		case args["history_id"] != nil:
+			result.HistoryID = int(args["history_id"].(float64))
+		case args["download_id"] != nil:
+			downloadID, _ := args["download_id"].(string)
+			data, err := request(ctx, "GET", "/history?pageSize=50&downloadId="+url.QueryEscape(strings.ToUpper(downloadID)), nil)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			var page struct {
+				Records []map[string]interface{} `json:"records"`
+			}
+			json.Unmarshal(data, &page)
+			records = page.Records
+		case args[idArg] != nil:
+			data, err := request(ctx, "GET", fmt.Sprintf(historyEndpoint, int(args[idArg].(float64))), nil)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			json.Unmarshal(data, &records)
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("Give history_id, download_id, or %s", idArg)), nil
+		}
+
+		if result.HistoryID == 0 {
+			grab := latestGrab(records)
+			if grab == nil {
+				return mcp.NewToolResultError("No grab found in history to mark as failed"), nil
+			}
+			id, _ := grab["id"].(float64)
+			result.HistoryID = int(id)
+			result.Release, _ = grab["sourceTitle"].(string)
+		}
+
+		if _, err := request(ctx, "POST", fmt.Sprintf("/history/failed/%d", result.HistoryID), nil); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		release := fmt.Sprintf("history item %d", result.HistoryID)
+		if result.Release != "" {
+			release = result.Release
+		}
+		return mcp.NewToolResultStructured(result, fmt.Sprintf("Marked %s as failed. %s will blocklist it and, if automatic redownload is enabled, search for another release.", release, service)), nil
+	}
+}
+
+// latestGrab returns the most recent "grabbed" record in history, or nil.
+func latestGrab(records []map[string]interface{}) map[string]interface{} {
+	var latest map[string]interface{}
+	var latestDate time.Time
+	for _, r := range records {
+		if r["eventType"] != "grabbed" {
+			continue
+		}
+		date, _ := r["date"].(string)
+		t, _ := time.Parse(time.RFC3339, date)
+		if latest == nil || t.After(latestDate) {
+			latest, latestDate = r, t
+		}
+	}
+	return latest
+}
+
+// How long a service's blocklist is reused between interactive searches. A
+// release marked failed shows up in the item's history right away, so only
+// entries added in Sonarr/Radarr themselves wait for the refresh.
+const blocklistCacheTTL = 5 * time.Minute
+
+// cachedBlocklist is a service's blocklist records from its last fetch.
+type cachedBlocklist struct {
+	records []map[string]interface{}
+	expires time.Time
+}
+
+var (
+	blocklistMu    sync.Mutex
+	blocklistCache = map[string]cachedBlocklist{}
+)
+
+// blocklist returns a service's blocklist records, fetching them at most
+// once per blocklistCacheTTL.
+func blocklist(ctx context.Context, service string, request arrRequestFunc) ([]map[string]interface{}, error) {
+	blocklistMu.Lock()
+	defer blocklistMu.Unlock()
+	if c, ok := blocklistCache[service]; ok && time.Now().Before(c.expires) {
+		return c.records, nil
+	}
+	data, err := request(ctx, "GET", "/blocklist?pageSize=1000", nil)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Records []map[string]interface{} `json:"records"`
+	}
+	json.Unmarshal(data, &result)
+	blocklistCache[service] = cachedBlocklist{records: result.Records, expires: time.Now().Add(blocklistCacheTTL)}
+	return result.Records, nil
+}
+
+// knownBadReleases returns release titles (lowercased) that previously failed
+// to download or were blocklisted for one series/movie, with a short reason.
+// historyEndpoint is the per-item history endpoint, e.g.
+// "/history/series?seriesId=42"; idField is "seriesId" or "movieId".
+func knownBadReleases(ctx context.Context, service string, request arrRequestFunc, historyEndpoint, idField string, id int) map[string]string {
+	bad := map[string]string{}
+
+	if data, err := request(ctx, "GET", historyEndpoint, nil); err == nil {
+		var history []map[string]interface{}
+		json.Unmarshal(data, &history)
+		for _, h := range history {
+			if h["eventType"] != "downloadFailed" {
+				continue
+			}
+			title, _ := h["sourceTitle"].(string)
+			date, _ := h["date"].(string)
+			bad[strings.ToLower(title)] = "previously failed to download " + date[:min(10, len(date))]
+		}
+	}
+
+	if records, err := blocklist(ctx, service, request); err == nil {
+		for _, item := range records {
+			if itemID, _ := item[idField].(float64); int(itemID) != id {
+				continue
+			}
+			title, _ := item["sourceTitle"].(string)
+			reason := "blocklisted"
+			if msg, ok := item["message"].(string); ok && msg != "" {
+				reason += ": " + msg
+			}
+			bad[strings.ToLower(title)] = reason
+		}
+	}
+
+	return bad
+}
+
+// release is one interactive search result.
+type release struct {
+	GUID        string  `json:"guid"`
+	Title       string  `json:"title"`
+	IndexerID   int     `json:"indexerId"`
+	Indexer     string  `json:"indexer"`
+	Size        int64   `json:"size"`
+	Seeders     int     `json:"seeders"`
+	PublishDate string  `json:"publishDate,omitempty"`
+	Age         string  `json:"age,omitempty"`
+	Warning     string  `json:"warning,omitempty"`   // why the release is known-bad
+	Mbps        float64 `json:"mbps,omitempty"`      // estimated video bitrate
+	Transcode   string  `json:"transcode,omitempty"` // why it would need transcoding
+}
+
+// releaseList is the structured result of the *_get_releases tools. Only the
+// first releases are included; Total counts all of them.
+type releaseList struct {
+	Pending          bool      `json:"pending,omitempty"` // still searching; releases are from the previous search, if any
+	Total            int       `json:"total"`
+	Flagged          int       `json:"flagged"`
+	NeedsTranscode   int       `json:"needsTranscode,omitempty"`
+	ActiveTranscodes *int      `json:"activeTranscodes,omitempty"` // Jellyfin streams transcoding now
+	Releases         []release `json:"releases"`
+}
+
+// Releases shown per interactive search
+const maxReleases = 20
+
+// parseReleases converts interactive search results, including each
+// release's age and a warning for releases in bad (see knownBadReleases).
+func parseReleases(releases []map[string]interface{}, bad map[string]string) releaseList {
+	list := releaseList{Total: len(releases), Releases: []release{}}
+	for i, r := range releases {
+		if i >= maxReleases {
+			break
+		}
+		rel := release{
+			Title:     r["title"].(string),
+			Size:      int64(r["size"].(float64)),
+			GUID:      r["guid"].(string),
+			IndexerID: int(r["indexerId"].(float64)),
+			Indexer:   r["indexer"].(string),
+		}
+		if s, ok := r["seeders"].(float64); ok {
+			rel.Seeders = int(s)
+		}
+		if h, ok := r["ageHours"].(float64); ok && h < 48 {
+			rel.Age = fmt.Sprintf("%dh old", int(h))
+		} else if d, ok := r["age"].(float64); ok {
+			rel.Age = fmt.Sprintf("%dd old", int(d))
+		}
+		if p, ok := r["publishDate"].(string); ok && len(p) >= 10 {
+			rel.PublishDate = p[:10]
+		}
+		if reason, ok := bad[strings.ToLower(rel.Title)]; ok {
+			rel.Warning = reason
+			list.Flagged++
+		}
+		list.Releases = append(list.Releases, rel)
+	}
+	return list
+}
+
+// formatReleases renders a releaseList for the text result.
+func formatReleases(list releaseList) []string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("Available releases (%d):\n", list.Total))
+
+	for _, r := range list.Releases {
+		published := ""
+		if r.PublishDate != "" {
+			published = fmt.Sprintf(" | Published: %s (%s)", r.PublishDate, r.Age)
+		}
+		lines = append(lines, fmt.Sprintf("  [%d seeders] %s (%dMB) - %s\n    GUID: %s | Indexer: %d%s", r.Seeders, r.Title[:min(60, len(r.Title))], r.Size/1024/1024, r.Indexer, r.GUID, r.IndexerID, published))
+		if r.Warning != "" {
+			lines = append(lines, "    WARNING: "+r.Warning+" - avoid grabbing this release again")
+		}
+		if r.Transcode != "" {
+			lines = append(lines, "    TRANSCODE: "+r.Transcode)
+		}
+	}
+	if list.Total > len(list.Releases) {
+		lines = append(lines, fmt.Sprintf("\n  ... and %d more", list.Total-len(list.Releases)))
+	}
+
+	if list.Flagged > 0 {
+		lines = append(lines, fmt.Sprintf("\n%d release(s) flagged as known-bad.", list.Flagged))
+	}
+	if list.NeedsTranscode > 0 {
+		line := fmt.Sprintf("\n%d release(s) would likely need transcoding to play.", list.NeedsTranscode)
+		if n := list.ActiveTranscodes; n != nil && *n > 0 {
+			line += fmt.Sprintf(" Jellyfin is already transcoding %d stream(s); prefer a release that direct plays.", *n)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// exclusion is an entry that blocks a title from being added or requested.
+type exclusion struct {
+	Service string // "Radarr", "Sonarr", or "Jellyseerr"
+	ID      int
+	Title   string
+}
+
+// findExclusions checks Radarr's exclusion list (movies), Sonarr's import
+// list exclusions (TV), and Jellyseerr's blacklist for a TMDB ID. Lookups
+// against services that are unreachable are skipped.
+func findExclusions(ctx context.Context, mediaType string, tmdbID int) []exclusion {
+	var found []exclusion
+
+	if mediaType == "movie" && isConfigured(radarr) {
+		if data, err := radarrRequest(ctx, "GET", "/exclusions", nil); err == nil {
+			var items []map[string]interface{}
+			json.Unmarshal(data, &items)
+			for _, e := range items {
+				if id, _ := e["tmdbId"].(float64); int(id) == tmdbID {
+					title, _ := e["movieTitle"].(string)
+					found = append(found, exclusion{Service: "Radarr", ID: int(e["id"].(float64)), Title: title})
+				}
+			}
+		}
+	}
+
+	if mediaType == "tv" && isConfigured(sonarr) {
+		// Sonarr keys exclusions by TVDB ID, which Jellyseerr can resolve
+		tvdbID := 0
+		if data, err := jellyseerrRequest(ctx, "GET", fmt.Sprintf("/tv/%d", tmdbID), nil); err == nil {
+			var show map[string]interface{}
+			json.Unmarshal(data, &show)
+			if ext, ok := show["externalIds"].(map[string]interface{}); ok {
+				if id, ok := ext["tvdbId"].(float64); ok {
+					tvdbID = int(id)
+				}
+			}
+		}
+		if tvdbID > 0 {
+			if data, err := sonarrRequest(ctx, "GET", "/importlistexclusion", nil); err == nil {
+				var items []map[string]interface{}
+				json.Unmarshal(data, &items)
+				for _, e := range items {
+					if id, _ := e["tvdbId"].(float64); int(id) == tvdbID {
+						title, _ := e["title"].(string)
+						found = append(found, exclusion{Service: "Sonarr", ID: int(e["id"].(float64)), Title: title})
+					}
+				}
+			}
+		}
+	}
+
+	if data, err := jellyseerrRequest(ctx, "GET", fmt.Sprintf("/blacklist/%d", tmdbID), nil); err == nil {
+		var entry map[string]interface{}
+		json.Unmarshal(data, &entry)
+		if t, _ := entry["mediaType"].(string); t == "" || t == mediaType {
+			title, _ := entry["title"].(string)
+			found = append(found, exclusion{Service: "Jellyseerr", ID: tmdbID, Title: title})
+		}
+	}
+
+	return found
+}
+
+// removeExclusion deletes an exclusion found by findExclusions.
+func removeExclusion(ctx context.Context, e exclusion) error {
+	var err error
+	switch e.Service {
+	case "Radarr":
+		_, err = radarrRequest(ctx, "DELETE", fmt.Sprintf("/exclusions/%d", e.ID), nil)
+	case "Sonarr":
+		_, err = sonarrRequest(ctx, "DELETE", fmt.Sprintf("/importlistexclusion/%d", e.ID), nil)
+	case "Jellyseerr":
+		_, err = jellyseerrRequest(ctx, "DELETE", fmt.Sprintf("/blacklist/%d", e.ID), nil)
+	}
+	return err
+}
+
+// describeExclusions explains why a title is blocked and how to unblock it.
+func describeExclusions(title string, excl []exclusion) string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("%s can't be requested because it is excluded:", title))
+	for _, e := range excl {
+		switch e.Service {
+		case "Jellyseerr":
+			lines = append(lines, "  - on Jellyseerr's blacklist")
+		case "Radarr":
+			lines = append(lines, fmt.Sprintf("  - on Radarr's exclusion list (exclusion ID %d)", e.ID))
+		case "Sonarr":
+			lines = append(lines, fmt.Sprintf("  - on Sonarr's import list exclusions (exclusion ID %d)", e.ID))
+		}
+	}
+	lines = append(lines, "Call again with remove_exclusion=true to remove the exclusion and request it.")
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// Availability Matrix
+// ============================================================================
+
+// Titles accepted per availability check
+const maxAvailabilityTitles = 50
+
+// How long a title's search match (and the status that came with it) is
+// reused; kept short since statuses change as downloads finish.
+const availabilityCacheTTL = 5 * time.Minute
+
+// titleAvailability is one row of the matrix. State is "available",
+// "partial", "downloading", "requested", "absent", "not found", or "error",
+// with Error saying what went wrong looking the title up.
+type titleAvailability struct {
+	Query     string `json:"query"`
+	Title     string `json:"title,omitempty"`
+	Year      string `json:"year,omitempty"`
+	MediaType string `json:"mediaType,omitempty"`
+	TmdbID    int    `json:"tmdbId,omitempty"`
+	State     string `json:"state"`
+	Error     string `json:"error,omitempty"`
+}
+
+// availabilityMatrix is the structured result of ultimarr_availability.
+type availabilityMatrix struct {
+	Titles []titleAvailability `json:"titles"`
+	Counts map[string]int      `json:"counts"`
+}
+
+// availabilityMatch is a title's best search result with what's needed to
+// tell whether it is downloading.
+type availabilityMatch struct {
+	row     titleAvailability
+	tvdbID  int
+	expires time.Time
+}
+
+var (
+	availabilityMu    sync.Mutex
+	availabilityCache = map[string]availabilityMatch{}
+)
+
+func handleUltimarrAvailability(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	mediaType, _ := args["media_type"].(string)
+	var queries []string
+	if titles, ok := args["titles"].([]interface{}); ok {
+		for _, t := range titles {
+			if s, ok := t.(string); ok && strings.TrimSpace(s) != "" {
+				queries = append(queries, strings.TrimSpace(s))
+			}
+		}
+	}
+	if len(queries) == 0 {
+		return mcp.NewToolResultError("Give at least one title"), nil
+	}
+	if len(queries) > maxAvailabilityTitles {
+		return mcp.NewToolResultError(fmt.Sprintf("At most %d titles can be checked at once", maxAvailabilityTitles)), nil
+	}
+
+	// Queues are fetched once and matched by ID while the searches run
+	var downloading map[string]bool
+	var queueWG sync.WaitGroup
+	queueWG.Add(1)
+	go func() {
+		defer queueWG.Done()
+		downloading = queuedIDs(ctx)
+	}()
+
+	matches := make([]availabilityMatch, len(queries))
+	errs := make([]error, len(queries))
+	sem := make(chan struct{}, titleLookupWorkers)
+	var wg sync.WaitGroup
+	for i, q := range queries {
+		wg.Add(1)
+		go func(i int, q string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			matches[i], errs[i] = matchTitle(ctx, q, mediaType)
+		}(i, q)
+	}
+	wg.Wait()
+	queueWG.Wait()
+
+	matrix := availabilityMatrix{Titles: []titleAvailability{}, Counts: map[string]int{}}
+	width := 0
+	for _, q := range queries {
+		width = max(width, len(q))
+	}
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed == len(queries) {
+		return mcp.NewToolResultError(errs[0].Error()), nil
+	}
+
+	var lines []string
+	for i, m := range matches {
+		row := m.row
+		if errs[i] != nil {
+			row = titleAvailability{Query: queries[i], State: "error", Error: errs[i].Error()}
+		}
+		if row.State == "requested" || row.State == "absent" {
+			key := fmt.Sprintf("tmdb:%d", row.TmdbID)
+			if row.MediaType == "tv" {
+				key = fmt.Sprintf("tvdb:%d", m.tvdbID)
+			}
+			if downloading[key] {
+				row.State = "downloading"
+			}
+		}
+		matrix.Titles = append(matrix.Titles, row)
+		matrix.Counts[row.State]++
+
+		line := fmt.Sprintf("  %-11s  %-*s", row.State, width, row.Query)
+		if row.Title != "" {
+			line += fmt.Sprintf("  → %s (%s, %s)", row.Title, row.Year, row.MediaType)
+		}
+		if row.Error != "" {
+			line += "  " + row.Error
+		}
+		lines = append(lines, line)
+	}
+
+	var summary []string
+	for _, state := range []string{"available", "partial", "downloading", "requested", "absent", "not found", "error"} {
+		if n := matrix.Counts[state]; n > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", n, state))
+		}
+	}
+	text := fmt.Sprintf("Availability of %d titles: %s\n\n%s", len(queries), strings.Join(summary, ", "), strings.Join(lines, "\n"))
+	return mcp.NewToolResultStructured(matrix, text), nil
+}
+
+// matchTitle finds the best Jellyseerr match for a title like "Oppenheimer"
+// or "Dune (2021)", preferring the given year and media type.
+func matchTitle(ctx context.Context, query, mediaType string) (availabilityMatch, error) {
+	key := mediaType + "|" + strings.ToLower(query)
+	availabilityMu.Lock()
+	cached, ok := availabilityCache[key]
+	availabilityMu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached, nil
+	}
+
+	match := availabilityMatch{row: titleAvailability{Query: query, State: "not found"}}
+	name, year := stripYear(normalizeQuery(query))
+	results, err := jellyseerrSearch(ctx, name)
+	if err != nil {
+		return match, err
+	}
+
+	var best map[string]interface{}
+	for _, r := range results {
+		item := r.(map[string]interface{})
+		t, _ := item["mediaType"].(string)
+		if t != "movie" && t != "tv" || (mediaType != "" && t != mediaType) {
+			continue
+		}
+		_, y := mediaNameYear(item)
+		if best == nil || (year != "" && y == year) {
+			best = item
+		}
+		if year == "" || y == year {
+			break
+		}
+	}
+
+	if best != nil {
+		row := &match.row
+		row.Title, row.Year = mediaNameYear(best)
+		row.MediaType, _ = best["mediaType"].(string)
+		id, _ := best["id"].(float64)
+		row.TmdbID = int(id)
+		rememberTitle(row.MediaType, row.TmdbID, row.Title)
+
+		row.State = "absent"
+		if mi, ok := best["mediaInfo"].(map[string]interface{}); ok {
+			tvdbID, _ := mi["tvdbId"].(float64)
+			match.tvdbID = int(tvdbID)
+			status, _ := mi["status"].(float64)
+			switch mediaStatusNames[int(status)] {
+			case "available":
+				row.State = "available"
+			case "partial":
+				row.State = "partial"
+			case "pending", "processing":
+				row.State = "requested"
+			}
+		}
+	}
+
+	match.expires = time.Now().Add(availabilityCacheTTL)
+	availabilityMu.Lock()
+	for k, m := range availabilityCache {
+		if time.Now().After(m.expires) {
+			delete(availabilityCache, k)
+		}
+	}
+	availabilityCache[key] = match
+	availabilityMu.Unlock()
+	return match, nil
+}
+
+// queuedIDs returns the titles in the Sonarr and Radarr download queues,
+// keyed like "tvdb:81189" and "tmdb:603". Unreachable services are skipped.
+func queuedIDs(ctx context.Context) map[string]bool {
+	ids := map[string]bool{}
+	for _, svc := range arrServices() {
+		field, provider, include := "series", "tvdb", "includeSeries=true"
+		if svc.Name == "Radarr" {
+			field, provider, include = "movie", "tmdb", "includeMovie=true"
+		}
+		data, err := svc.Request(ctx, "GET", "/queue?pageSize=500&"+include, nil)
+		if err != nil {
+			continue
+		}
+		var queue struct {
+			Records []map[string]interface{} `json:"records"`
+		}
+		json.Unmarshal(data, &queue)
+		for _, r := range queue.Records {
+			media, _ := r[field].(map[string]interface{})
+			if id, _ := media[provider+"Id"].(float64); id > 0 {
+				ids[fmt.Sprintf("%s:%d", provider, int(id))] = true
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// Bandwidth
+// ============================================================================
+
+// transferSample is a snapshot of qBittorrent's all-time transfer counters.
+// qBittorrent doesn't keep per-period totals, so they are computed from the
+// difference between samples.
+type transferSample struct {
+	Time       time.Time `json:"time"`
+	Downloaded int64     `json:"downloaded"`
+	Uploaded   int64     `json:"uploaded"`
+}
+
+// How long transfer samples are kept
+const bandwidthKeepFor = 400 * 24 * time.Hour
+
+var (
+	bandwidthMu     sync.Mutex
+	transferSamples []transferSample
+	bandwidthLoaded bool
+)
+
+func bandwidthPath() string {
+	return filepath.Join(config.DataDir, "bandwidth.json")
+}
+
+// startBandwidthSampling registers the poller that records qBittorrent
+// transfer counters, if qBittorrent is configured.
+func startBandwidthSampling() {
+	if !isConfigured(qbittorrent) {
+		return
+	}
+	addPoller(func(ctx context.Context) { sampleQbittorrent(ctx) })
+}
+
+// sampleQbittorrent reads the current counters, records a sample (at most one
+// every 15 minutes) and returns the latest server state.
+func sampleQbittorrent(ctx context.Context) (map[string]interface{}, error) {
+	data, err := qbittorrentRequest(ctx, "GET", "/sync/maindata", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]interface{}
+	json.Unmarshal(data, &result)
+	state, _ := result["server_state"].(map[string]interface{})
+	if state == nil {
+		return nil, fmt.Errorf("qBittorrent returned no server state")
+	}
+
+	dl, _ := state["alltime_dl"].(float64)
+	ul, _ := state["alltime_ul"].(float64)
+
+	bandwidthMu.Lock()
+	defer bandwidthMu.Unlock()
+
+	loadBandwidthLocked()
+	now := time.Now()
+	if n := len(transferSamples); n == 0 || now.Sub(transferSamples[n-1].Time) >= 15*time.Minute {
+		transferSamples = append(transferSamples, transferSample{Time: now, Downloaded: int64(dl), Uploaded: int64(ul)})
+
+		cutoff := now.Add(-bandwidthKeepFor)
+		for len(transferSamples) > 0 && transferSamples[0].Time.Before(cutoff) {
+			transferSamples = transferSamples[1:]
+		}
+		saveBandwidthLocked()
+	}
+
+	return state, nil
+}
+
+func loadBandwidthLocked() {
+	if bandwidthLoaded {
+		return
+	}
+	bandwidthLoaded = true
+	if data, err := os.ReadFile(bandwidthPath()); err == nil {
+		json.Unmarshal(data, &transferSamples)
+	}
+}
+
+func saveBandwidthLocked() error {
+	if err := os.MkdirAll(config.DataDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(transferSamples)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(bandwidthPath(), data, 0o644)
+}
+
+// transferSince returns how much was transferred since start, based on the
+// last sample taken at or before start. If sampling began after start, the
+// earliest sample is used and its time returned so callers can say so.
+func transferSince(start time.Time, current transferSample) (int64, int64, time.Time) {
+	bandwidthMu.Lock()
+	defer bandwidthMu.Unlock()
+
+	if len(transferSamples) == 0 {
+		return 0, 0, current.Time
+	}
+
+	base := transferSamples[0]
+	for _, s := range transferSamples {
+		if s.Time.After(start) {
+			break
+		}
+		base = s
+	}
+
+	// Counters reset if qBittorrent's statistics are cleared
+	if current.Downloaded < base.Downloaded || current.Uploaded < base.Uploaded {
+		return current.Downloaded, current.Uploaded, base.Time
+	}
+	return current.Downloaded - base.Downloaded, current.Uploaded - base.Uploaded, base.Time
+}
+
+// bandwidthReport is the structured result of ultimarr_bandwidth.
+type bandwidthReport struct {
+	Clients []clientTransfer `json:"clients"`
+}
+
+// clientTransfer is one download client's current speeds (bytes per second)
+// and transfer totals (bytes) per period.
+type clientTransfer struct {
+	Client        string           `json:"client"`
+	Error         string           `json:"error,omitempty"`
+	DownloadSpeed int64            `json:"downloadSpeed"`
+	UploadSpeed   int64            `json:"uploadSpeed"`
+	Periods       []transferPeriod `json:"periods"`
+}
+
+// transferPeriod is the amount transferred in one period. TrackedSince is set
+// when sampling began after the period started, so the totals are partial.
+type transferPeriod struct {
+	Label        string     `json:"label"`
+	Downloaded   int64      `json:"downloaded"`
+	Uploaded     int64      `json:"uploaded"`
+	TrackedSince *time.Time `json:"trackedSince,omitempty"`
+}
+
+func handleUltimarrBandwidth(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	report := bandwidthReport{Clients: []clientTransfer{}}
+	var lines []string
+	if isConfigured(qbittorrent) {
+		transfer, clientLines := qbittorrentBandwidth(ctx)
+		report.Clients = append(report.Clients, transfer)
+		lines = append(lines, clientLines...)
+	}
+	if isConfigured(sabnzbd) {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		transfer, clientLines := sabnzbdBandwidth(ctx)
+		report.Clients = append(report.Clients, transfer)
+		lines = append(lines, clientLines...)
+	}
+
+	return mcp.NewToolResultStructured(report, strings.Join(lines, "\n")), nil
+}
+
+func qbittorrentBandwidth(ctx context.Context) (clientTransfer, []string) {
+	transfer := clientTransfer{Client: "qBittorrent", Periods: []transferPeriod{}}
+	state, err := sampleQbittorrent(ctx)
+	if err != nil {
+		transfer.Error = err.Error()
+		return transfer, []string{"qBittorrent: " + err.Error()}
+	}
+
+	dlSpeed, _ := state["dl_info_speed"].(float64)
+	ulSpeed, _ := state["up_info_speed"].(float64)
+	dl, _ := state["alltime_dl"].(float64)
+	ul, _ := state["alltime_ul"].(float64)
+
+	now := time.Now()
+	current := transferSample{Time: now, Downloaded: int64(dl), Uploaded: int64(ul)}
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	transfer.DownloadSpeed, transfer.UploadSpeed = int64(dlSpeed), int64(ulSpeed)
+	lines := []string{
+		"qBittorrent:",
+		fmt.Sprintf("  Current: %s/s down, %s/s up", formatBytes(int64(dlSpeed)), formatBytes(int64(ulSpeed))),
+	}
+
+	periods := []struct {
+		Label string
+		Start time.Time
+	}{
+		{"Today", midnight},
+		{"Last 7 days", now.AddDate(0, 0, -7)},
+		{"This month", monthStart},
+	}
+	for _, p := range periods {
+		down, up, since := transferSince(p.Start, current)
+		period := transferPeriod{Label: p.Label, Downloaded: down, Uploaded: up}
+		line := fmt.Sprintf("  %s: %s down, %s up", p.Label, formatBytes(down), formatBytes(up))
+		if since.After(p.Start) {
+			line += fmt.Sprintf(" (tracked since %s)", since.Format("2006-01-02 15:04"))
+			period.TrackedSince = &since
+		}
+		lines = append(lines, line)
+		transfer.Periods = append(transfer.Periods, period)
+	}
+	lines = append(lines, fmt.Sprintf("  All time: %s down, %s up", formatBytes(int64(dl)), formatBytes(int64(ul))))
+	transfer.Periods = append(transfer.Periods, transferPeriod{Label: "All time", Downloaded: int64(dl), Uploaded: int64(ul)})
+
+	return transfer, lines
+}
+
+func sabnzbdBandwidth(ctx context.Context) (clientTransfer, []string) {
+	transfer := clientTransfer{Client: "SABnzbd", Periods: []transferPeriod{}}
+	data, err := sabnzbdRequest(ctx, "server_stats", nil)
+	if err != nil {
+		transfer.Error = err.Error()
+		return transfer, []string{"SABnzbd: " + err.Error()}
+	}
+	var stats map[string]interface{}
+	json.Unmarshal(data, &stats)
+
+	lines := []string{"SABnzbd:"}
+
+	if data, err := sabnzbdRequest(ctx, "queue", nil); err == nil {
+		var result map[string]interface{}
+		json.Unmarshal(data, &result)
+		if queue, ok := result["queue"].(map[string]interface{}); ok {
+			kbps, _ := queue["kbpersec"].(string)
+			if v, err := strconv.ParseFloat(kbps, 64); err == nil {
+				transfer.DownloadSpeed = int64(v * 1024)
+				lines = append(lines, fmt.Sprintf("  Current: %s/s down", formatBytes(transfer.DownloadSpeed)))
+			}
+		}
+	}
+
+	for _, p := range []struct{ Label, Key string }{
+		{"Today", "day"},
+		{"This week", "week"},
+		{"This month", "month"},
+		{"All time", "total"},
+	} {
+		v, _ := stats[p.Key].(float64)
+		lines = append(lines, fmt.Sprintf("  %s: %s down", p.Label, formatBytes(int64(v))))
+		transfer.Periods = append(transfer.Periods, transferPeriod{Label: p.Label, Downloaded: int64(v)})
+	}
+
+	return transfer, lines
+}
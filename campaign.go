@@ -0,0 +1,416 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ============================================================================
+// Upgrade Campaigns
+// ============================================================================
+
+// An upgrade campaign searches for better releases of everything below its
+// quality profile's cutoff, a batch at a time, so indexers and download
+// clients aren't flooded by one huge search. Campaigns are saved in the data
+// directory and resume after a restart.
+
+const (
+	defaultCampaignBatch    = 10
+	maxCampaignBatch        = 100
+	defaultCampaignInterval = 15 // minutes
+	minCampaignInterval     = 5
+)
+
+// upgradeCampaign is one service's campaign. Pending holds the episode (Sonarr)
+// or movie (Radarr) IDs not searched yet.
+type upgradeCampaign struct {
+	Service         string     `json:"service"`
+	Total           int        `json:"total"`
+	Pending         []int      `json:"pending"`
+	BatchSize       int        `json:"batchSize"`
+	IntervalMinutes int        `json:"intervalMinutes"`
+	StartedAt       time.Time  `json:"startedAt"`
+	NextBatch       time.Time  `json:"nextBatch"`
+	LastCommandID   int        `json:"lastCommandId,omitempty"`
+	FinishedAt      *time.Time `json:"finishedAt,omitempty"`
+	Errors          []string   `json:"errors,omitempty"` // most recent last
+}
+
+// Errors kept per campaign
+const maxCampaignErrors = 5
+
+var (
+	campaignMu sync.Mutex
+	campaigns  = map[string]*upgradeCampaign{}
+)
+
+// campaignServices describes how each *arr lists and searches cutoff-unmet items.
+var campaignServices = map[string]struct {
+	Request arrRequestFunc
+	Command string // search command name
+	IDsKey  string // command field holding the IDs
+	Unit    string
+}{
+	"Sonarr": {sonarrRequest, "EpisodeSearch", "episodeIds", "episodes"},
+	"Radarr": {radarrRequest, "MoviesSearch", "movieIds", "movies"},
+}
+
+func campaignsPath() string {
+	return filepath.Join(config.DataDir, "campaigns.json")
+}
+
+// loadCampaigns reads saved campaigns and starts the loop that runs them.
+func loadCampaigns() error {
+	go campaignLoop()
+
+	data, err := os.ReadFile(campaignsPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	campaignMu.Lock()
+	defer campaignMu.Unlock()
+	return json.Unmarshal(data, &campaigns)
+}
+
+func saveCampaignsLocked() error {
+	if err := os.MkdirAll(config.DataDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(campaigns, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := campaignsPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, campaignsPath())
+}
+
+func campaignLoop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		campaignMu.Lock()
+		var due []string
+		for name, c := range campaigns {
+			if c.FinishedAt == nil && !now.Before(c.NextBatch) {
+				due = append(due, name)
+			}
+		}
+		campaignMu.Unlock()
+
+		for _, name := range due {
+			runCampaignBatch(context.Background(), name, now)
+		}
+	}
+}
+
+// runCampaignBatch searches the next batch of a campaign. A batch is held
+// back while the previous one is still running or during quiet hours.
+func runCampaignBatch(ctx context.Context, name string, now time.Time) {
+	svc := campaignServices[name]
+	campaignMu.Lock()
+	c := campaigns[name]
+	if c == nil || c.FinishedAt != nil {
+		campaignMu.Unlock()
+		return
+	}
+	interval := time.Duration(c.IntervalMinutes) * time.Minute
+	lastCommand := c.LastCommandID
+	campaignMu.Unlock()
+
+	if config.QuietHours != nil {
+		if active, until := config.QuietHours.active(now); active {
+			setNextBatch(name, until)
+			return
+		}
+	}
+	if lastCommand > 0 {
+		if data, err := svc.Request(ctx, "GET", fmt.Sprintf("/command/%d", lastCommand), nil); err == nil {
+			var cmd map[string]interface{}
+			json.Unmarshal(data, &cmd)
+			if status, _ := cmd["status"].(string); status == "queued" || status == "started" {
+				setNextBatch(name, now.Add(interval))
+				return
+			}
+		}
+	}
+
+	campaignMu.Lock()
+	batch := c.Pending[:min(c.BatchSize, len(c.Pending))]
+	campaignMu.Unlock()
+
+	body, _ := json.Marshal(map[string]interface{}{"name": svc.Command, svc.IDsKey: batch})
+	data, err := svc.Request(ctx, "POST", "/command", strings.NewReader(string(body)))
+
+	campaignMu.Lock()
+	defer campaignMu.Unlock()
+	if campaigns[name] != c {
+		return // stopped meanwhile
+	}
+	c.NextBatch = now.Add(interval)
+	if err != nil {
+		addCampaignError(c, now, err)
+	} else {
+		var cmd map[string]interface{}
+		json.Unmarshal(data, &cmd)
+		id, _ := cmd["id"].(float64)
+		c.LastCommandID = int(id)
+		c.Pending = c.Pending[len(batch):]
+		if len(c.Pending) == 0 {
+			c.FinishedAt = &now
+		}
+	}
+	saveCampaignProgressLocked(c)
+}
+
+func setNextBatch(name string, t time.Time) {
+	campaignMu.Lock()
+	defer campaignMu.Unlock()
+	if c := campaigns[name]; c != nil {
+		c.NextBatch = t
+		saveCampaignProgressLocked(c)
+	}
+}
+
+// addCampaignError logs an error and keeps it with the campaign for
+// ultimarr_campaign_status. Callers hold campaignMu.
+func addCampaignError(c *upgradeCampaign, now time.Time, err error) {
+	c.Errors = append(c.Errors, fmt.Sprintf("%s: %v", now.Format("Jan 2 15:04"), err))
+	c.Errors = c.Errors[max(0, len(c.Errors)-maxCampaignErrors):]
+	log.Printf("%s upgrade campaign: %v", c.Service, err)
+}
+
+// saveCampaignProgressLocked saves campaigns from the campaign loop, where
+// nobody is waiting on the result, so a failure is recorded as a campaign
+// error instead.
+func saveCampaignProgressLocked(c *upgradeCampaign) {
+	if err := saveCampaignsLocked(); err != nil {
+		addCampaignError(c, time.Now(), fmt.Errorf("saving progress: %w", err))
+	}
+}
+
+// cutoffUnmet lists the IDs of monitored items below their profile's cutoff.
+func cutoffUnmet(ctx context.Context, request arrRequestFunc) ([]int, error) {
+	var ids []int
+	for page := 1; ; page++ {
+		data, err := request(ctx, "GET", fmt.Sprintf("/wanted/cutoff?page=%d&pageSize=1000&monitored=true", page), nil)
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			TotalRecords int                      `json:"totalRecords"`
+			Records      []map[string]interface{} `json:"records"`
+		}
+		json.Unmarshal(data, &result)
+		for _, r := range result.Records {
+			if id, ok := r["id"].(float64); ok {
+				ids = append(ids, int(id))
+			}
+		}
+		if len(result.Records) == 0 || len(ids) >= result.TotalRecords {
+			return ids, nil
+		}
+	}
+}
+
+// campaignResult is the structured result of ultimarr_upgrade_campaign.
+type campaignResult struct {
+	Service         string     `json:"service"`
+	Stopped         bool       `json:"stopped,omitempty"`
+	Items           int        `json:"items"`
+	BatchSize       int        `json:"batchSize"`
+	IntervalMinutes int        `json:"intervalMinutes"`
+	EstimatedEnd    *time.Time `json:"estimatedEnd,omitempty"`
+}
+
+func handleUltimarrUpgradeCampaign(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	name := "Sonarr"
+	if args["service"] == "radarr" {
+		name = "Radarr"
+	}
+	svc := campaignServices[name]
+	if (name == "Sonarr" && !isConfigured(sonarr)) || (name == "Radarr" && !isConfigured(radarr)) {
+		return mcp.NewToolResultError(name + " is not configured"), nil
+	}
+
+	if stop, _ := args["stop"].(bool); stop {
+		campaignMu.Lock()
+		defer campaignMu.Unlock()
+		c := campaigns[name]
+		if c == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("There is no %s upgrade campaign", name)), nil
+		}
+		delete(campaigns, name)
+		var note string
+		if err := saveCampaignsLocked(); err != nil {
+			log.Printf("Upgrade campaigns: %v", err)
+			note = " The change couldn't be saved, so the campaign will resume after a restart: " + err.Error()
+		}
+		searched := c.Total - len(c.Pending)
+		result := campaignResult{Service: name, Stopped: true, Items: searched, BatchSize: c.BatchSize, IntervalMinutes: c.IntervalMinutes}
+		if c.FinishedAt != nil {
+			return mcp.NewToolResultStructured(result, fmt.Sprintf("Cleared the finished %s upgrade campaign (%d %s searched).", name, searched, svc.Unit)+note), nil
+		}
+		return mcp.NewToolResultStructured(result, fmt.Sprintf("Stopped the %s upgrade campaign after searching %d of %d %s.", name, searched, c.Total, svc.Unit)+note), nil
+	}
+
+	batch := defaultCampaignBatch
+	if b, ok := args["batch_size"].(float64); ok {
+		batch = min(max(int(b), 1), maxCampaignBatch)
+	}
+	interval := defaultCampaignInterval
+	if m, ok := args["interval_minutes"].(float64); ok {
+		interval = max(int(m), minCampaignInterval)
+	}
+
+	campaignMu.Lock()
+	running := campaigns[name] != nil && campaigns[name].FinishedAt == nil
+	campaignMu.Unlock()
+	if running {
+		return mcp.NewToolResultError(fmt.Sprintf("A %s upgrade campaign is already running; check it with ultimarr_campaign_status or stop it first", name)), nil
+	}
+
+	ids, err := cutoffUnmet(ctx, svc.Request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(ids) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Nothing in %s is below its quality cutoff", name)), nil
+	}
+
+	now := time.Now()
+	batches := (len(ids) + batch - 1) / batch
+	end := now.Add(time.Duration(batches-1) * time.Duration(interval) * time.Minute)
+	result := campaignResult{Service: name, Items: len(ids), BatchSize: batch, IntervalMinutes: interval, EstimatedEnd: &end}
+	campaignMu.Lock()
+	campaigns[name] = &upgradeCampaign{
+		Service: name, Total: len(ids), Pending: ids, BatchSize: batch, IntervalMinutes: interval,
+		StartedAt: now, NextBatch: now,
+	}
+	saveErr := saveCampaignsLocked()
+	campaignMu.Unlock()
+
+	text := fmt.Sprintf("Started a %s upgrade campaign: %d %s below cutoff, searched %d every %d minutes in %d batch(es). It should finish around %s. The first batch starts within a minute.",
+		name, len(ids), svc.Unit, batch, interval, batches, end.Format("Jan 2 15:04"))
+	if config.QuietHours != nil {
+		text += " Batches pause during quiet hours."
+	}
+	if saveErr != nil {
+		log.Printf("Upgrade campaigns: %v", saveErr)
+		text += " The campaign couldn't be saved, so it won't resume after a restart: " + saveErr.Error()
+	}
+	return mcp.NewToolResultStructured(result, text), nil
+}
+
+// campaignStatus is one campaign in ultimarr_campaign_status. StillBelow is
+// how many items are below cutoff now, so Total - StillBelow approximates
+// the upgrades found so far.
+type campaignStatus struct {
+	Service    string     `json:"service"`
+	Total      int        `json:"total"`
+	Searched   int        `json:"searched"`
+	StillBelow *int       `json:"stillBelow,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	NextBatch  *time.Time `json:"nextBatch,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Errors     []string   `json:"errors,omitempty"`
+}
+
+type campaignStatusList struct {
+	Campaigns []campaignStatus `json:"campaigns"`
+}
+
+func handleUltimarrCampaignStatus(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	campaignMu.Lock()
+	var list []upgradeCampaign
+	for _, c := range campaigns {
+		list = append(list, *c)
+	}
+	campaignMu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Service < list[j].Service })
+
+	if len(list) == 0 {
+		return mcp.NewToolResultStructured(campaignStatusList{Campaigns: []campaignStatus{}}, "No upgrade campaigns. Start one with ultimarr_upgrade_campaign."), nil
+	}
+
+	result := campaignStatusList{Campaigns: []campaignStatus{}}
+	var lines []string
+	for _, c := range list {
+		svc := campaignServices[c.Service]
+		st := campaignStatus{Service: c.Service, Total: c.Total, Searched: c.Total - len(c.Pending), StartedAt: c.StartedAt, FinishedAt: c.FinishedAt, Errors: c.Errors}
+		if data, err := svc.Request(ctx, "GET", "/wanted/cutoff?pageSize=1&monitored=true", nil); err == nil {
+			var page struct {
+				TotalRecords int `json:"totalRecords"`
+			}
+			json.Unmarshal(data, &page)
+			st.StillBelow = &page.TotalRecords
+		}
+
+		pct := st.Searched * 100 / c.Total
+		lines = append(lines, fmt.Sprintf("%s: searched %d of %d %s (%d%%), started %s", c.Service, st.Searched, c.Total, svc.Unit, pct, c.StartedAt.Format("Jan 2 15:04")))
+		if c.FinishedAt != nil {
+			lines = append(lines, "  Finished "+c.FinishedAt.Format("Jan 2 15:04"))
+		} else {
+			next := c.NextBatch
+			st.NextBatch = &next
+			remaining := (len(c.Pending) + c.BatchSize - 1) / c.BatchSize
+			end := next.Add(time.Duration(remaining-1) * time.Duration(c.IntervalMinutes) * time.Minute)
+			lines = append(lines, fmt.Sprintf("  Next batch of %d at %s; about %d batch(es) left, finishing around %s", min(c.BatchSize, len(c.Pending)), next.Format("15:04"), remaining, end.Format("Jan 2 15:04")))
+		}
+		if st.StillBelow != nil {
+			lines = append(lines, fmt.Sprintf("  %d %s still below cutoff (%d when the campaign started)", *st.StillBelow, svc.Unit, c.Total))
+		}
+		for _, e := range c.Errors {
+			lines = append(lines, "  Error: "+e)
+		}
+		result.Campaigns = append(result.Campaigns, st)
+	}
+	return mcp.NewToolResultStructured(result, strings.Join(lines, "\n")), nil
+}
+
+func registerCampaignTools(s *server.MCPServer) {
+	if len(arrServices()) == 0 {
+		return
+	}
+
+	// Upgrade Campaign
+	s.AddTool(
+		mcp.NewTool("ultimarr_upgrade_campaign",
+			mcp.WithDescription("Start (or stop) a campaign that searches for upgrades of everything below its quality cutoff in Sonarr or Radarr, a batch at a time over hours or days instead of all at once. Progress survives restarts; check it with ultimarr_campaign_status."),
+			mcp.WithString("service", mcp.Required(), mcp.Enum("sonarr", "radarr"), mcp.Description("Which service's cutoff-unmet items to upgrade")),
+			mcp.WithNumber("batch_size", mcp.Description(fmt.Sprintf("Episodes or movies searched per batch (default %d, max %d)", defaultCampaignBatch, maxCampaignBatch))),
+			mcp.WithNumber("interval_minutes", mcp.Description(fmt.Sprintf("Minutes between batches (default %d, min %d)", defaultCampaignInterval, minCampaignInterval))),
+			mcp.WithBoolean("stop", mcp.Description("Stop the running campaign, or clear a finished one, instead of starting one (default false)")),
+			mcp.WithOutputSchema[campaignResult](),
+		),
+		handleUltimarrUpgradeCampaign,
+	)
+
+	// Campaign Status
+	s.AddTool(
+		mcp.NewTool("ultimarr_campaign_status",
+			mcp.WithDescription("Show how far each upgrade campaign has gotten: items searched, next batch, estimated finish, how many are still below cutoff, and recent errors"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[campaignStatusList](),
+		),
+		handleUltimarrCampaignStatus,
+	)
+}
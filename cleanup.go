@@ -0,0 +1,461 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// Bulk Delete
+// ============================================================================
+
+// pendingDelete is a dry-run result waiting for confirmation. Confirming
+// deletes exactly the items that were shown, even if the library has changed,
+// with the delete_files and add_exclusion options the dry run was made with.
+type pendingDelete struct {
+	Service      string
+	IDs          []int
+	Titles       []string
+	DeleteFiles  bool
+	AddExclusion bool
+	Expires      time.Time
+}
+
+var (
+	pendingDeletesMu sync.Mutex
+	pendingDeletes   = map[string]pendingDelete{}
+)
+
+// How long a dry-run confirmation token stays valid
+const confirmTokenTTL = 15 * time.Minute
+
+// bulkDeleteResult is the structured result of the *_bulk_delete tools. A dry
+// run lists Items and returns ConfirmToken; a confirmed run sets Deleted.
+type bulkDeleteResult struct {
+	DryRun       bool             `json:"dryRun"`
+	Deleted      bool             `json:"deleted"`
+	Items        []bulkDeleteItem `json:"items"`
+	TotalSize    int64            `json:"totalSize,omitempty"`
+	WatchUnknown []bulkDeleteItem `json:"watchStateUnknown,omitempty"` // left out: not found in Jellyfin
+	ConfirmToken string           `json:"confirmToken,omitempty"`
+	ExpiresAt    *time.Time       `json:"expiresAt,omitempty"`
+}
+
+type bulkDeleteItem struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Size  int64  `json:"size,omitempty"`
+}
+
+// bulkDeleteCandidate is a library item considered by a bulk delete.
+type bulkDeleteCandidate struct {
+	ID         int
+	Title      string
+	Size       int64
+	Monitored  bool
+	Ended      bool
+	Tags       []int
+	Genres     []string
+	Resolution int // 0 if unknown
+	ExternalID string
+	Added      time.Time
+}
+
+func bulkDeleteOptions(service string) []mcp.ToolOption {
+	opts := []mcp.ToolOption{
+		mcp.WithString("tag", mcp.Description("Only items with this tag label")),
+		mcp.WithBoolean("unmonitored", mcp.Description("Only unmonitored items")),
+		mcp.WithString("genre", mcp.Description("Only items with this genre, e.g. 'Reality'")),
+		mcp.WithNumber("older_than_days", mcp.Description("Only items added at least this many days ago")),
+		mcp.WithNumber("not_watched_days", mcp.Description("Only items nobody has played in Jellyfin for this many days (and added at least that long ago); requires Jellyfin")),
+		mcp.WithBoolean("watched", mcp.Description("Only items someone has played in Jellyfin; requires Jellyfin")),
+		mcp.WithNumber("below_size_gb", mcp.Description("Only items using less than this much disk space, in GB")),
+		mcp.WithNumber("min_size_gb", mcp.Description("Only items using at least this much disk space, in GB")),
+		mcp.WithBoolean("delete_files", mcp.Description("Also delete files from disk (default true)")),
+		mcp.WithBoolean("add_exclusion", mcp.Description("Add deleted items to the import exclusion list so lists don't re-add them (default false)")),
+		mcp.WithString("confirm_token", mcp.Description("Token from a previous dry run; pass it to actually delete the listed items")),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[bulkDeleteResult](),
+	}
+	if service == "Sonarr" {
+		opts = append(opts, mcp.WithBoolean("ended", mcp.Description("Only series that have ended")))
+	} else {
+		opts = append(opts, mcp.WithNumber("below_resolution", mcp.Description("Only movies whose file is below this resolution, e.g. 1080")))
+	}
+	return opts
+}
+
+func handleSonarrBulkDelete(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return handleBulkDelete(ctx, req, "Sonarr", sonarrRequest)
+}
+
+func handleRadarrBulkDelete(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return handleBulkDelete(ctx, req, "Radarr", radarrRequest)
+}
+
+func handleBulkDelete(ctx context.Context, req mcp.CallToolRequest, service string, request arrRequestFunc) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+
+	if token, ok := args["confirm_token"].(string); ok && token != "" {
+		return executeBulkDelete(ctx, args, service, request, token)
+	}
+
+	candidates, err := bulkDeleteCandidates(ctx, service, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	matches, unknown, err := filterBulkDelete(ctx, args, service, request, candidates)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := bulkDeleteResult{DryRun: true, Items: []bulkDeleteItem{}}
+	var unknownLines []string
+	for _, c := range unknown {
+		result.WatchUnknown = append(result.WatchUnknown, bulkDeleteItem{ID: c.ID, Title: c.Title, Size: c.Size})
+		unknownLines = append(unknownLines, fmt.Sprintf("  [%d] %s (%s)", c.ID, c.Title, formatBytes(c.Size)))
+	}
+	var unknownText string
+	if len(unknown) > 0 {
+		unknownText = fmt.Sprintf("\n\nWatch state unknown, not found in Jellyfin, so left out (%d):\n%s", len(unknown), strings.Join(unknownLines, "\n"))
+	}
+	if len(matches) == 0 {
+		return mcp.NewToolResultStructured(result, "No items match these criteria; nothing to delete."+unknownText), nil
+	}
+
+	deleteFiles, addExclusion := bulkDeleteFlags(args)
+	pd := pendingDelete{Service: service, DeleteFiles: deleteFiles, AddExclusion: addExclusion, Expires: time.Now().Add(confirmTokenTTL)}
+	var lines []string
+	for _, c := range matches {
+		pd.IDs = append(pd.IDs, c.ID)
+		pd.Titles = append(pd.Titles, c.Title)
+		result.TotalSize += c.Size
+		result.Items = append(result.Items, bulkDeleteItem{ID: c.ID, Title: c.Title, Size: c.Size})
+		lines = append(lines, fmt.Sprintf("  [%d] %s (%s)", c.ID, c.Title, formatBytes(c.Size)))
+	}
+
+	token := newConfirmToken()
+	pendingDeletesMu.Lock()
+	for t, p := range pendingDeletes {
+		if time.Now().After(p.Expires) {
+			delete(pendingDeletes, t)
+		}
+	}
+	pendingDeletes[token] = pd
+	pendingDeletesMu.Unlock()
+	result.ConfirmToken = token
+	result.ExpiresAt = &pd.Expires
+
+	action := fmt.Sprintf("deleted from %s, reclaiming %s", service, formatBytes(result.TotalSize))
+	if !deleteFiles {
+		action = fmt.Sprintf("removed from %s, keeping their files on disk", service)
+	}
+	if addExclusion {
+		action += ", and added to the import exclusion list"
+	}
+	header := fmt.Sprintf("DRY RUN: %d item(s) would be %s:\n", len(matches), action)
+	footer := fmt.Sprintf("\nNothing has been deleted. To delete exactly these items, call again with confirm_token=%q within %d minutes.", token, int(confirmTokenTTL.Minutes()))
+	return mcp.NewToolResultStructured(result, header+strings.Join(lines, "\n")+unknownText+"\n"+footer), nil
+}
+
+func newConfirmToken() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// bulkDeleteFlags returns the delete_files (default true) and add_exclusion
+// options of a bulk delete call.
+func bulkDeleteFlags(args map[string]interface{}) (deleteFiles, addExclusion bool) {
+	deleteFiles = true
+	if v, ok := args["delete_files"].(bool); ok {
+		deleteFiles = v
+	}
+	addExclusion, _ = args["add_exclusion"].(bool)
+	return deleteFiles, addExclusion
+}
+
+func executeBulkDelete(ctx context.Context, args map[string]interface{}, service string, request arrRequestFunc, token string) (*mcp.CallToolResult, error) {
+	pendingDeletesMu.Lock()
+	pd, ok := pendingDeletes[token]
+	pendingDeletesMu.Unlock()
+
+	if !ok || time.Now().After(pd.Expires) {
+		return mcp.NewToolResultError("Unknown or expired confirm_token. Run the bulk delete again without a token to get a fresh dry run."), nil
+	}
+	if pd.Service != service {
+		return mcp.NewToolResultError(fmt.Sprintf("This token is for a %s bulk delete", pd.Service)), nil
+	}
+	// The options are fixed by the dry run; passing them again is only
+	// allowed if they agree
+	if v, ok := args["delete_files"].(bool); ok && v != pd.DeleteFiles {
+		return mcp.NewToolResultError(fmt.Sprintf("The dry run for this token was made with delete_files=%t. Confirm without delete_files, or run a new dry run with delete_files=%t.", pd.DeleteFiles, v)), nil
+	}
+	if v, ok := args["add_exclusion"].(bool); ok && v != pd.AddExclusion {
+		return mcp.NewToolResultError(fmt.Sprintf("The dry run for this token was made with add_exclusion=%t. Confirm without add_exclusion, or run a new dry run with add_exclusion=%t.", pd.AddExclusion, v)), nil
+	}
+
+	pendingDeletesMu.Lock()
+	_, ok = pendingDeletes[token]
+	delete(pendingDeletes, token)
+	pendingDeletesMu.Unlock()
+	if !ok {
+		return mcp.NewToolResultError("This confirm_token has already been used."), nil
+	}
+
+	payload := map[string]interface{}{"deleteFiles": pd.DeleteFiles}
+	endpoint := "/movie/editor"
+	if service == "Sonarr" {
+		payload["seriesIds"] = pd.IDs
+		payload["addImportListExclusion"] = pd.AddExclusion
+		endpoint = "/series/editor"
+	} else {
+		payload["movieIds"] = pd.IDs
+		payload["addImportExclusion"] = pd.AddExclusion
+	}
+	body, _ := json.Marshal(payload)
+
+	if _, err := request(ctx, "DELETE", endpoint, strings.NewReader(string(body))); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := bulkDeleteResult{Deleted: true, Items: []bulkDeleteItem{}}
+	for i, id := range pd.IDs {
+		result.Items = append(result.Items, bulkDeleteItem{ID: id, Title: pd.Titles[i]})
+	}
+	text := fmt.Sprintf("Deleted %d item(s) from %s: %s", len(pd.IDs), service, strings.Join(pd.Titles, ", "))
+	if !pd.DeleteFiles {
+		text = fmt.Sprintf("Removed %d item(s) from %s, keeping their files on disk: %s", len(pd.IDs), service, strings.Join(pd.Titles, ", "))
+	}
+	return mcp.NewToolResultStructured(result, text), nil
+}
+
+// bulkDeleteCandidates loads every series or movie with the fields the
+// filters need.
+func bulkDeleteCandidates(ctx context.Context, service string, request arrRequestFunc) ([]bulkDeleteCandidate, error) {
+	endpoint := "/movie"
+	if service == "Sonarr" {
+		endpoint = "/series"
+	}
+	data, err := request(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []map[string]interface{}
+	json.Unmarshal(data, &items)
+
+	var candidates []bulkDeleteCandidate
+	for _, item := range items {
+		c := bulkDeleteCandidate{ID: int(item["id"].(float64))}
+		c.Title, _ = item["title"].(string)
+		c.Monitored, _ = item["monitored"].(bool)
+		if added, ok := item["added"].(string); ok {
+			c.Added, _ = time.Parse(time.RFC3339, added)
+		}
+		if tags, ok := item["tags"].([]interface{}); ok {
+			for _, t := range tags {
+				c.Tags = append(c.Tags, int(t.(float64)))
+			}
+		}
+		if genres, ok := item["genres"].([]interface{}); ok {
+			for _, g := range genres {
+				if name, ok := g.(string); ok {
+					c.Genres = append(c.Genres, name)
+				}
+			}
+		}
+
+		if service == "Sonarr" {
+			status, _ := item["status"].(string)
+			c.Ended = status == "ended"
+			if stats, ok := item["statistics"].(map[string]interface{}); ok {
+				size, _ := stats["sizeOnDisk"].(float64)
+				c.Size = int64(size)
+			}
+			if id, ok := item["tvdbId"].(float64); ok {
+				c.ExternalID = strconv.Itoa(int(id))
+			}
+		} else {
+			size, _ := item["sizeOnDisk"].(float64)
+			c.Size = int64(size)
+			if id, ok := item["tmdbId"].(float64); ok {
+				c.ExternalID = strconv.Itoa(int(id))
+			}
+			if mf, ok := item["movieFile"].(map[string]interface{}); ok {
+				if q, ok := mf["quality"].(map[string]interface{}); ok {
+					if qq, ok := q["quality"].(map[string]interface{}); ok {
+						if r, ok := qq["resolution"].(float64); ok {
+							c.Resolution = int(r)
+						}
+					}
+				}
+			}
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
+
+// filterBulkDelete returns the candidates matching every filter in args.
+// With a watch filter, candidates Jellyfin can't be matched with are
+// returned separately as unknown rather than treated as never watched.
+func filterBulkDelete(ctx context.Context, args map[string]interface{}, service string, request arrRequestFunc, candidates []bulkDeleteCandidate) (matches, unknown []bulkDeleteCandidate, err error) {
+	criteria := 0
+
+	tagID := -1
+	if label, ok := args["tag"].(string); ok && label != "" {
+		criteria++
+		data, err := request(ctx, "GET", "/tag", nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		var tags []map[string]interface{}
+		json.Unmarshal(data, &tags)
+		for _, t := range tags {
+			if l, _ := t["label"].(string); strings.EqualFold(l, label) {
+				tagID = int(t["id"].(float64))
+			}
+		}
+		if tagID < 0 {
+			return nil, nil, fmt.Errorf("no %s tag named %q", service, label)
+		}
+	}
+
+	unmonitored, _ := args["unmonitored"].(bool)
+	ended, _ := args["ended"].(bool)
+	if unmonitored {
+		criteria++
+	}
+	if ended {
+		criteria++
+	}
+
+	// A zero or negative limit would match everything (or nothing), so it
+	// doesn't count as a filter
+	for _, name := range []string{"below_size_gb", "min_size_gb", "below_resolution", "older_than_days", "not_watched_days"} {
+		if v, ok := args[name].(float64); ok && v <= 0 {
+			return nil, nil, fmt.Errorf("%s must be greater than 0", name)
+		}
+	}
+
+	var belowSize, minSize int64
+	if v, ok := args["below_size_gb"].(float64); ok {
+		belowSize = int64(v * 1024 * 1024 * 1024)
+		criteria++
+	}
+	if v, ok := args["min_size_gb"].(float64); ok {
+		minSize = int64(v * 1024 * 1024 * 1024)
+		criteria++
+	}
+	belowResolution := 0
+	if v, ok := args["below_resolution"].(float64); ok {
+		belowResolution = int(v)
+		criteria++
+	}
+	genre, _ := args["genre"].(string)
+	if genre != "" {
+		criteria++
+	}
+	var addedCutoff time.Time
+	if v, ok := args["older_than_days"].(float64); ok {
+		addedCutoff = time.Now().AddDate(0, 0, -int(v))
+		criteria++
+	}
+
+	var lastPlayed map[string]time.Time
+	var watchCutoff time.Time
+	notWatchedDays, notWatched := args["not_watched_days"].(float64)
+	watched, _ := args["watched"].(bool)
+	if notWatched || watched {
+		if notWatched {
+			criteria++
+			watchCutoff = time.Now().AddDate(0, 0, -int(notWatchedDays))
+		}
+		if watched {
+			criteria++
+		}
+		mediaType := "movie"
+		if service == "Sonarr" {
+			mediaType = "tv"
+		}
+		if lastPlayed, err = jellyfinLastPlayed(ctx, mediaType); err != nil {
+			return nil, nil, fmt.Errorf("watch filters need Jellyfin play history: %w", err)
+		}
+	}
+
+	// Refuse to match the whole library by accident
+	if criteria == 0 {
+		return nil, nil, fmt.Errorf("at least one filter is required (tag, unmonitored, ended, genre, older_than_days, not_watched_days, watched, below_size_gb, min_size_gb, below_resolution)")
+	}
+
+	for _, c := range candidates {
+		if tagID >= 0 && !containsInt(c.Tags, tagID) {
+			continue
+		}
+		if unmonitored && c.Monitored {
+			continue
+		}
+		if ended && !c.Ended {
+			continue
+		}
+		if belowSize > 0 && c.Size >= belowSize {
+			continue
+		}
+		if minSize > 0 && c.Size < minSize {
+			continue
+		}
+		if belowResolution > 0 && (c.Resolution == 0 || c.Resolution >= belowResolution) {
+			continue
+		}
+		if genre != "" && !containsFold(c.Genres, genre) {
+			continue
+		}
+		if !addedCutoff.IsZero() && c.Added.After(addedCutoff) {
+			continue
+		}
+		if notWatched || watched {
+			last, ok := lastPlayed[c.ExternalID]
+			if c.ExternalID == "" || !ok {
+				unknown = append(unknown, c)
+				continue
+			}
+			if notWatched && (c.Added.After(watchCutoff) || last.After(watchCutoff)) {
+				continue
+			}
+			if watched && last.IsZero() {
+				continue
+			}
+		}
+		matches = append(matches, c)
+	}
+	return matches, unknown, nil
+}
+
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, v string) bool {
+	for _, x := range list {
+		if strings.EqualFold(x, v) {
+			return true
+		}
+	}
+	return false
+}
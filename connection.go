@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// Connection Diagnostics
+// ============================================================================
+
+// Timeout for each network probe
+const probeTimeout = 5 * time.Second
+
+// TLS certificates expiring sooner than this are flagged
+const certExpiryWarning = 14 * 24 * time.Hour
+
+// connectionTarget is a configured service and how to make an authenticated
+// call to its version endpoint.
+type connectionTarget struct {
+	Name    string
+	URL     string
+	Version func(ctx context.Context) (string, error)
+}
+
+// authError reports credentials a service rejected without an HTTP error
+// status (qBittorrent logins, SABnzbd API keys).
+type authError struct {
+	msg string
+}
+
+func (e *authError) Error() string { return e.msg }
+
+// connectionTargets lists the configured services that can be probed.
+func connectionTargets() []connectionTarget {
+	var targets []connectionTarget
+	for _, svc := range configured {
+		if h, ok := svc.(httpService); ok {
+			targets = append(targets, connectionTarget{h.Name(), h.URL(), h.HealthCheck})
+		}
+	}
+	return targets
+}
+
+// versionField extracts the version from a status response.
+func versionField(data []byte, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	var status map[string]interface{}
+	if json.Unmarshal(data, &status) != nil {
+		return "", fmt.Errorf("the response isn't JSON; the URL may point at a different application or a login page")
+	}
+	for _, key := range []string{"version", "Version"} {
+		if v, ok := status[key].(string); ok {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("the response has no version; the URL may point at a different application")
+}
+
+// connectionDiagnosis is the structured result of ultimarr_diagnose_connection.
+// Layers are checked in order and stop at the first failure, named by FailedAt.
+type connectionDiagnosis struct {
+	Service  string            `json:"service"`
+	URL      string            `json:"url"`
+	Layers   []connectionLayer `json:"layers"`
+	FailedAt string            `json:"failedAt,omitempty"`
+}
+
+type connectionLayer struct {
+	Layer   string `json:"layer"` // dns, tcp, tls, http, auth, or api
+	OK      bool   `json:"ok"`
+	Skipped bool   `json:"skipped,omitempty"`
+	Detail  string `json:"detail"`
+	Advice  string `json:"advice,omitempty"`
+	Millis  int64  `json:"millis"`
+}
+
+func handleUltimarrDiagnoseConnection(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	name, _ := args["service"].(string)
+
+	var target *connectionTarget
+	for _, t := range connectionTargets() {
+		if strings.EqualFold(t.Name, name) {
+			target = &t
+		}
+	}
+	if target == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is not configured", name)), nil
+	}
+
+	result := diagnoseConnection(ctx, *target)
+	lines := []string{fmt.Sprintf("%s at %s:", result.Service, result.URL)}
+	for _, l := range result.Layers {
+		state := "OK  "
+		switch {
+		case l.Skipped:
+			state = "SKIP"
+		case !l.OK:
+			state = "FAIL"
+		}
+		lines = append(lines, fmt.Sprintf("  [%s] %-4s %s (%d ms)", state, strings.ToUpper(l.Layer), l.Detail, l.Millis))
+		if l.Advice != "" {
+			lines = append(lines, "         "+l.Advice)
+		}
+	}
+	if result.FailedAt == "" {
+		lines = append(lines, "", "All layers passed.")
+	} else {
+		lines = append(lines, "", fmt.Sprintf("The connection fails at the %s layer.", strings.ToUpper(result.FailedAt)))
+	}
+	return mcp.NewToolResultStructured(result, strings.Join(lines, "\n")), nil
+}
+
+// diagnoseConnection checks each layer between this server and a service:
+// name resolution, TCP, TLS, an unauthenticated HTTP request, then an
+// authenticated call to the version endpoint.
+func diagnoseConnection(ctx context.Context, t connectionTarget) connectionDiagnosis {
+	result := connectionDiagnosis{Service: t.Name, URL: t.URL, Layers: []connectionLayer{}}
+	add := func(layer string, start time.Time, ok bool, detail, advice string) {
+		result.Layers = append(result.Layers, connectionLayer{Layer: layer, OK: ok, Detail: detail, Advice: advice, Millis: time.Since(start).Milliseconds()})
+		if !ok && result.FailedAt == "" {
+			result.FailedAt = layer
+		}
+	}
+
+	u, err := url.Parse(t.URL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		add("dns", time.Now(), false, fmt.Sprintf("%q is not a valid URL", t.URL), "Use the form http://host:port, including the scheme")
+		return result
+	}
+	host, port := u.Hostname(), u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+
+	// Demo mode answers from canned data, so there is no network to probe
+	if _, demo := httpTransport.(demoTransport); demo {
+		for _, layer := range []string{"dns", "tcp", "tls"} {
+			result.Layers = append(result.Layers, connectionLayer{Layer: layer, OK: true, Skipped: true, Detail: "demo mode"})
+		}
+	} else {
+		start := time.Now()
+		if net.ParseIP(host) != nil {
+			add("dns", start, true, host+" is an IP address, no lookup needed", "")
+		} else {
+			lookupCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+			addrs, err := net.DefaultResolver.LookupHost(lookupCtx, host)
+			cancel()
+			if err != nil {
+				advice := "Check the hostname for typos. In Docker, use the container or service name and make sure both containers share a network."
+				var dnsErr *net.DNSError
+				if errors.As(err, &dnsErr) && dnsErr.IsTimeout {
+					advice = "The DNS server didn't answer; check this machine's DNS settings."
+				}
+				add("dns", start, false, err.Error(), advice)
+				return result
+			}
+			add("dns", start, true, fmt.Sprintf("%s resolves to %s", host, strings.Join(addrs, ", ")), "")
+		}
+
+		start = time.Now()
+		dialer := &net.Dialer{Timeout: probeTimeout}
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+		if err != nil {
+			advice := "Nothing answered; a firewall may be dropping the connection, or the address is on a network this server can't reach."
+			if errors.Is(err, syscall.ECONNREFUSED) {
+				advice = fmt.Sprintf("Nothing is listening on port %s. Check the port, that %s is running, and that it listens on all interfaces rather than only localhost.", port, t.Name)
+			}
+			add("tcp", start, false, err.Error(), advice)
+			return result
+		}
+		conn.Close()
+		add("tcp", start, true, fmt.Sprintf("connected to port %s", port), "")
+
+		if u.Scheme == "https" {
+			start = time.Now()
+			conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(host, port), &tls.Config{ServerName: host})
+			if err != nil {
+				add("tls", start, false, err.Error(), tlsAdvice(err))
+				return result
+			}
+			cert := conn.ConnectionState().PeerCertificates[0]
+			conn.Close()
+			detail := fmt.Sprintf("certificate for %s issued by %s, valid until %s", strings.Join(cert.DNSNames, ", "), cert.Issuer.CommonName, cert.NotAfter.Format("2006-01-02"))
+			advice := ""
+			if time.Until(cert.NotAfter) < certExpiryWarning {
+				advice = "The certificate expires soon; check that it's being renewed."
+			}
+			add("tls", start, true, detail, advice)
+		} else {
+			result.Layers = append(result.Layers, connectionLayer{Layer: "tls", OK: true, Skipped: true, Detail: "the URL uses plain HTTP"})
+		}
+	}
+
+	// Any HTTP response shows a web server is there; redirects aren't followed
+	// so a login page in front of the service is visible
+	start := time.Now()
+	client := &http.Client{
+		Timeout:       probeTimeout,
+		Transport:     httpTransport,
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", t.URL, nil)
+	var resp *http.Response
+	if err == nil {
+		resp, err = client.Do(req)
+	}
+	if err != nil {
+		advice := "The server accepted the connection but didn't answer HTTP."
+		if strings.Contains(err.Error(), "HTTP response to HTTPS client") {
+			advice = "The server speaks plain HTTP; change the URL to http://."
+		} else if strings.Contains(err.Error(), "malformed HTTP response") {
+			advice = "The server expects HTTPS; change the URL to https://."
+		}
+		add("http", start, false, err.Error(), advice)
+		return result
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+	detail := fmt.Sprintf("HTTP %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	if server := resp.Header.Get("Server"); server != "" {
+		detail += ", server " + server
+	}
+	switch {
+	case resp.StatusCode == http.StatusBadRequest && (strings.Contains(string(body), "HTTP request to an HTTPS server") || strings.Contains(string(body), "plain HTTP request was sent to HTTPS port")):
+		add("http", start, false, detail, "The server expects HTTPS on this port; change the URL to https://.")
+		return result
+	case resp.StatusCode >= 502 && resp.StatusCode <= 504:
+		add("http", start, false, detail, fmt.Sprintf("A reverse proxy answered but couldn't reach %s behind it; check the proxy's upstream address and that %s is running.", t.Name, t.Name))
+		return result
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		location := resp.Header.Get("Location")
+		detail += ", redirects to " + location
+		advice := ""
+		if target, err := u.Parse(location); err == nil && target.Host != u.Host {
+			advice = "Redirects to another host, often a single sign-on page. API calls may be caught by it; exempt the API path in the proxy or use the service's internal address."
+		}
+		add("http", start, true, detail, advice)
+	default:
+		add("http", start, true, detail, "")
+	}
+
+	start = time.Now()
+	version, err := t.Version(ctx)
+	var httpErr *HTTPError
+	var authErr *authError
+	switch {
+	case err == nil:
+		add("auth", start, true, "credentials accepted", "")
+		add("api", start, true, fmt.Sprintf("%s %s", t.Name, version), "")
+	case errors.As(err, &authErr), errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden):
+		add("auth", start, false, err.Error(), fmt.Sprintf("%s rejected the credentials; check the API key (or username and password) in this server's environment against %s's settings.", t.Name, t.Name))
+	case errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound:
+		add("auth", start, true, "not rejected", "")
+		advice := fmt.Sprintf("The API endpoint wasn't found. If %s runs under a URL base (e.g. /%s), include it in the URL.", t.Name, strings.ToLower(t.Name))
+		if strings.Trim(u.Path, "/") != "" {
+			advice = fmt.Sprintf("The API endpoint wasn't found. Check that %s matches the URL base in %s's settings.", u.Path, t.Name)
+		}
+		add("api", start, false, err.Error(), advice)
+	case errors.As(err, &httpErr):
+		add("auth", start, true, "not rejected", "")
+		add("api", start, false, err.Error(), fmt.Sprintf("%s answered with an error; check its logs.", t.Name))
+	default:
+		add("api", start, false, err.Error(), "")
+	}
+	return result
+}
+
+// tlsAdvice explains a failed TLS handshake.
+func tlsAdvice(err error) string {
+	var unknownCA x509.UnknownAuthorityError
+	var hostErr x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	switch {
+	case errors.As(err, &unknownCA):
+		return "The certificate is self-signed or from an unknown CA. Add the CA to this machine's trust store, or use the service's plain HTTP address on a trusted network."
+	case errors.As(err, &hostErr):
+		return "The certificate is for a different name than the URL's host; use the name on the certificate."
+	case errors.As(err, &invalid) && invalid.Reason == x509.Expired:
+		return "The certificate has expired; renew it."
+	case strings.Contains(err.Error(), "first record does not look like a TLS handshake"):
+		return "The server speaks plain HTTP on this port; change the URL to http://."
+	}
+	return ""
+}
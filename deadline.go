@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ============================================================================
+// Tool Deadlines
+// ============================================================================
+
+// Backend calls can take up to requestTimeout each, so without a deadline a
+// tool stuck on an unresponsive service hangs until then (or longer, for
+// tools making several calls). Each tool call gets a deadline instead, and a
+// call that misses it returns a timeout result naming the backend it was
+// waiting on. The deadline cancels the backend requests the call is still
+// making; its result is dropped.
+
+// Extra time on top of the longest wait a tool does by design
+const deadlineMargin = 15 * time.Second
+
+// Deadline for tools that scan the whole library or check many titles
+const scanDeadline = 2 * time.Minute
+
+// toolDeadlines overrides ULTIMARR_TOOL_TIMEOUT for tools that are slow by
+// design.
+var toolDeadlines = map[string]time.Duration{
+	"sonarr_get_releases":           releaseSearchTimeout + deadlineMargin,
+	"radarr_get_releases":           releaseSearchTimeout + deadlineMargin,
+	"ultimarr_test_indexers":        releaseSearchTimeout + deadlineMargin,
+	"ultimarr_indexer_report":       scanDeadline,
+	"sonarr_refresh_and_verify":     2*time.Minute + deadlineMargin,
+	"radarr_refresh_and_verify":     2*time.Minute + deadlineMargin,
+	"sonarr_add_existing":           5*time.Minute + deadlineMargin,
+	"radarr_add_existing":           5*time.Minute + deadlineMargin,
+	"ultimarr_diagnose_connection":  requestTimeout + 4*probeTimeout + deadlineMargin,
+	"ultimarr_availability":         scanDeadline,
+	"ultimarr_in_flight":            scanDeadline,
+	"ultimarr_retention_report":     scanDeadline,
+	"ultimarr_torrent_health":       scanDeadline,
+	"ultimarr_replace_unregistered": scanDeadline,
+	"ultimarr_upgrade_campaign":     scanDeadline,
+	"ultimarr_campaign_status":      scanDeadline,
+	"sonarr_find_gaps":              scanDeadline,
+	"sonarr_bulk_delete":            scanDeadline,
+	"radarr_bulk_delete":            scanDeadline,
+	"jellyseerr_request_trends":     scanDeadline,
+	"ultimarr_space_plan":           scanDeadline,
+	"ultimarr_duplicate_cleanup":    scanDeadline,
+	"ultimarr_reconcile":            scanDeadline,
+}
+
+// toolDeadline returns the deadline for a tool. Tools that change something
+// get at least as long as a backend request, so a change isn't cut off just
+// because the service is slow to confirm it.
+func toolDeadline(ctx context.Context, name string) time.Duration {
+	d, ok := toolDeadlines[name]
+	if !ok {
+		d = config.ToolTimeout
+	}
+	if d > 0 && !readOnlyTool(ctx, name) {
+		d = max(d, requestTimeout+deadlineMargin)
+	}
+	return d
+}
+
+// toolTimeout describes a tool call that missed its deadline or was cancelled
+// by the client, in the error result's _meta under metaTimeout.
+type toolTimeout struct {
+	Tool      string        `json:"tool"`
+	Seconds   float64       `json:"seconds"`
+	Cancelled bool          `json:"cancelled"`
+	Waiting   []slowBackend `json:"waiting"`
+}
+
+// slowBackend is a backend request still outstanding when a call timed out.
+type slowBackend struct {
+	Service string  `json:"service"`
+	Request string  `json:"request"`
+	Seconds float64 `json:"seconds"`
+}
+
+// withToolDeadline is tool handler middleware enforcing toolDeadline. An
+// earlier deadline on the request's own context still applies.
+func withToolDeadline(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		limit := toolDeadline(ctx, req.Params.Name)
+		if limit <= 0 {
+			return next(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, limit)
+		defer cancel()
+		calls := &backendCalls{calls: map[int]*backendCall{}}
+		ctx = context.WithValue(ctx, backendCallsKey{}, calls)
+
+		type outcome struct {
+			result *mcp.CallToolResult
+			err    error
+		}
+		start := time.Now()
+		done := make(chan outcome, 1)
+		go func() {
+			// A panic here would take the whole server down, since it's
+			// outside anything the MCP server recovers
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("%s panicked: %v", req.Params.Name, r)
+					done <- outcome{mcp.NewToolResultError(fmt.Sprintf("%s failed with an internal error: %v", req.Params.Name, r)), nil}
+				}
+			}()
+			result, err := next(ctx, req)
+			done <- outcome{result, err}
+		}()
+
+		select {
+		case o := <-done:
+			return o.result, o.err
+		case <-ctx.Done():
+			return timeoutResult(req.Params.Name, start, ctx.Err() == context.Canceled, calls), nil
+		}
+	}
+}
+
+func timeoutResult(tool string, start time.Time, cancelled bool, calls *backendCalls) *mcp.CallToolResult {
+	elapsed := time.Since(start)
+	result := toolTimeout{Tool: tool, Seconds: elapsed.Seconds(), Cancelled: cancelled, Waiting: []slowBackend{}}
+	seen := map[string]bool{}
+	for _, c := range calls.outstanding() {
+		// The oldest request per service is the one holding things up
+		if seen[c.Service] {
+			continue
+		}
+		seen[c.Service] = true
+		result.Waiting = append(result.Waiting, slowBackend{Service: c.Service, Request: c.Method + " " + c.Path, Seconds: time.Since(c.Start).Seconds()})
+	}
+
+	var text string
+	if cancelled {
+		text = fmt.Sprintf("%s was cancelled after %s.", tool, elapsed.Round(time.Second))
+	} else {
+		text = fmt.Sprintf("%s didn't finish within %s.", tool, elapsed.Round(time.Second))
+	}
+	if len(result.Waiting) == 0 {
+		text += " It wasn't waiting on a backend at the time."
+	} else {
+		var waiting []string
+		for _, w := range result.Waiting {
+			waiting = append(waiting, fmt.Sprintf("%s (%s, %s)", w.Service, w.Request, time.Duration(w.Seconds*float64(time.Second)).Round(time.Second)))
+		}
+		text += " Still waiting on " + strings.Join(waiting, ", ") + "."
+	}
+	if !cancelled {
+		text += " Try again in a moment"
+		if len(result.Waiting) > 0 && isDiagnosable(result.Waiting[0].Service) {
+			text += fmt.Sprintf("; if %s keeps timing out, run ultimarr_diagnose_connection with service %q", result.Waiting[0].Service, strings.ToLower(result.Waiting[0].Service))
+		}
+		text += "."
+	}
+	text += " Changes the call was making may still complete in the background, so check before repeating them."
+
+	res := mcp.NewToolResultError(text)
+	setMeta(res, metaTimeout, result)
+	return res
+}
+
+func isDiagnosable(service string) bool {
+	for _, t := range connectionTargets() {
+		if t.Name == service {
+			return true
+		}
+	}
+	return false
+}
+
+// ----------------------------------------------------------------------------
+// Outstanding backend calls
+// ----------------------------------------------------------------------------
+
+type backendCall struct {
+	Service string
+	Method  string
+	Path    string // without the query, which may hold an API key
+	Start   time.Time
+}
+
+// backendCalls holds the backend requests one tool call has outstanding.
+type backendCalls struct {
+	mu    sync.Mutex
+	calls map[int]*backendCall
+	seq   int
+}
+
+type backendCallsKey struct{}
+
+// trackBackendCall records an outgoing request for the tool call ctx belongs
+// to until the returned function is called. Requests still running when the
+// call's deadline passes are kept, so the timeout result can name them.
+func trackBackendCall(ctx context.Context, method, urlStr string) func() {
+	calls, ok := ctx.Value(backendCallsKey{}).(*backendCalls)
+	if !ok {
+		return func() {}
+	}
+	call := &backendCall{Service: backendName(urlStr), Method: method, Start: time.Now()}
+	if u, err := url.Parse(urlStr); err == nil {
+		call.Path = u.Path
+	}
+
+	calls.mu.Lock()
+	calls.seq++
+	id := calls.seq
+	calls.calls[id] = call
+	calls.mu.Unlock()
+
+	return func() {
+		if ctx.Err() != nil {
+			return
+		}
+		calls.mu.Lock()
+		delete(calls.calls, id)
+		calls.mu.Unlock()
+	}
+}
+
+// outstanding lists the requests still running, oldest first.
+func (c *backendCalls) outstanding() []backendCall {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var calls []backendCall
+	for _, call := range c.calls {
+		calls = append(calls, *call)
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].Start.Before(calls[j].Start) })
+	return calls
+}
+
+// backendName names the configured service a URL belongs to.
+func backendName(urlStr string) string {
+	for _, svc := range configured {
+		if h, ok := svc.(httpService); ok && h.URL() != "" && strings.HasPrefix(urlStr, h.URL()) {
+			return h.Name()
+		}
+	}
+	if u, err := url.Parse(urlStr); err == nil {
+		return u.Host
+	}
+	return urlStr
+}
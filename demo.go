@@ -0,0 +1,1059 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// Demo Mode
+// ============================================================================
+
+// With ULTIMARR_MODE=demo every backend is replaced by canned data served
+// from an in-process transport, so tools can be tried without an *arr stack.
+// Writes are accepted and answered as a real server would, but nothing
+// changes.
+
+type demoJSON = map[string]interface{}
+
+// Demo hosts, one per backend
+const (
+	demoJellyseerr  = "jellyseerr.demo"
+	demoSonarr      = "sonarr.demo"
+	demoRadarr      = "radarr.demo"
+	demoJellyfin    = "jellyfin.demo"
+	demoQbittorrent = "qbittorrent.demo"
+	demoSabnzbd     = "sabnzbd.demo"
+)
+
+// enableDemoMode points every backend at the demo transport. It sets the
+// environment each Service configures itself from, so it must run before
+// configureServices.
+func enableDemoMode() {
+	for key, value := range map[string]string{
+		"JELLYSEERR_URL":         "http://" + demoJellyseerr,
+		"JELLYSEERR_API_KEY":     "demo",
+		"JELLYSEERR_API_KEY_ALT": "",
+		"JELLYSEERR_EMAIL":       "",
+		"SONARR_URL":             "http://" + demoSonarr,
+		"SONARR_API_KEY":         "demo",
+		"SONARR_API_KEY_ALT":     "",
+		"RADARR_URL":             "http://" + demoRadarr,
+		"RADARR_API_KEY":         "demo",
+		"RADARR_API_KEY_ALT":     "",
+		"JELLYFIN_URL":           "http://" + demoJellyfin,
+		"JELLYFIN_API_KEY":       "demo",
+		"QBITTORRENT_URL":        "http://" + demoQbittorrent,
+		"SABNZBD_URL":            "http://" + demoSabnzbd,
+		"SABNZBD_API_KEY":        "demo",
+	} {
+		os.Setenv(key, value)
+	}
+	config.DataDir = filepath.Join(os.TempDir(), "ultimarr-demo")
+	if len(config.Retention) == 0 {
+		config.Retention = []retentionPolicy{
+			{Name: "finished shows", Service: "sonarr", Ended: true, Watched: true, OlderThanDays: 180},
+			{Name: "unwatched movies", Service: "radarr", NotWatchedDays: 120},
+		}
+	}
+	httpTransport = demoTransport{}
+	log.Printf("Demo mode: serving canned data, no backends are contacted")
+}
+
+type demoTransport struct{}
+
+func (demoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+
+	var status int
+	var body interface{}
+	header := http.Header{"Content-Type": {"application/json"}}
+	switch req.URL.Host {
+	case demoJellyseerr:
+		status, body = demoJellyseerrResponse(req.Method, strings.TrimPrefix(req.URL.Path, "/api/v1"), req.URL.Query())
+	case demoSonarr:
+		status, body = demoArrResponse(demoSonarrData, req.Method, strings.TrimPrefix(req.URL.Path, "/api/v3"), req.URL.Query())
+	case demoRadarr:
+		status, body = demoArrResponse(demoRadarrData, req.Method, strings.TrimPrefix(req.URL.Path, "/api/v3"), req.URL.Query())
+	case demoJellyfin:
+		status, body = demoJellyfinResponse(req.URL.Path)
+	case demoQbittorrent:
+		if req.URL.Path == "/api/v2/auth/login" {
+			header.Set("Set-Cookie", "SID=demo")
+		}
+		status, body = demoQbittorrentResponse(strings.TrimPrefix(req.URL.Path, "/api/v2"), req.URL.Query())
+	case demoSabnzbd:
+		status, body = demoSabnzbdResponse(req.URL.Query().Get("mode"))
+	default:
+		status, body = http.StatusNotFound, demoJSON{"message": "Not available in demo mode"}
+	}
+
+	data, _ := json.Marshal(body)
+	if text, ok := body.(demoText); ok {
+		data = []byte(text)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader(data)),
+		Request:    req,
+	}, nil
+}
+
+// demoText is a plain-text response body.
+type demoText string
+
+// demoAgo formats a time relative to now the way the APIs do.
+func demoAgo(d time.Duration) string {
+	return time.Now().Add(-d).UTC().Format(time.RFC3339)
+}
+
+const (
+	demoDay = 24 * time.Hour
+	demoGB  = 1 << 30
+)
+
+// ----------------------------------------------------------------------------
+// Library
+// ----------------------------------------------------------------------------
+
+type demoSeason struct {
+	Number   int
+	Episodes int
+	Files    int // the first Files episodes are downloaded
+	Aired    int // the first Aired episodes have aired
+}
+
+type demoShow struct {
+	ID, TvdbID, TmdbID int
+	Title              string
+	Year               int
+	Network            string
+	Status             string
+	Runtime            int
+	Overview           string
+	Seasons            []demoSeason
+	Tags               []int
+	Added              time.Duration // how long ago it was added
+}
+
+type demoFilm struct {
+	ID, TmdbID int
+	Title      string
+	Year       int
+	Studio     string
+	Runtime    int
+	Overview   string
+	Size       int64 // 0 when there is no file
+	Resolution int
+	Tags       []int
+	Added      time.Duration
+}
+
+var demoShows = []demoShow{
+	{ID: 1, TvdbID: 371980, TmdbID: 95396, Title: "Severance", Year: 2022, Network: "Apple TV+", Status: "continuing", Runtime: 55,
+		Overview: "Mark leads a team of office workers whose memories have been surgically divided between their work and personal lives.",
+		Seasons:  []demoSeason{{1, 9, 9, 9}, {2, 10, 8, 9}}, Added: 400 * demoDay},
+	{ID: 2, TvdbID: 403245, TmdbID: 136315, Title: "The Bear", Year: 2022, Network: "FX", Status: "continuing", Runtime: 32,
+		Overview: "A young chef from the fine dining world returns to Chicago to run his family's sandwich shop.",
+		Seasons:  []demoSeason{{1, 8, 8, 8}, {2, 10, 10, 10}, {3, 10, 10, 10}}, Added: 300 * demoDay},
+	{ID: 3, TvdbID: 421216, TmdbID: 126308, Title: "Shōgun", Year: 2024, Network: "FX", Status: "continuing", Runtime: 60,
+		Overview: "In 1600 Japan, Lord Yoshii Toranaga fights for his life as his enemies on the Council of Regents unite against him.",
+		Seasons:  []demoSeason{{1, 10, 10, 10}}, Added: 200 * demoDay},
+	{ID: 4, TvdbID: 81189, TmdbID: 1396, Title: "Breaking Bad", Year: 2008, Network: "AMC", Status: "ended", Runtime: 47,
+		Overview: "A high school chemistry teacher diagnosed with terminal cancer turns to manufacturing methamphetamine.",
+		Seasons:  []demoSeason{{1, 7, 7, 7}, {2, 13, 13, 13}, {3, 13, 13, 13}, {4, 13, 13, 13}, {5, 16, 16, 16}}, Tags: []int{2}, Added: 900 * demoDay},
+}
+
+var demoFilms = []demoFilm{
+	{ID: 1, TmdbID: 693134, Title: "Dune: Part Two", Year: 2024, Studio: "Legendary Pictures", Runtime: 166,
+		Overview: "Paul Atreides unites with Chani and the Fremen while on a path of revenge against those who destroyed his family.",
+		Size:     62 * demoGB, Resolution: 2160, Tags: []int{1}, Added: 150 * demoDay},
+	{ID: 2, TmdbID: 872585, Title: "Oppenheimer", Year: 2023, Studio: "Universal Pictures", Runtime: 180,
+		Overview: "The story of J. Robert Oppenheimer's role in the development of the atomic bomb during World War II.",
+		Size:     14 * demoGB, Resolution: 1080, Added: 250 * demoDay},
+	{ID: 3, TmdbID: 666277, Title: "Past Lives", Year: 2023, Studio: "A24", Runtime: 106,
+		Overview: "Nora and Hae Sung, two childhood friends, are reunited in New York for one fateful week.",
+		Size:     8 * demoGB, Resolution: 1080, Added: 320 * demoDay},
+	{ID: 4, TmdbID: 467244, Title: "The Zone of Interest", Year: 2023, Studio: "A24", Runtime: 105,
+		Overview: "The commandant of Auschwitz and his wife strive to build a dream life next to the camp.",
+		Added:    2 * demoDay},
+	{ID: 5, TmdbID: 792307, Title: "Poor Things", Year: 2023, Studio: "Searchlight Pictures", Runtime: 141,
+		Overview: "Bella Baxter, brought back to life by an unorthodox scientist, runs off on a whirlwind adventure.",
+		Size:     11 * demoGB, Resolution: 1080, Added: 100 * demoDay},
+}
+
+// Genres by TMDB ID
+var demoGenres = map[int][]interface{}{
+	95396: {"Drama", "Mystery", "Science Fiction"}, 136315: {"Comedy", "Drama"}, 126308: {"Drama", "History"},
+	1396: {"Crime", "Drama"}, 693134: {"Science Fiction", "Adventure"}, 872585: {"Drama", "History"},
+	666277: {"Drama", "Romance"}, 467244: {"Drama", "History", "War"}, 792307: {"Comedy", "Science Fiction"},
+}
+
+// US ratings by TMDB ID
+var demoRatings = map[int]string{
+	95396: "TV-MA", 136315: "TV-MA", 126308: "TV-MA", 1396: "TV-MA", 100088: "TV-MA", 94997: "TV-MA",
+	693134: "PG-13", 872585: "R", 666277: "PG-13", 467244: "PG-13", 792307: "R", 915935: "R", 840430: "R",
+}
+
+// Titles Jellyseerr knows about that aren't in the library
+var demoElsewhere = []demoJSON{
+	{"id": 915935.0, "mediaType": "movie", "title": "Anatomy of a Fall", "releaseDate": "2023-08-23", "originalLanguage": "fr"},
+	{"id": 840430.0, "mediaType": "movie", "title": "The Holdovers", "releaseDate": "2023-10-27", "originalLanguage": "en"},
+	{"id": 100088.0, "mediaType": "tv", "name": "The Last of Us", "firstAirDate": "2023-01-15", "originalLanguage": "en"},
+	{"id": 94997.0, "mediaType": "tv", "name": "House of the Dragon", "firstAirDate": "2022-08-21", "originalLanguage": "en"},
+}
+
+// Network or studio of each title, by TMDB ID
+var demoCompanies = map[int]string{
+	915935: "Neon", 840430: "Focus Features", 100088: "HBO", 94997: "HBO",
+}
+
+func init() {
+	for _, s := range demoShows {
+		demoCompanies[s.TmdbID] = s.Network
+	}
+	for _, f := range demoFilms {
+		demoCompanies[f.TmdbID] = f.Studio
+	}
+}
+
+var demoCompanyPath = regexp.MustCompile(`^/discover/(?:tv|movies)/(network|studio)/(\d+)$`)
+
+// demoCompanyName returns the featured network or studio name for an ID.
+func demoCompanyName(kind string, id int) string {
+	featured := discoverNetworks
+	if kind == "studio" {
+		featured = discoverStudios
+	}
+	for _, f := range featured {
+		if f.ID == id {
+			return f.Name
+		}
+	}
+	return "?"
+}
+
+// demoMediaStatus returns Jellyseerr's media status code for a status name.
+func demoMediaStatus(name string) float64 {
+	for code, n := range mediaStatusNames {
+		if n == name {
+			return float64(code)
+		}
+	}
+	return 1
+}
+
+// ----------------------------------------------------------------------------
+// Jellyseerr
+// ----------------------------------------------------------------------------
+
+// demoMedia lists every title Jellyseerr knows, with library status.
+func demoMedia() []demoJSON {
+	var media []demoJSON
+	for _, s := range demoShows {
+		status := "available"
+		if s.ID == 1 {
+			status = "partial"
+		}
+		media = append(media, demoJSON{
+			"id": float64(s.TmdbID), "mediaType": "tv", "name": s.Title, "firstAirDate": fmt.Sprintf("%d-01-01", s.Year),
+			"originalLanguage": "en", "overview": s.Overview,
+			"mediaInfo": demoJSON{"status": demoMediaStatus(status), "tvdbId": float64(s.TvdbID)},
+		})
+	}
+	for _, f := range demoFilms {
+		status := "available"
+		if f.Size == 0 {
+			status = "processing"
+		}
+		media = append(media, demoJSON{
+			"id": float64(f.TmdbID), "mediaType": "movie", "title": f.Title, "releaseDate": fmt.Sprintf("%d-06-01", f.Year),
+			"originalLanguage": "en", "overview": f.Overview,
+			"mediaInfo": demoJSON{"status": demoMediaStatus(status)},
+		})
+	}
+	for _, m := range demoElsewhere {
+		entry := demoJSON{}
+		for k, v := range m {
+			entry[k] = v
+		}
+		switch m["id"] {
+		case 840430.0:
+			entry["mediaInfo"] = demoJSON{"status": demoMediaStatus("pending")}
+		case 94997.0:
+			// Still available in Jellyseerr after being removed from Sonarr
+			entry["mediaInfo"] = demoJSON{"status": demoMediaStatus("available"), "tvdbId": 371572.0}
+		case 100088.0:
+			// Approved, but never reached Sonarr
+			entry["mediaInfo"] = demoJSON{"status": demoMediaStatus("processing"), "tvdbId": 392256.0}
+		}
+		media = append(media, entry)
+	}
+	return media
+}
+
+func demoRequests() []demoJSON {
+	user := func(name string) demoJSON { return demoJSON{"id": 2.0, "displayName": name} }
+	return []demoJSON{
+		{"id": 11.0, "status": 1.0, "createdAt": demoAgo(5 * time.Hour), "requestedBy": user("Alex"),
+			"media": demoJSON{"mediaType": "movie", "tmdbId": 840430.0, "status": demoMediaStatus("pending")}},
+		{"id": 10.0, "status": 2.0, "createdAt": demoAgo(3 * demoDay), "requestedBy": user("Sam"),
+			"serverId": 0.0, "profileId": 1.0, "rootFolder": "/data/tv", "tags": []interface{}{},
+			"seasons": []interface{}{demoJSON{"seasonNumber": 2.0, "status": 2.0}},
+			"media": demoJSON{"mediaType": "tv", "tmdbId": 95396.0, "tvdbId": 371980.0, "status": demoMediaStatus("partial"),
+				"seasons": []interface{}{
+					demoJSON{"seasonNumber": 1.0, "status": demoMediaStatus("available")},
+					demoJSON{"seasonNumber": 2.0, "status": demoMediaStatus("processing")},
+				}}},
+		{"id": 9.0, "status": 2.0, "createdAt": demoAgo(2 * demoDay), "requestedBy": user("Sam"),
+			"serverId": 0.0, "profileId": 1.0, "rootFolder": "/data/movies", "tags": []interface{}{},
+			"media": demoJSON{"mediaType": "movie", "tmdbId": 467244.0, "status": demoMediaStatus("processing")}},
+		{"id": 8.0, "status": 3.0, "createdAt": demoAgo(12 * demoDay), "requestedBy": user("Alex"),
+			"media": demoJSON{"mediaType": "movie", "tmdbId": 915935.0, "status": demoMediaStatus("unknown")}},
+		{"id": 7.0, "status": 2.0, "createdAt": demoAgo(100 * demoDay), "requestedBy": user("Alex"),
+			"media": demoJSON{"mediaType": "movie", "tmdbId": 792307.0, "status": demoMediaStatus("available"), "mediaAddedAt": demoAgo(99 * demoDay)}},
+		{"id": 6.0, "status": 2.0, "createdAt": demoAgo(202 * demoDay), "requestedBy": user("Sam"),
+			"seasons": []interface{}{demoJSON{"seasonNumber": 1.0, "status": 2.0}},
+			"media": demoJSON{"mediaType": "tv", "tmdbId": 126308.0, "tvdbId": 421216.0, "status": demoMediaStatus("available"), "mediaAddedAt": demoAgo(200 * demoDay),
+				"seasons": []interface{}{demoJSON{"seasonNumber": 1.0, "status": demoMediaStatus("available")}}}},
+	}
+}
+
+var demoIDPath = regexp.MustCompile(`^/(movie|tv|request|blacklist|user)/(\d+)(/.*)?$`)
+
+func demoJellyseerrResponse(method, path string, query map[string][]string) (int, interface{}) {
+	get := func(k string) string {
+		if v := query[k]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+
+	if m := demoIDPath.FindStringSubmatch(path); m != nil {
+		id, _ := strconv.Atoi(m[2])
+		switch m[1] {
+		case "movie", "tv":
+			for _, media := range demoMedia() {
+				if media["id"] == float64(id) && media["mediaType"] == m[1] {
+					media["keywords"] = []interface{}{}
+					genres := []interface{}{}
+					for _, g := range demoGenres[id] {
+						genres = append(genres, demoJSON{"name": g})
+					}
+					media["genres"] = genres
+					if m[1] == "movie" {
+						media["releases"] = demoJSON{"results": []interface{}{demoJSON{"iso_3166_1": "US",
+							"release_dates": []interface{}{demoJSON{"certification": demoRatings[id], "type": 3.0, "descriptors": []interface{}{}}}}}}
+					} else {
+						media["contentRatings"] = demoJSON{"results": []interface{}{demoJSON{"iso_3166_1": "US", "rating": demoRatings[id]}}}
+					}
+					return http.StatusOK, media
+				}
+			}
+			return http.StatusNotFound, demoJSON{"message": "Not found"}
+		case "request":
+			for _, r := range demoRequests() {
+				if r["id"] == float64(id) {
+					return http.StatusOK, r
+				}
+			}
+			return http.StatusNotFound, demoJSON{"message": "Request not found"}
+		case "blacklist":
+			return http.StatusNotFound, demoJSON{"message": "Not blacklisted"}
+		case "user":
+			return http.StatusOK, demoJSON{}
+		}
+	}
+
+	switch {
+	case path == "/auth/me":
+		return http.StatusOK, demoJSON{"id": 1, "displayName": "Demo Admin", "permissions": 2}
+	case path == "/status":
+		return http.StatusOK, demoJSON{"version": "2.1.0"}
+	case path == "/request/count":
+		return http.StatusOK, demoJSON{"total": 6, "movie": 4, "tv": 2, "pending": 1, "approved": 4, "declined": 1, "processing": 2, "available": 2}
+	case path == "/request" && method == "POST":
+		return http.StatusCreated, demoJSON{"id": 12, "status": 2}
+	case path == "/request":
+		var results []demoJSON
+		for _, r := range demoRequests() {
+			switch get("filter") {
+			case "pending":
+				if r["status"] != 1.0 {
+					continue
+				}
+			case "processing":
+				if r["status"] != 2.0 || r["media"].(demoJSON)["status"] == demoMediaStatus("available") {
+					continue
+				}
+			}
+			results = append(results, r)
+		}
+		return http.StatusOK, demoJSON{"results": results, "pageInfo": demoJSON{"results": len(results)}}
+	case path == "/media":
+		results := []demoJSON{}
+		for i, m := range demoMedia() {
+			info, ok := m["mediaInfo"].(demoJSON)
+			if !ok {
+				continue
+			}
+			status := mediaStatusNames[int(info["status"].(float64))]
+			switch get("filter") {
+			case "allavailable":
+				if status != "available" && status != "partial" {
+					continue
+				}
+			case "processing":
+				if status != "processing" {
+					continue
+				}
+			}
+			results = append(results, demoJSON{"id": float64(i + 1), "mediaType": m["mediaType"], "tmdbId": m["id"],
+				"tvdbId": info["tvdbId"], "status": info["status"]})
+		}
+		return http.StatusOK, demoJSON{"results": results, "pageInfo": demoJSON{"results": len(results)}}
+	case path == "/user" && method == "POST":
+		return http.StatusCreated, demoJSON{"id": 5, "email": "new.user@example.com"}
+	case path == "/search/company":
+		return http.StatusOK, demoJSON{"results": []demoJSON{{"id": 41077, "name": "A24"}}}
+	case path == "/search":
+		q := normalizeQuery(get("query"))
+		q, _ = stripYear(q)
+		results := []demoJSON{}
+		for _, m := range demoMedia() {
+			name, _ := mediaNameYear(m)
+			if q != "" && strings.Contains(normalizeQuery(name), q) {
+				results = append(results, m)
+			}
+		}
+		return http.StatusOK, demoJSON{"page": 1, "totalPages": 1, "totalResults": len(results), "results": results}
+	case strings.HasPrefix(path, "/discover"):
+		company := ""
+		if m := demoCompanyPath.FindStringSubmatch(path); m != nil {
+			id, _ := strconv.Atoi(m[2])
+			company = demoCompanyName(m[1], id)
+		}
+		results := []demoJSON{}
+		for _, m := range demoMedia() {
+			isTV := m["mediaType"] == "tv"
+			if strings.HasPrefix(path, "/discover/tv") && !isTV || strings.HasPrefix(path, "/discover/movies") && isTV {
+				continue
+			}
+			if company != "" && !strings.HasPrefix(demoCompanies[int(m["id"].(float64))], company) {
+				continue
+			}
+			results = append(results, m)
+		}
+		return http.StatusOK, demoJSON{"page": 1, "totalPages": 1, "totalResults": len(results), "results": results}
+	case method != "GET":
+		return http.StatusOK, demoJSON{}
+	}
+	return http.StatusNotFound, demoJSON{"message": "Not available in demo mode"}
+}
+
+// ----------------------------------------------------------------------------
+// Sonarr / Radarr
+// ----------------------------------------------------------------------------
+
+// demoArr holds one *arr's canned data.
+type demoArr struct {
+	Version  string
+	Root     string
+	Items    func() []demoJSON       // /series or /movie
+	Files    func(id int) []demoJSON // episode or movie files of an item
+	Queue    func() []demoJSON
+	Releases func() []demoJSON
+	History  func() []demoJSON // newest first
+	Lookup   []demoJSON        // titles not in the library
+	Missing  func() []demoJSON // Sonarr's wanted/missing episodes
+	Episodes func(id int) []demoJSON
+	Category string
+}
+
+var demoSonarrData = demoArr{
+	Version: "4.0.9.2244", Root: "/data/tv", Category: "tvCategory",
+	Items: func() []demoJSON {
+		var items []demoJSON
+		for _, s := range demoShows {
+			items = append(items, demoSeriesJSON(s))
+		}
+		return items
+	},
+	Files: func(id int) []demoJSON {
+		var files []demoJSON
+		for _, e := range demoEpisodes(id) {
+			if e["hasFile"] == true {
+				size := 1.6 * demoGB
+				if id == 3 && e["episodeNumber"] == 7.0 {
+					size = 0.12 * demoGB // a sample imported in place of Shōgun S01E07
+				}
+				files = append(files, demoJSON{
+					"id": e["episodeFileId"], "seriesId": float64(id), "seasonNumber": e["seasonNumber"],
+					"relativePath": fmt.Sprintf("Season %02d/S%02dE%02d.mkv", int(e["seasonNumber"].(float64)), int(e["seasonNumber"].(float64)), int(e["episodeNumber"].(float64))),
+					"size":         size,
+					"quality":      demoJSON{"quality": demoJSON{"name": "WEBDL-1080p", "resolution": 1080.0}},
+				})
+			}
+		}
+		if id == 2 {
+			// Left behind when a repack replaced The Bear S02E05
+			files = append(files, demoJSON{
+				"id": 2999.0, "seriesId": 2.0, "seasonNumber": 2.0, "relativePath": "Season 02/S02E05.REPACK.mkv",
+				"size": 1.1 * demoGB, "quality": demoJSON{"quality": demoJSON{"name": "WEBDL-1080p", "resolution": 1080.0}},
+			})
+		}
+		return files
+	},
+	Queue: func() []demoJSON {
+		show := demoShows[0]
+		return []demoJSON{{
+			"id": 501.0, "title": "Severance.S02E09.The.After.Hours.1080p.ATVP.WEB-DL.DDP5.1.H.264-FLUX",
+			"status": "downloading", "trackedDownloadStatus": "ok", "trackedDownloadState": "downloading",
+			"size": 3.2 * demoGB, "sizeleft": 1.1 * demoGB, "timeleft": "00:09:40", "protocol": "torrent",
+			"downloadClient": "qBittorrent", "downloadId": "8F2C6A0E4B1D3C5E7A9B0D2F4E6A8C0B1D3F5E7A",
+			"outputPath":     "/data/downloads/tv-sonarr/Severance.S02E09.The.After.Hours.1080p.ATVP.WEB-DL.DDP5.1.H.264-FLUX",
+			"statusMessages": []interface{}{},
+			"series":         demoSeriesJSON(show), "seriesId": 1.0,
+			"episode": demoJSON{"seasonNumber": 2.0, "episodeNumber": 9.0, "title": "The After Hours"},
+		}}
+	},
+	Releases: func() []demoJSON {
+		return []demoJSON{
+			demoRelease("Severance.S02E09.1080p.ATVP.WEB-DL.DDP5.1.H.264-FLUX", 3.2*demoGB, 412, 30, "torrent", []float64{9}),
+			demoRelease("Severance.S02E09.2160p.ATVP.WEB-DL.DDP5.1.DV.HDR.H.265-FLUX", 9.8*demoGB, 188, 30, "torrent", []float64{9}),
+			demoRelease("Severance.S02E09.720p.ATVP.WEB-DL.DDP5.1.H.264-NTb", 1.4*demoGB, 95, 29, "torrent", []float64{9}),
+			demoRelease("Severance.S02E09.1080p.WEB.h264-ETHEL", 2.9*demoGB, 0, 31, "usenet", []float64{9}),
+		}
+	},
+	History: func() []demoJSON {
+		show := demoSeriesJSON(demoShows[0])
+		return []demoJSON{
+			{"id": 7003.0, "eventType": "grabbed", "date": demoAgo(40 * time.Minute), "seriesId": 1.0,
+				"sourceTitle": "Severance.S02E09.The.After.Hours.1080p.ATVP.WEB-DL.DDP5.1.H.264-FLUX",
+				"downloadId":  "8F2C6A0E4B1D3C5E7A9B0D2F4E6A8C0B1D3F5E7A", "series": show,
+				"quality": demoJSON{"quality": demoJSON{"name": "WEBDL-1080p", "resolution": 1080.0}},
+				"data":    demoJSON{"indexer": "TorrentLeech", "size": "3435973837"}},
+			{"id": 7002.0, "eventType": "downloadFailed", "date": demoAgo(2 * time.Hour), "seriesId": 1.0,
+				"sourceTitle": "Severance.S02E09.1080p.WEB.h264-ETHEL", "downloadId": "SABnzbd_nzo_k3j9x2", "series": show},
+			{"id": 7001.0, "eventType": "downloadFolderImported", "date": demoAgo(6 * time.Hour), "seriesId": 1.0,
+				"sourceTitle": "Severance.S02E08.Sweet.Vitriol.1080p.ATVP.WEB-DL.DDP5.1.H.264-FLUX", "series": show,
+				"downloadId": "2B4D6F8A0C1E3B5D7F9A1C3E5B7D9F0A2C4E6B8D",
+				"episode":    demoJSON{"seasonNumber": 2.0, "episodeNumber": 8.0, "title": "Sweet Vitriol"}},
+			{"id": 7000.0, "eventType": "grabbed", "date": demoAgo(3 * time.Hour), "seriesId": 1.0,
+				"sourceTitle": "Severance.S02E09.1080p.WEB.h264-ETHEL", "downloadId": "SABnzbd_nzo_k3j9x2", "series": show,
+				"quality": demoJSON{"quality": demoJSON{"name": "WEBDL-1080p", "resolution": 1080.0}},
+				"data":    demoJSON{"indexer": "NZBgeek", "size": "3113851290"}},
+			{"id": 6999.0, "eventType": "grabbed", "date": demoAgo(7 * time.Hour), "seriesId": 1.0,
+				"sourceTitle": "Severance.S02E08.Sweet.Vitriol.1080p.ATVP.WEB-DL.DDP5.1.H.264-FLUX",
+				"downloadId":  "2B4D6F8A0C1E3B5D7F9A1C3E5B7D9F0A2C4E6B8D", "series": show,
+				"quality": demoJSON{"quality": demoJSON{"name": "WEBDL-1080p", "resolution": 1080.0}},
+				"data":    demoJSON{"indexer": "TorrentLeech", "size": "3328599654"}},
+		}
+	},
+	Lookup: []demoJSON{
+		{"title": "The Last of Us", "year": 2023.0, "tvdbId": 392256.0, "tmdbId": 100088.0, "seriesType": "standard", "status": "continuing"},
+		{"title": "House of the Dragon", "year": 2022.0, "tvdbId": 371572.0, "tmdbId": 94997.0, "seriesType": "standard", "status": "continuing"},
+	},
+	Missing: func() []demoJSON {
+		var missing []demoJSON
+		for _, e := range demoEpisodes(1) {
+			if e["hasFile"] == false && e["aired"] == true {
+				e["series"] = demoSeriesJSON(demoShows[0])
+				missing = append(missing, e)
+			}
+		}
+		return missing
+	},
+	Episodes: demoEpisodes,
+}
+
+var demoRadarrData = demoArr{
+	Version: "5.14.0.9383", Root: "/data/movies", Category: "movieCategory",
+	Items: func() []demoJSON {
+		var items []demoJSON
+		for _, f := range demoFilms {
+			items = append(items, demoMovieJSON(f))
+		}
+		return items
+	},
+	Files: func(id int) []demoJSON {
+		for _, f := range demoFilms {
+			if f.ID == id && f.Size > 0 {
+				files := []demoJSON{demoMovieJSON(f)["movieFile"].(demoJSON)}
+				if id == 2 {
+					// The 720p copy an upgrade didn't remove
+					files = append(files, demoJSON{
+						"id": 902.0, "movieId": 2.0, "size": 5.0 * demoGB, "relativePath": "Oppenheimer (2023) 720p.mkv",
+						"quality": demoJSON{"quality": demoJSON{"name": "Bluray-720p", "resolution": 720.0}},
+					})
+				}
+				return files
+			}
+		}
+		return []demoJSON{}
+	},
+	Queue: func() []demoJSON {
+		return []demoJSON{{
+			"id": 601.0, "title": "The.Zone.of.Interest.2023.1080p.BluRay.x264-PiGNUS",
+			"status": "downloading", "trackedDownloadStatus": "ok", "trackedDownloadState": "downloading",
+			"size": 9.1 * demoGB, "sizeleft": 6.3 * demoGB, "timeleft": "00:41:12", "protocol": "torrent",
+			"downloadClient": "qBittorrent", "downloadId": "1A3C5E7B9D0F2A4C6E8B0D1F3A5C7E9B2D4F6A8C",
+			"outputPath":     "/data/downloads/radarr/The.Zone.of.Interest.2023.1080p.BluRay.x264-PiGNUS",
+			"statusMessages": []interface{}{},
+			"movie":          demoMovieJSON(demoFilms[3]), "movieId": 4.0,
+		}}
+	},
+	Releases: func() []demoJSON {
+		return []demoJSON{
+			demoRelease("The.Zone.of.Interest.2023.1080p.BluRay.x264-PiGNUS", 9.1*demoGB, 230, 120, "torrent", nil),
+			demoRelease("The.Zone.of.Interest.2023.2160p.UHD.BluRay.REMUX.HDR.HEVC.DTS-HD.MA.5.1-FGT", 58*demoGB, 41, 110, "torrent", nil),
+			demoRelease("The.Zone.of.Interest.2023.720p.WEB.h264-EDITH", 2.3*demoGB, 77, 200, "torrent", nil),
+		}
+	},
+	History: func() []demoJSON {
+		movie := demoMovieJSON(demoFilms[3])
+		return []demoJSON{
+			{"id": 8002.0, "eventType": "grabbed", "date": demoAgo(25 * time.Minute), "movieId": 4.0,
+				"sourceTitle": "The.Zone.of.Interest.2023.1080p.BluRay.x264-PiGNUS",
+				"downloadId":  "1A3C5E7B9D0F2A4C6E8B0D1F3A5C7E9B2D4F6A8C", "movie": movie,
+				"quality": demoJSON{"quality": demoJSON{"name": "Bluray-1080p", "resolution": 1080.0}},
+				"data":    demoJSON{"indexer": "TorrentLeech", "size": "11274289152"}},
+			{"id": 8001.0, "eventType": "downloadFolderImported", "date": demoAgo(20 * time.Hour), "movieId": 5.0,
+				"sourceTitle": "Poor.Things.2023.1080p.BluRay.x264-SPRiNTER", "movie": demoMovieJSON(demoFilms[4]),
+				"downloadId": "SABnzbd_nzo_p7q2m8"},
+			{"id": 8000.0, "eventType": "grabbed", "date": demoAgo(22 * time.Hour), "movieId": 5.0,
+				"sourceTitle": "Poor.Things.2023.1080p.BluRay.x264-SPRiNTER", "movie": demoMovieJSON(demoFilms[4]),
+				"downloadId": "SABnzbd_nzo_p7q2m8",
+				"quality":    demoJSON{"quality": demoJSON{"name": "Bluray-1080p", "resolution": 1080.0}},
+				"data":       demoJSON{"indexer": "NZBgeek", "size": "13421772800"}},
+		}
+	},
+	Lookup: []demoJSON{
+		{"title": "Anatomy of a Fall", "year": 2023.0, "tmdbId": 915935.0, "status": "released"},
+		{"title": "The Holdovers", "year": 2023.0, "tmdbId": 840430.0, "status": "released"},
+	},
+	Missing:  func() []demoJSON { return []demoJSON{} },
+	Episodes: func(int) []demoJSON { return []demoJSON{} },
+}
+
+func demoImages(kind string, id, tmdbID int) []interface{} {
+	return []interface{}{
+		demoJSON{"coverType": "poster", "url": fmt.Sprintf("/MediaCover/%d/poster.jpg", id), "remoteUrl": fmt.Sprintf("https://image.tmdb.org/t/p/original/demo-%s-%d-poster.jpg", kind, tmdbID)},
+		demoJSON{"coverType": "fanart", "url": fmt.Sprintf("/MediaCover/%d/fanart.jpg", id), "remoteUrl": fmt.Sprintf("https://image.tmdb.org/t/p/original/demo-%s-%d-fanart.jpg", kind, tmdbID)},
+	}
+}
+
+func demoSeriesJSON(s demoShow) demoJSON {
+	var seasons []interface{}
+	var files, episodes, total int
+	for _, se := range s.Seasons {
+		seasons = append(seasons, demoJSON{
+			"seasonNumber": float64(se.Number), "monitored": true,
+			"statistics": demoJSON{
+				"episodeCount": float64(se.Aired), "episodeFileCount": float64(se.Files),
+				"totalEpisodeCount": float64(se.Episodes), "sizeOnDisk": float64(se.Files) * 1.6 * demoGB,
+			},
+		})
+		files += se.Files
+		episodes += se.Aired
+		total += se.Episodes
+	}
+	tags := []interface{}{}
+	for _, t := range s.Tags {
+		tags = append(tags, float64(t))
+	}
+	return demoJSON{
+		"id": float64(s.ID), "title": s.Title, "year": float64(s.Year), "tvdbId": float64(s.TvdbID), "tmdbId": float64(s.TmdbID),
+		"status": s.Status, "ended": s.Status == "ended", "network": s.Network, "runtime": float64(s.Runtime), "overview": s.Overview,
+		"monitored": true, "seasonFolder": true, "seriesType": "standard", "qualityProfileId": 1.0,
+		"path": "/data/tv/" + s.Title, "added": demoAgo(s.Added), "tags": tags, "seasons": seasons, "genres": demoGenres[s.TmdbID],
+		"certification": demoRatings[s.TmdbID], "images": demoImages("tv", s.ID, s.TmdbID),
+		"statistics": demoJSON{
+			"seasonCount": float64(len(s.Seasons)), "episodeFileCount": float64(files), "episodeCount": float64(episodes),
+			"totalEpisodeCount": float64(total), "sizeOnDisk": float64(files) * 1.6 * demoGB,
+			"percentOfEpisodes": float64(files) * 100 / float64(max(episodes, 1)),
+		},
+	}
+}
+
+func demoMovieJSON(f demoFilm) demoJSON {
+	tags := []interface{}{}
+	for _, t := range f.Tags {
+		tags = append(tags, float64(t))
+	}
+	movie := demoJSON{
+		"id": float64(f.ID), "title": f.Title, "year": float64(f.Year), "tmdbId": float64(f.TmdbID),
+		"studio": f.Studio, "runtime": float64(f.Runtime), "overview": f.Overview, "status": "released",
+		"monitored": true, "isAvailable": true, "hasFile": f.Size > 0, "sizeOnDisk": float64(f.Size),
+		"qualityProfileId": 1.0, "minimumAvailability": "released",
+		"path": fmt.Sprintf("/data/movies/%s (%d)", f.Title, f.Year), "added": demoAgo(f.Added), "tags": tags, "genres": demoGenres[f.TmdbID],
+		"certification": demoRatings[f.TmdbID], "images": demoImages("movie", f.ID, f.TmdbID),
+	}
+	if f.Size > 0 {
+		name := "Bluray-1080p"
+		if f.Resolution == 2160 {
+			name = "Bluray-2160p"
+		}
+		movie["movieFile"] = demoJSON{
+			"id": float64(100 + f.ID), "movieId": float64(f.ID), "size": float64(f.Size),
+			"relativePath": fmt.Sprintf("%s (%d).mkv", f.Title, f.Year),
+			"quality":      demoJSON{"quality": demoJSON{"name": name, "resolution": float64(f.Resolution)}},
+		}
+	}
+	return movie
+}
+
+// demoEpisodes generates a show's episodes. The last season's unaired
+// episodes air a week apart from a few days from now.
+func demoEpisodes(seriesID int) []demoJSON {
+	var show *demoShow
+	for i := range demoShows {
+		if demoShows[i].ID == seriesID {
+			show = &demoShows[i]
+		}
+	}
+	episodes := []demoJSON{}
+	if show == nil {
+		return episodes
+	}
+	id := seriesID * 1000
+	for _, se := range show.Seasons {
+		for n := 1; n <= se.Episodes; n++ {
+			id++
+			aired := n <= se.Aired
+			airDate := time.Date(show.Year+se.Number-1, 1, 17, 2, 0, 0, 0, time.UTC).AddDate(0, 0, 7*(n-1))
+			if !aired {
+				airDate = time.Now().UTC().Add(time.Duration(3+7*(n-se.Aired-1)) * demoDay).Truncate(demoDay)
+			} else if se == show.Seasons[len(show.Seasons)-1] && show.Status == "continuing" && se.Aired < se.Episodes {
+				airDate = time.Now().UTC().Add(-time.Duration(4+7*(se.Aired-n)) * demoDay).Truncate(demoDay)
+			}
+			e := demoJSON{
+				"id": float64(id), "seriesId": float64(seriesID), "seasonNumber": float64(se.Number), "episodeNumber": float64(n),
+				"title": fmt.Sprintf("Episode %d", n), "airDateUtc": airDate.Format(time.RFC3339), "airDate": airDate.Format("2006-01-02"),
+				"monitored": true, "hasFile": n <= se.Files, "aired": aired, "runtime": float64(show.Runtime),
+				"overview": fmt.Sprintf("Season %d, episode %d of %s.", se.Number, n, show.Title),
+			}
+			if n <= se.Files {
+				e["episodeFileId"] = float64(id)
+			} else {
+				e["episodeFileId"] = 0.0
+			}
+			episodes = append(episodes, e)
+		}
+	}
+	return episodes
+}
+
+func demoRelease(title string, size float64, seeders int, ageDays int, protocol string, episodes []float64) demoJSON {
+	r := demoJSON{
+		"guid": "demo-" + strings.ToLower(title), "title": title, "size": size, "protocol": protocol,
+		"indexerId": 1.0, "indexer": "TorrentLeech", "seeders": float64(seeders),
+		"age": float64(ageDays), "ageHours": float64(ageDays * 24), "publishDate": demoAgo(time.Duration(ageDays) * demoDay),
+		"approved": true, "seasonNumber": 2.0, "fullSeason": false,
+	}
+	if protocol == "usenet" {
+		r["indexerId"], r["indexer"] = 2.0, "NZBgeek"
+		delete(r, "seeders")
+	}
+	if episodes != nil {
+		r["episodeNumbers"] = episodes
+	}
+	return r
+}
+
+var demoArrIDPath = regexp.MustCompile(`^/(series|movie|command|episode|tag|remotepathmapping)/(\d+)$`)
+
+func demoArrResponse(arr demoArr, method, path string, query map[string][]string) (int, interface{}) {
+	get := func(k string) string {
+		if v := query[k]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	itemID, _ := strconv.Atoi(get("seriesId"))
+	if get("movieId") != "" {
+		itemID, _ = strconv.Atoi(get("movieId"))
+	}
+
+	if m := demoArrIDPath.FindStringSubmatch(path); m != nil {
+		id, _ := strconv.Atoi(m[2])
+		switch {
+		case m[1] == "command":
+			return http.StatusOK, demoJSON{"id": float64(id), "status": "completed"}
+		case method != "GET":
+			return http.StatusOK, demoJSON{}
+		}
+		for _, item := range arr.Items() {
+			if item["id"] == float64(id) {
+				return http.StatusOK, item
+			}
+		}
+		return http.StatusNotFound, demoJSON{"message": "NotFound"}
+	}
+
+	switch path {
+	case "/system/status":
+		return http.StatusOK, demoJSON{"version": arr.Version}
+	case "/series", "/movie":
+		if method == "POST" {
+			return http.StatusCreated, demoJSON{"id": float64(len(arr.Items()) + 1)}
+		}
+		return http.StatusOK, arr.Items()
+	case "/series/lookup", "/movie/lookup":
+		term := strings.ToLower(get("term"))
+		results := []demoJSON{}
+		for _, item := range append(arr.Items(), arr.Lookup...) {
+			title, _ := item["title"].(string)
+			tvdb, _ := item["tvdbId"].(float64)
+			tmdb, _ := item["tmdbId"].(float64)
+			if strings.Contains(strings.ToLower(title), term) || term == fmt.Sprintf("tvdb:%d", int(tvdb)) || term == fmt.Sprintf("tmdb:%d", int(tmdb)) {
+				results = append(results, item)
+			}
+		}
+		return http.StatusOK, results
+	case "/episode":
+		return http.StatusOK, arr.Episodes(itemID)
+	case "/episodefile", "/moviefile":
+		return http.StatusOK, arr.Files(itemID)
+	case "/calendar":
+		start, _ := time.Parse(time.RFC3339, get("start"))
+		end, _ := time.Parse(time.RFC3339, get("end"))
+		calendar := []demoJSON{}
+		for _, s := range demoShows {
+			for _, e := range demoEpisodes(s.ID) {
+				aired, _ := time.Parse(time.RFC3339, e["airDateUtc"].(string))
+				if !aired.Before(start) && aired.Before(end) {
+					e["series"] = demoSeriesJSON(s)
+					calendar = append(calendar, e)
+				}
+			}
+		}
+		return http.StatusOK, calendar
+	case "/queue":
+		q := arr.Queue()
+		return http.StatusOK, demoJSON{"page": 1, "totalRecords": float64(len(q)), "records": q}
+	case "/release":
+		if method == "POST" {
+			return http.StatusOK, demoJSON{}
+		}
+		return http.StatusOK, arr.Releases()
+	case "/history":
+		records := []demoJSON{}
+		for _, h := range arr.History() {
+			if id := get("downloadId"); id == "" || strings.EqualFold(id, fmt.Sprint(h["downloadId"])) {
+				records = append(records, h)
+			}
+		}
+		return http.StatusOK, demoJSON{"page": 1, "totalRecords": len(records), "records": records}
+	case "/history/series", "/history/movie":
+		records := []demoJSON{}
+		for _, h := range arr.History() {
+			if id, _ := h["seriesId"].(float64); int(id) == itemID {
+				records = append(records, h)
+			} else if id, _ := h["movieId"].(float64); int(id) == itemID {
+				records = append(records, h)
+			}
+		}
+		return http.StatusOK, records
+	case "/history/since":
+		eventTypes := map[string]string{"1": "grabbed", "3": "downloadFolderImported", "4": "downloadFailed"}
+		records := []demoJSON{}
+		for _, h := range arr.History() {
+			if get("eventType") == "" || h["eventType"] == eventTypes[get("eventType")] {
+				records = append(records, h)
+			}
+		}
+		return http.StatusOK, records
+	case "/wanted/missing":
+		missing := arr.Missing()
+		return http.StatusOK, demoJSON{"page": 1, "totalRecords": len(missing), "records": missing}
+	case "/wanted/cutoff":
+		var below []demoJSON
+		for _, item := range arr.Items() {
+			if item["id"] == 2.0 || item["id"] == 4.0 {
+				below = append(below, item)
+			}
+		}
+		if arr.Version == demoSonarrData.Version {
+			below = []demoJSON{}
+			for _, e := range demoEpisodes(4) {
+				if e["seasonNumber"] == 1.0 {
+					below = append(below, e)
+				}
+			}
+		}
+		return http.StatusOK, demoJSON{"page": 1, "totalRecords": len(below), "records": below}
+	case "/blocklist":
+		return http.StatusOK, demoJSON{"page": 1, "totalRecords": 0, "records": []demoJSON{}}
+	case "/rootfolder":
+		return http.StatusOK, []demoJSON{{"id": 1.0, "path": arr.Root, "accessible": true, "freeSpace": 1.8 * 1024 * demoGB}}
+	case "/diskspace":
+		return http.StatusOK, []demoJSON{{"path": "/", "freeSpace": 40.0 * demoGB, "totalSpace": 120.0 * demoGB},
+			{"path": "/data", "freeSpace": 1.8 * 1024 * demoGB, "totalSpace": 8.0 * 1024 * demoGB}}
+	case "/config/mediamanagement":
+		return http.StatusOK, demoJSON{"minimumFreeSpaceWhenImporting": 100.0, "recycleBin": "/data/.recycle", "recycleBinCleanupDays": 7.0}
+	case "/filesystem":
+		if get("path") == "/data/.recycle" {
+			return http.StatusOK, demoJSON{"directories": []demoJSON{{"path": "/data/.recycle/The Bear", "name": "The Bear"}}, "files": []demoJSON{}}
+		}
+		return http.StatusOK, demoJSON{"directories": []demoJSON{}, "files": []demoJSON{
+			{"path": "/data/.recycle/The Bear/S01E03.mkv", "size": 1.2 * demoGB, "lastModified": demoAgo(20 * demoDay)},
+			{"path": "/data/.recycle/The Bear/S01E04.mkv", "size": 1.3 * demoGB, "lastModified": demoAgo(3 * demoDay)},
+		}}
+	case "/health":
+		if arr.Version == demoSonarrData.Version {
+			return http.StatusOK, []demoJSON{{"source": "IndexerStatusCheck", "type": "warning",
+				"message": "Indexers unavailable due to failures for more than 6 hours: NZBgeek"}}
+		}
+		return http.StatusOK, []demoJSON{}
+	case "/downloadclient":
+		category := "tv-sonarr"
+		if arr.Category == "movieCategory" {
+			category = "radarr"
+		}
+		return http.StatusOK, []demoJSON{{
+			"id": 1.0, "name": "qBittorrent", "implementation": "QBittorrent", "protocol": "torrent", "enable": true,
+			"fields": []interface{}{
+				demoJSON{"name": "host", "value": "qbittorrent"},
+				demoJSON{"name": arr.Category, "value": category},
+			},
+		}}
+	case "/remotepathmapping":
+		if method == "POST" {
+			return http.StatusCreated, demoJSON{"id": 2.0}
+		}
+		return http.StatusOK, []demoJSON{{"id": 1.0, "host": "qbittorrent", "remotePath": "/downloads/", "localPath": "/data/downloads/"}}
+	case "/tag":
+		if method == "POST" {
+			return http.StatusCreated, demoJSON{"id": 3.0}
+		}
+		return http.StatusOK, []demoJSON{{"id": 1.0, "label": "4k"}, {"id": 2.0, "label": "keep"}}
+	case "/qualityprofile":
+		return http.StatusOK, []demoJSON{{"id": 1.0, "name": "HD-1080p"}, {"id": 4.0, "name": "Ultra-HD"}}
+	case "/indexer":
+		indexer := func(id float64, name, protocol string) demoJSON {
+			return demoJSON{"id": id, "name": name, "protocol": protocol, "enableRss": true,
+				"enableAutomaticSearch": true, "enableInteractiveSearch": true, "fields": []interface{}{}}
+		}
+		return http.StatusOK, []demoJSON{indexer(1, "TorrentLeech", "torrent"), indexer(2, "NZBgeek", "usenet")}
+	case "/indexerstatus":
+		if arr.Version != demoSonarrData.Version {
+			return http.StatusOK, []demoJSON{}
+		}
+		return http.StatusOK, []demoJSON{{"indexerId": 2.0, "disabledTill": time.Now().Add(3 * time.Hour).UTC().Format(time.RFC3339)}}
+	case "/importlistexclusion", "/exclusions":
+		return http.StatusOK, []demoJSON{}
+	case "/command":
+		return http.StatusCreated, demoJSON{"id": 1001.0, "status": "queued"}
+	}
+	if method != "GET" {
+		return http.StatusOK, demoJSON{}
+	}
+	return http.StatusNotFound, demoJSON{"message": "Not available in demo mode"}
+}
+
+// ----------------------------------------------------------------------------
+// Jellyfin
+// ----------------------------------------------------------------------------
+
+func demoJellyfinResponse(path string) (int, interface{}) {
+	episode := func(series string, season, number int, name string, played float64) demoJSON {
+		return demoJSON{"Name": name, "SeriesName": series, "SeriesId": "s-" + series, "ParentIndexNumber": float64(season),
+			"IndexNumber": float64(number), "UserData": demoJSON{"PlayedPercentage": played, "LastPlayedDate": demoAgo(demoDay)}}
+	}
+	switch {
+	case path == "/System/Info":
+		return http.StatusOK, demoJSON{"Version": "10.10.3", "ServerName": "media"}
+	case path == "/Users":
+		return http.StatusOK, []demoJSON{{"Id": "u1", "Name": "Sam"}, {"Id": "u2", "Name": "Alex"}}
+	case path == "/Sessions":
+		return http.StatusOK, []demoJSON{{"UserName": "Alex", "NowPlayingItem": demoJSON{"Name": "Oppenheimer"},
+			"TranscodingInfo": demoJSON{"IsVideoDirect": false, "VideoCodec": "h264"}}}
+	case path == "/Shows/NextUp":
+		return http.StatusOK, demoJSON{"Items": []demoJSON{episode("Severance", 2, 9, "The After Hours", 0)}}
+	case strings.HasSuffix(path, "/Items/Resume"):
+		return http.StatusOK, demoJSON{"Items": []demoJSON{
+			episode("Severance", 2, 8, "Sweet Vitriol", 42),
+			{"Name": "Past Lives", "ProductionYear": 2023.0, "UserData": demoJSON{"PlayedPercentage": 63.0}},
+		}}
+	case strings.HasSuffix(path, "/Items"):
+		var items []demoJSON
+		for _, f := range demoFilms {
+			item := demoJSON{"Name": f.Title, "Type": "Movie", "ProviderIds": demoJSON{"Tmdb": fmt.Sprint(f.TmdbID)}, "UserData": demoJSON{}}
+			if f.ID == 2 || f.ID == 3 {
+				item["UserData"] = demoJSON{"LastPlayedDate": demoAgo(time.Duration(f.ID*20) * demoDay)}
+			}
+			items = append(items, item)
+		}
+		for _, s := range demoShows {
+			items = append(items, demoJSON{"Name": s.Title, "Id": "s-" + s.Title, "Type": "Series", "ProviderIds": demoJSON{"Tvdb": fmt.Sprint(s.TvdbID)}})
+		}
+		items = append(items, episode("Severance", 2, 7, "Chikhai Bardo", 100), episode("The Bear", 3, 10, "Forever", 100), episode("Breaking Bad", 5, 16, "Felina", 100))
+		return http.StatusOK, demoJSON{"Items": items}
+	}
+	return http.StatusNotFound, demoJSON{}
+}
+
+// ----------------------------------------------------------------------------
+// Download clients
+// ----------------------------------------------------------------------------
+
+func demoQbittorrentResponse(path string, query map[string][]string) (int, interface{}) {
+	torrent := func(hash, name, category, tracker, state string, size float64) demoJSON {
+		return demoJSON{"hash": hash, "name": name, "category": category, "tracker": tracker, "state": state, "size": size, "progress": 1.0}
+	}
+	switch path {
+	case "/auth/login":
+		return http.StatusOK, demoText("Ok.")
+	case "/app/version":
+		return http.StatusOK, demoText("v5.0.1")
+	case "/sync/maindata":
+		return http.StatusOK, demoJSON{"server_state": demoJSON{
+			"alltime_dl": 18.4 * 1024 * demoGB, "alltime_ul": 31.2 * 1024 * demoGB,
+			"dl_info_speed": 42.5 * 1024 * 1024, "up_info_speed": 6.1 * 1024 * 1024,
+		}}
+	case "/torrents/info":
+		return http.StatusOK, []demoJSON{
+			torrent("a1b2c3d4e5f60718293a4b5c6d7e8f9012345678", "Shogun.2024.S01.1080p.DSNP.WEB-DL.DDP5.1.H.264-NTb", "tv-sonarr", "https://tracker.torrentleech.org/a/passkey/announce", "stalledUP", 16*demoGB),
+			torrent("b2c3d4e5f60718293a4b5c6d7e8f9012345678a1", "Oppenheimer.2023.1080p.BluRay.x264-SPRiNTER", "radarr", "https://tracker.torrentleech.org/a/passkey/announce", "uploading", 14*demoGB),
+			torrent("c3d4e5f60718293a4b5c6d7e8f9012345678a1b2", "Oppenheimer.2023.1080p.BluRay.x264-SPRiNTER", "radarr", "https://announce.example-tracker.net/passkey/announce", "stalledUP", 14*demoGB),
+			torrent("d4e5f60718293a4b5c6d7e8f9012345678a1b2c3", "Breaking.Bad.S01.1080p.BluRay.x264-ROVERS", "tv-sonarr", "", "stalledUP", 9*demoGB),
+		}
+	case "/torrents/trackers":
+		if v := query["hash"]; len(v) > 0 && strings.HasPrefix(v[0], "d4e5") {
+			return http.StatusOK, []demoJSON{
+				{"url": "** [DHT] **", "status": 0.0, "msg": ""},
+				{"url": "https://tracker.torrentleech.org/a/passkey/announce", "status": 4.0, "msg": "Unregistered torrent"},
+			}
+		}
+		return http.StatusOK, []demoJSON{}
+	}
+	return http.StatusOK, ""
+}
+
+func demoSabnzbdResponse(mode string) (int, interface{}) {
+	switch mode {
+	case "server_stats":
+		return http.StatusOK, demoJSON{"day": 12.5 * demoGB, "week": 96.2 * demoGB, "month": 402.0 * demoGB, "total": 6.3 * 1024 * demoGB}
+	case "queue":
+		return http.StatusOK, demoJSON{"queue": demoJSON{"kbpersec": "0.00", "slots": []interface{}{}}}
+	case "version":
+		return http.StatusOK, demoJSON{"version": "4.3.3"}
+	}
+	return http.StatusOK, demoJSON{"status": true}
+}
@@ -0,0 +1,616 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ============================================================================
+// Diagnostics
+// ============================================================================
+
+func registerDiagnosticTools(s *server.MCPServer) {
+	// Stack Status
+	s.AddTool(
+		mcp.NewTool("ultimarr_status",
+			mcp.WithDescription("One-shot dashboard for the whole stack: services up/down with versions, download queue counts and speed, pending requests, free disk space per root folder, and health warnings. Use for \"how's the server doing?\""),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[stackStatus](),
+		),
+		handleUltimarrStatus,
+	)
+
+	// Export Config, an admin tool when the server is shared, since the
+	// profile shows how everything is set up
+	if len(config.Users) == 0 || config.AdminTools {
+		s.AddTool(
+			mcp.NewTool("ultimarr_export_config",
+				mcp.WithDescription("Export the effective ultimarr configuration as a profile of environment variables, without API keys, passwords, or user tokens. Use to copy a tuned setup (defaults, retention, quotas, quiet hours, ratings) to another machine, which loads it with ULTIMARR_PROFILE."),
+				mcp.WithReadOnlyHintAnnotation(true),
+				mcp.WithOutputSchema[configProfile](),
+			),
+			handleUltimarrExportConfig,
+		)
+	}
+
+	// Verify Download Clients
+	if len(arrServices()) > 0 {
+		s.AddTool(
+			mcp.NewTool("ultimarr_verify_download_clients",
+				mcp.WithDescription("Verify each Sonarr/Radarr download client: connection test, category set and valid, completed downloads importable from where the *arr expects them, and root folders accessible. Flags category typos, path mismatches, and permission problems."),
+				mcp.WithReadOnlyHintAnnotation(true),
+				mcp.WithOutputSchema[clientVerification](),
+			),
+			handleVerifyDownloadClients,
+		)
+	}
+
+	// In Flight
+	if isConfigured(jellyseerr) || len(arrServices()) > 0 {
+		s.AddTool(
+			mcp.NewTool("ultimarr_in_flight",
+				mcp.WithDescription("Everything in flight, grouped by title, however it was requested: pending and approved Jellyseerr requests, Sonarr/Radarr items added but still missing, downloads in the queue, and recent imports. Use for \"what's pending?\""),
+				mcp.WithNumber("hours", mcp.Description("How far back to include imports (default 24)")),
+				mcp.WithReadOnlyHintAnnotation(true),
+				mcp.WithOutputSchema[inFlight](),
+			),
+			handleUltimarrInFlight,
+		)
+	}
+
+	// Availability Matrix
+	if isConfigured(jellyseerr) {
+		s.AddTool(
+			mcp.NewTool("ultimarr_availability",
+				mcp.WithDescription("Check up to 50 titles at once and get a compact matrix of which are available, partially available, downloading, requested, or absent. Use for \"which of these Oscar nominees do we have?\""),
+				mcp.WithArray("titles", mcp.Required(), mcp.WithStringItems(), mcp.Description("Titles to check; add a year to disambiguate, e.g. 'Dune (2021)'")),
+				mcp.WithString("media_type", mcp.Enum("movie", "tv"), mcp.Description("Only match movies or only TV shows (default both)")),
+				mcp.WithReadOnlyHintAnnotation(true),
+				mcp.WithOutputSchema[availabilityMatrix](),
+			),
+			handleUltimarrAvailability,
+		)
+	}
+
+	// Indexer Tests
+	if len(arrServices()) > 0 {
+		s.AddTool(
+			mcp.NewTool("ultimarr_test_indexers",
+				mcp.WithDescription("Test each enabled Sonarr/Radarr indexer individually: response time and errors (expired API key or cookie, rate limited, unreachable, disabled after failures). With series_id or movie_id, also runs that search and counts results per indexer. Use when interactive searches come back empty."),
+				mcp.WithString("service", mcp.Enum("sonarr", "radarr"), mcp.Description("Only test this service's indexers (default both)")),
+				mcp.WithNumber("series_id", mcp.Description("Sonarr series ID to search and count results per indexer")),
+				mcp.WithNumber("movie_id", mcp.Description("Radarr movie ID to search and count results per indexer")),
+				mcp.WithOutputSchema[indexerReport](),
+			),
+			withQuietHours(searchInteractive, handleUltimarrTestIndexers),
+		)
+	}
+
+	// Indexer Grab Report
+	if len(arrServices()) > 0 {
+		s.AddTool(
+			mcp.NewTool("ultimarr_indexer_report",
+				mcp.WithDescription("Report grabs per Sonarr/Radarr indexer over the last days: how many were imported, failed, or are still pending, the failure rate, and the typical quality and size grabbed. Configured indexers without grabs are listed too. Use when deciding which indexers are worth keeping."),
+				mcp.WithString("service", mcp.Enum("sonarr", "radarr"), mcp.Description("Only this service's grabs (default both)")),
+				mcp.WithNumber("days", mcp.Description("How many days of history to report on (default 30)")),
+				mcp.WithReadOnlyHintAnnotation(true),
+				mcp.WithOutputSchema[indexerGrabReport](),
+			),
+			handleUltimarrIndexerReport,
+		)
+	}
+
+	// Grab Log
+	if len(arrServices()) > 0 {
+		s.AddTool(
+			mcp.NewTool("ultimarr_grab_log",
+				mcp.WithDescription("List releases grabbed with sonarr_download_release/radarr_download_release and why each was chosen (user choice, best match, upgrade campaign) with any comment. Use when reviewing an odd grab."),
+				mcp.WithString("service", mcp.Enum("sonarr", "radarr"), mcp.Description("Only this service")),
+				mcp.WithNumber("series_id", mcp.Description("Only grabs for this Sonarr series")),
+				mcp.WithNumber("movie_id", mcp.Description("Only grabs for this Radarr movie")),
+				mcp.WithString("reason", mcp.Enum(grabReasons...), mcp.Description("Only grabs with this reason")),
+				mcp.WithNumber("limit", mcp.Description("Maximum grabs to list, newest first (default 20)")),
+				mcp.WithReadOnlyHintAnnotation(true),
+				mcp.WithOutputSchema[grabLogResult](),
+			),
+			handleUltimarrGrabLog,
+		)
+	}
+
+	// Diagnose Connection
+	var targets []string
+	for _, t := range connectionTargets() {
+		targets = append(targets, strings.ToLower(t.Name))
+	}
+	if len(targets) > 0 {
+		s.AddTool(
+			mcp.NewTool("ultimarr_diagnose_connection",
+				mcp.WithDescription("Find out why a service can't be reached: checks DNS resolution, TCP connection, TLS certificate, HTTP response, credentials, and the API version endpoint one at a time and reports which layer fails, with what to fix. Use when a tool returns a connection or HTTP error."),
+				mcp.WithString("service", mcp.Required(), mcp.Enum(targets...), mcp.Description("Service to diagnose")),
+				mcp.WithReadOnlyHintAnnotation(true),
+				mcp.WithOutputSchema[connectionDiagnosis](),
+			),
+			handleUltimarrDiagnoseConnection,
+		)
+	}
+
+	// Space Planner
+	if len(arrServices()) > 0 {
+		s.AddTool(
+			mcp.NewTool("ultimarr_space_plan",
+				mcp.WithDescription("Estimate how much space the monitored but missing episodes and movies will take once Sonarr/Radarr grab them, and compare it with the free space on each disk. Sizes come from the library's own files for the same series or quality profile. Use for \"will the disk survive if everything I'm monitoring downloads?\""),
+				mcp.WithString("service", mcp.Enum("sonarr", "radarr"), mcp.Description("Only plan for this service (default both)")),
+				mcp.WithReadOnlyHintAnnotation(true),
+				mcp.WithOutputSchema[spacePlan](),
+			),
+			handleUltimarrSpacePlan,
+		)
+	}
+
+	// Status Reconciliation
+	if isConfigured(jellyseerr) && len(arrServices()) > 0 {
+		s.AddTool(
+			mcp.NewTool("ultimarr_reconcile",
+				mcp.WithDescription("Find titles whose Jellyseerr status disagrees with Sonarr/Radarr: marked available but the files are gone or the title was removed, or still processing although everything was imported or the title never reached the *arr. Lists the fix for each; with confirm=true, corrects the Jellyseerr status or retries the stuck request. Use when a user says something shows as available but won't play, or a finished download still shows as requested."),
+				mcp.WithString("media_type", mcp.Enum("movie", "tv"), mcp.Description("Only check movies or only TV shows (default both)")),
+				mcp.WithBoolean("confirm", mcp.Description("Must be true to apply the fixes")),
+				mcp.WithOutputSchema[reconcileReport](),
+			),
+			handleUltimarrReconcile,
+		)
+	}
+
+	// Duplicate Cleanup
+	if len(arrServices()) > 0 {
+		s.AddTool(
+			mcp.NewTool("ultimarr_duplicate_cleanup",
+				mcp.WithDescription("Find duplicate files in Sonarr/Radarr (episode files no episode uses, extra files beside a movie's own) and files left in the recycle bin past its cleanup window, with the space they use. Dry run by default; pass the returned confirm_token to delete the duplicates and empty the expired recycle bin files."),
+				mcp.WithString("service", mcp.Enum("sonarr", "radarr"), mcp.Description("Only check this service (default both)")),
+				mcp.WithString("confirm_token", mcp.Description("Token from a previous dry run; pass it to actually delete what it listed")),
+				mcp.WithDestructiveHintAnnotation(true),
+				mcp.WithOutputSchema[duplicateReport](),
+			),
+			handleUltimarrDuplicateCleanup,
+		)
+	}
+
+	// Retention Report
+	if len(config.Retention) > 0 {
+		s.AddTool(
+			mcp.NewTool("ultimarr_retention_report",
+				mcp.WithDescription("Evaluate the library against the retention policies in ULTIMARR_RETENTION and list the candidates and reclaimable space for each. Reports only; nothing is deleted."),
+				mcp.WithString("policy", mcp.Enum(retentionPolicyNames()...), mcp.Description("Only evaluate this policy (default all)")),
+				mcp.WithReadOnlyHintAnnotation(true),
+				mcp.WithOutputSchema[retentionReport](),
+			),
+			handleUltimarrRetentionReport,
+		)
+	}
+}
+
+// serviceStatus is everything ultimarr_status gathers from one service.
+type serviceStatus struct {
+	Name     string
+	Version  string
+	Err      error
+	Queue    int
+	HasQueue bool    // Queue was read, so it is shown under Downloads
+	Speed    float64 // bytes per second
+	Roots    map[string]int64
+	Health   []string
+	Requests map[string]int
+}
+
+// stackStatus is the structured result of ultimarr_status.
+type stackStatus struct {
+	Services []serviceState  `json:"services"`
+	Requests map[string]int  `json:"requests,omitempty"` // Jellyseerr request counts by status
+	Speed    int64           `json:"speed"`              // estimated download speed, bytes per second
+	Disk     []diskSpace     `json:"disk"`
+	Health   []healthWarning `json:"health"`
+}
+
+type serviceState struct {
+	Name    string `json:"name"`
+	Up      bool   `json:"up"`
+	Version string `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Queue   int    `json:"queue"`
+}
+
+type diskSpace struct {
+	Path string `json:"path"`
+	Free int64  `json:"free"`
+}
+
+type healthWarning struct {
+	Service string `json:"service"`
+	Message string `json:"message"`
+}
+
+func handleUltimarrStatus(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var statuses []*serviceStatus
+	var wg sync.WaitGroup
+
+	for _, svc := range configured {
+		st := &serviceStatus{Name: svc.Name()}
+		statuses = append(statuses, st)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if r, ok := svc.(statusReporter); ok {
+				r.Status(ctx, st)
+			} else {
+				st.Version, st.Err = svc.HealthCheck(ctx)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(statuses) == 0 {
+		return mcp.NewToolResultError("No services are configured"), nil
+	}
+
+	result := stackStatus{Services: []serviceState{}, Disk: []diskSpace{}, Health: []healthWarning{}}
+	var lines []string
+	lines = append(lines, "Services:")
+	for _, st := range statuses {
+		state := serviceState{Name: st.Name, Up: st.Err == nil, Version: st.Version, Queue: st.Queue}
+		if st.Err != nil {
+			lines = append(lines, fmt.Sprintf("  %s: DOWN - %v", st.Name, st.Err))
+			state.Error = st.Err.Error()
+		} else {
+			lines = append(lines, fmt.Sprintf("  %s: up (v%s)", st.Name, strings.TrimPrefix(st.Version, "v")))
+		}
+		result.Services = append(result.Services, state)
+	}
+
+	var queues []string
+	var speed float64
+	roots := map[string]int64{}
+	var rootOrder []string
+	var health []string
+	for _, st := range statuses {
+		if st.Err != nil {
+			continue
+		}
+		if st.HasQueue {
+			queues = append(queues, fmt.Sprintf("%d in %s", st.Queue, st.Name))
+		}
+		speed += st.Speed
+		for path, free := range st.Roots {
+			if _, seen := roots[path]; !seen {
+				rootOrder = append(rootOrder, path)
+			}
+			roots[path] = free
+		}
+		for _, h := range st.Health {
+			health = append(health, fmt.Sprintf("  %s: %s", st.Name, h))
+			result.Health = append(result.Health, healthWarning{Service: st.Name, Message: h})
+		}
+		if st.Requests != nil {
+			result.Requests = st.Requests
+			lines = append(lines, "", fmt.Sprintf("Requests: %d pending approval, %d processing, %d available",
+				st.Requests["pending"], st.Requests["processing"], st.Requests["available"]))
+		}
+	}
+
+	if len(queues) > 0 {
+		lines = append(lines, "", fmt.Sprintf("Downloads: %s, ~%s/s total", strings.Join(queues, ", "), formatBytes(int64(speed))))
+	}
+
+	if len(rootOrder) > 0 {
+		sort.Strings(rootOrder)
+		lines = append(lines, "", "Disk space:")
+		for _, path := range rootOrder {
+			lines = append(lines, fmt.Sprintf("  %s: %s free", path, formatBytes(roots[path])))
+			result.Disk = append(result.Disk, diskSpace{Path: path, Free: roots[path]})
+		}
+	}
+	result.Speed = int64(speed)
+
+	lines = append(lines, "", fmt.Sprintf("Health (%d):", len(health)))
+	if len(health) == 0 {
+		lines = append(lines, "  (no warnings)")
+	}
+	lines = append(lines, health...)
+
+	return mcp.NewToolResultStructured(result, strings.Join(lines, "\n")), nil
+}
+
+func jellyseerrStatus(ctx context.Context, st *serviceStatus) {
+	data, err := jellyseerrRequest(ctx, "GET", "/status", nil)
+	if err != nil {
+		st.Err = err
+		return
+	}
+	var status map[string]interface{}
+	json.Unmarshal(data, &status)
+	st.Version, _ = status["version"].(string)
+
+	if data, err := jellyseerrRequest(ctx, "GET", "/request/count", nil); err == nil {
+		var counts map[string]float64
+		json.Unmarshal(data, &counts)
+		st.Requests = map[string]int{}
+		for k, v := range counts {
+			st.Requests[k] = int(v)
+		}
+	}
+}
+
+func arrStatus(ctx context.Context, svc arrService, st *serviceStatus) {
+	data, err := svc.Request(ctx, "GET", "/system/status", nil)
+	if err != nil {
+		st.Err = err
+		return
+	}
+	var status map[string]interface{}
+	json.Unmarshal(data, &status)
+	st.Version, _ = status["version"].(string)
+	st.HasQueue = true
+
+	if data, err := svc.Request(ctx, "GET", "/queue?pageSize=200", nil); err == nil {
+		var result map[string]interface{}
+		json.Unmarshal(data, &result)
+		if total, ok := result["totalRecords"].(float64); ok {
+			st.Queue = int(total)
+		}
+		records, _ := result["records"].([]interface{})
+		for _, r := range records {
+			item := r.(map[string]interface{})
+			if status, _ := item["status"].(string); status != "downloading" {
+				continue
+			}
+			sizeleft, _ := item["sizeleft"].(float64)
+			tl, _ := item["timeleft"].(string)
+			if d, ok := parseTimeLeft(tl); ok && d > 0 {
+				st.Speed += sizeleft / d.Seconds()
+			}
+		}
+	}
+
+	if data, err := svc.Request(ctx, "GET", "/rootfolder", nil); err == nil {
+		var folders []map[string]interface{}
+		json.Unmarshal(data, &folders)
+		st.Roots = map[string]int64{}
+		for _, f := range folders {
+			path, _ := f["path"].(string)
+			free, _ := f["freeSpace"].(float64)
+			st.Roots[path] = int64(free)
+		}
+	}
+
+	if data, err := svc.Request(ctx, "GET", "/health", nil); err == nil {
+		var health []map[string]interface{}
+		json.Unmarshal(data, &health)
+		for _, h := range health {
+			kind, _ := h["type"].(string)
+			msg, _ := h["message"].(string)
+			st.Health = append(st.Health, fmt.Sprintf("[%s] %s", kind, msg))
+		}
+	}
+}
+
+// clientVerification is the structured result of
+// ultimarr_verify_download_clients.
+type clientVerification struct {
+	Services []clientCheck `json:"services"`
+	Problems int           `json:"problems"`
+}
+
+// clientCheck lists the problems found for one *arr service, each prefixed
+// with what it concerns (a client, root folder, or queued download).
+type clientCheck struct {
+	Service  string   `json:"service"`
+	Problems []string `json:"problems"`
+}
+
+func handleVerifyDownloadClients(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	services := arrServices()
+	if len(services) == 0 {
+		return mcp.NewToolResultError("Neither Sonarr nor Radarr is configured"), nil
+	}
+
+	result := clientVerification{Services: []clientCheck{}}
+	var lines []string
+	pathMismatch := false
+	for _, svc := range services {
+		svcLines, svcProblems := verifyDownloadClients(ctx, svc)
+		lines = append(lines, fmt.Sprintf("%s:", svc.Name))
+		lines = append(lines, svcLines...)
+		lines = append(lines, "")
+		result.Services = append(result.Services, clientCheck{Service: svc.Name, Problems: svcProblems})
+		result.Problems += len(svcProblems)
+		for _, p := range svcProblems {
+			if strings.Contains(p, ": path mismatch: ") {
+				pathMismatch = true
+			}
+		}
+	}
+
+	if result.Problems == 0 {
+		lines = append(lines, "No problems found.")
+	} else {
+		lines = append(lines, fmt.Sprintf("%d problem(s) found.", result.Problems))
+	}
+	if pathMismatch {
+		lines = append(lines, "Path mismatches usually mean the download client and the *arr mount the downloads folder at different paths. ultimarr_path_mappings shows the client's path next to the existing mappings; fix it with ultimarr_add_path_mapping.")
+	}
+
+	return mcp.NewToolResultStructured(result, strings.Join(lines, "\n")), nil
+}
+
+// verifyDownloadClients checks one service's download clients, returning the
+// report lines and the problems found.
+func verifyDownloadClients(ctx context.Context, svc arrService) ([]string, []string) {
+	var lines []string
+	problems := []string{}
+	problem := func(subject, format string, a ...interface{}) {
+		msg := fmt.Sprintf(format, a...)
+		lines = append(lines, "    PROBLEM: "+msg)
+		problems = append(problems, subject+": "+msg)
+	}
+
+	data, err := svc.Request(ctx, "GET", "/downloadclient", nil)
+	if err != nil {
+		return []string{"  PROBLEM: " + err.Error()}, []string{err.Error()}
+	}
+	var clients []map[string]interface{}
+	json.Unmarshal(data, &clients)
+
+	if len(clients) == 0 {
+		return []string{"  PROBLEM: no download clients configured"}, []string{"no download clients configured"}
+	}
+
+	for _, c := range clients {
+		name, _ := c["name"].(string)
+		protocol, _ := c["protocol"].(string)
+		enabled, _ := c["enable"].(bool)
+
+		category := ""
+		if v, ok := providerField(c, svc.Category); ok {
+			category, _ = v.(string)
+		}
+
+		state := "enabled"
+		if !enabled {
+			state = "disabled"
+		}
+		lines = append(lines, fmt.Sprintf("  %s (%s, %s): category %q", name, protocol, state, category))
+		if !enabled {
+			continue
+		}
+
+		if category == "" {
+			problem(name, "no category set, so %s can't tell its downloads apart from other apps'", svc.Name)
+		}
+
+		// The test endpoint validates connectivity and, for most clients, that the category exists
+		body, _ := json.Marshal(c)
+		if _, err := svc.Request(ctx, "POST", "/downloadclient/test", strings.NewReader(string(body))); err != nil {
+			problem(name, "connection test failed: %s", describeValidationError(err))
+		} else {
+			lines = append(lines, "    OK: connection test passed")
+		}
+	}
+
+	// Health checks cover client/root folder mismatches detected by the *arr itself
+	if data, err := svc.Request(ctx, "GET", "/health", nil); err == nil {
+		var health []map[string]interface{}
+		json.Unmarshal(data, &health)
+		for _, h := range health {
+			source, _ := h["source"].(string)
+			if !strings.Contains(source, "DownloadClient") && !strings.Contains(source, "ImportMechanism") && !strings.Contains(source, "RemotePathMapping") {
+				continue
+			}
+			msg, _ := h["message"].(string)
+			lines = append(lines, fmt.Sprintf("  Health (%s):", source))
+			problem("Health ("+source+")", "%s", msg)
+		}
+	}
+
+	if data, err := svc.Request(ctx, "GET", "/rootfolder", nil); err == nil {
+		var folders []map[string]interface{}
+		json.Unmarshal(data, &folders)
+		for _, f := range folders {
+			path, _ := f["path"].(string)
+			if accessible, ok := f["accessible"].(bool); ok && !accessible {
+				lines = append(lines, fmt.Sprintf("  Root folder %s:", path))
+				problem("Root folder "+path, "not accessible to %s (missing mount or permissions)", svc.Name)
+			}
+		}
+	}
+
+	// Completed downloads stuck before import show where paths or permissions don't line up
+	if data, err := svc.Request(ctx, "GET", "/queue?pageSize=200", nil); err == nil {
+		var result map[string]interface{}
+		json.Unmarshal(data, &result)
+		records, _ := result["records"].([]interface{})
+		for _, r := range records {
+			item := r.(map[string]interface{})
+			state, _ := item["trackedDownloadState"].(string)
+			if state != "importPending" && state != "importBlocked" && state != "importFailed" {
+				continue
+			}
+			trackedStatus, _ := item["trackedDownloadStatus"].(string)
+			if trackedStatus == "ok" {
+				continue
+			}
+
+			title, _ := item["title"].(string)
+			outputPath, _ := item["outputPath"].(string)
+			messages := queueStatusMessages(item)
+
+			lines = append(lines, fmt.Sprintf("  Completed download %s (in %s):", title, outputPath))
+			problem("Completed download "+title, "%s: %s", importProblemKind(messages), strings.Join(messages, "; "))
+		}
+	}
+
+	return lines, problems
+}
+
+// importProblemKind classifies why a completed download can't be imported
+// from its queue status messages.
+func importProblemKind(messages []string) string {
+	joined := strings.ToLower(strings.Join(messages, " "))
+	switch {
+	case strings.Contains(joined, "denied") || strings.Contains(joined, "permission"):
+		return "permission problem"
+	case strings.Contains(joined, "does not exist") || strings.Contains(joined, "not found") || strings.Contains(joined, "remote path"):
+		return "path mismatch"
+	}
+	return "import blocked"
+}
+
+// queueStatusMessages flattens a queue record's statusMessages.
+func queueStatusMessages(item map[string]interface{}) []string {
+	var messages []string
+	statusMessages, _ := item["statusMessages"].([]interface{})
+	for _, sm := range statusMessages {
+		m, ok := sm.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		msgs, _ := m["messages"].([]interface{})
+		for _, msg := range msgs {
+			if str, ok := msg.(string); ok {
+				messages = append(messages, str)
+			}
+		}
+	}
+	if len(messages) == 0 {
+		if em, ok := item["errorMessage"].(string); ok && em != "" {
+			messages = append(messages, em)
+		}
+	}
+	return messages
+}
+
+// describeValidationError extracts the messages from an *arr validation
+// failure response, falling back to the raw error.
+func describeValidationError(err error) string {
+	httpErr, ok := err.(*HTTPError)
+	if !ok {
+		return err.Error()
+	}
+
+	var failures []map[string]interface{}
+	if json.Unmarshal([]byte(httpErr.Body), &failures) != nil || len(failures) == 0 {
+		return err.Error()
+	}
+
+	var messages []string
+	for _, f := range failures {
+		if msg, ok := f["errorMessage"].(string); ok {
+			messages = append(messages, msg)
+		}
+	}
+	return strings.Join(messages, "; ")
+}
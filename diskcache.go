@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Disk Cache
+// ============================================================================
+
+// With ULTIMARR_DISK_CACHE, metadata that is slow to gather on a NAS survives
+// restarts in the data directory's cache folder: the Sonarr/Radarr library
+// listings, the TMDB title cache, and how far completion notifications have
+// read the import history. A library snapshot is answered from while it's
+// under ULTIMARR_CACHE_MAX_AGE old, and each time it is, the library is
+// fetched again in the background so changes made elsewhere show up on the
+// next call. It's dropped as soon as ultimarr changes anything in that
+// service.
+
+// Import checkpoints older than this are ignored on startup, so a long
+// downtime doesn't flood clients with old imports
+const maxCheckpointAge = 24 * time.Hour
+
+// How often new titles are written to disk
+const titleSaveInterval = time.Minute
+
+// libraryEndpoints are the requests that return a service's whole library.
+var libraryEndpoints = map[string]string{
+	"Sonarr": "/series",
+	"Radarr": "/movie",
+}
+
+// librarySnapshot is a cached response to a library endpoint. URL is the
+// service's base URL, so a snapshot isn't reused for a different instance.
+type librarySnapshot struct {
+	URL  string          `json:"url"`
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// cachedTitleEntry is a title cache entry as stored on disk.
+type cachedTitleEntry struct {
+	MediaType string `json:"mediaType"`
+	TmdbID    int    `json:"tmdbId"`
+	Title     string `json:"title"`
+}
+
+var (
+	libraryMu         sync.Mutex
+	libraryCache      = map[string]*librarySnapshot{}
+	libraryLoaded     = map[string]bool{}
+	libraryRefreshing = map[string]bool{}
+	libraryChanges    = map[string]int{} // bumped whenever a snapshot is dropped
+)
+
+func cachePath(name string) string {
+	return filepath.Join(config.DataDir, "cache", name)
+}
+
+func readCacheFile(name string, v interface{}) bool {
+	data, err := os.ReadFile(cachePath(name))
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+func writeCacheFile(name string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(cachePath(name)), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp := cachePath(name) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, cachePath(name))
+}
+
+func libraryFile(service string) string {
+	return "library-" + service + ".json"
+}
+
+// cachedLibrary returns the snapshot of a service's library if the request
+// is for the whole library and the snapshot is recent enough, and starts
+// fetching a fresh one with fetch.
+func cachedLibrary(service, baseURL, method, endpoint string, fetch func(ctx context.Context) ([]byte, error)) ([]byte, bool) {
+	if !config.DiskCache || method != "GET" || endpoint != libraryEndpoints[service] {
+		return nil, false
+	}
+	libraryMu.Lock()
+	defer libraryMu.Unlock()
+	if !libraryLoaded[service] {
+		libraryLoaded[service] = true
+		var snap librarySnapshot
+		if readCacheFile(libraryFile(service), &snap) {
+			libraryCache[service] = &snap
+		}
+	}
+	snap := libraryCache[service]
+	if snap == nil || snap.URL != baseURL || time.Since(snap.Time) > config.CacheMaxAge {
+		return nil, false
+	}
+	if !libraryRefreshing[service] {
+		libraryRefreshing[service] = true
+		go refreshLibrary(service, baseURL, endpoint, fetch, libraryChanges[service])
+	}
+	return snap.Data, true
+}
+
+// refreshLibrary fetches a service's library and stores it, unless ultimarr
+// changed something in the service meanwhile.
+func refreshLibrary(service, baseURL, endpoint string, fetch func(ctx context.Context) ([]byte, error), changes int) {
+	// The refresh outlives the tool call that started it
+	data, err := fetch(context.Background())
+	libraryMu.Lock()
+	libraryRefreshing[service] = false
+	current := libraryChanges[service] == changes
+	libraryMu.Unlock()
+	if err != nil {
+		log.Printf("Disk cache: refreshing %s library: %v", service, err)
+		return
+	}
+	if current {
+		updateLibraryCache(service, baseURL, "GET", endpoint, data)
+	}
+}
+
+// updateLibraryCache stores a fresh library listing, or drops the snapshot
+// after any request that may have changed the library.
+func updateLibraryCache(service, baseURL, method, endpoint string, data []byte) {
+	if !config.DiskCache {
+		return
+	}
+	var snap *librarySnapshot
+	switch {
+	case method == "GET" && endpoint == libraryEndpoints[service]:
+		snap = &librarySnapshot{URL: baseURL, Time: time.Now(), Data: data}
+	case method == "GET":
+		return
+	}
+
+	libraryMu.Lock()
+	defer libraryMu.Unlock()
+	libraryLoaded[service] = true
+	if snap == nil {
+		libraryChanges[service]++
+		if libraryCache[service] == nil {
+			return
+		}
+	}
+	libraryCache[service] = snap
+	var err error
+	if snap == nil {
+		err = os.Remove(cachePath(libraryFile(service)))
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	} else {
+		err = writeCacheFile(libraryFile(service), snap)
+	}
+	if err != nil {
+		log.Printf("Disk cache: %v", err)
+	}
+}
+
+// loadDiskCache restores the title cache and starts writing it back every
+// titleSaveInterval. saveDiskCache writes it on shutdown.
+func loadDiskCache() {
+	var titles []cachedTitleEntry
+	if readCacheFile("titles.json", &titles) {
+		for _, t := range titles {
+			rememberTitle(t.MediaType, t.TmdbID, t.Title)
+		}
+		titleMu.Lock()
+		titlesDirty = false
+		titleMu.Unlock()
+		log.Printf("Disk cache: loaded %d titles", len(titles))
+	}
+	go func() {
+		for range time.Tick(titleSaveInterval) {
+			saveTitleCache()
+		}
+	}()
+}
+
+// saveDiskCache writes what's still only in memory before the server exits.
+func saveDiskCache() {
+	if config.DiskCache {
+		saveTitleCache()
+	}
+}
+
+// saveTitleCache writes the title cache if it changed since the last save.
+func saveTitleCache() {
+	titleMu.Lock()
+	if !titlesDirty {
+		titleMu.Unlock()
+		return
+	}
+	titles := make([]cachedTitleEntry, 0, len(titleCache))
+	for k, title := range titleCache {
+		titles = append(titles, cachedTitleEntry{MediaType: k.MediaType, TmdbID: k.TmdbID, Title: title})
+	}
+	titlesDirty = false
+	titleMu.Unlock()
+
+	if err := writeCacheFile("titles.json", titles); err != nil {
+		log.Printf("Disk cache: %v", err)
+	}
+}
+
+// loadImportCheckpoints returns where completion notifications left off
+// reading each service's import history, skipping checkpoints that are too
+// old to be worth catching up on.
+func loadImportCheckpoints() map[string]time.Time {
+	checkpoints := map[string]time.Time{}
+	if !config.DiskCache {
+		return checkpoints
+	}
+	var saved map[string]time.Time
+	readCacheFile("checkpoints.json", &saved)
+	for name, t := range saved {
+		if time.Since(t) < maxCheckpointAge {
+			checkpoints[name] = t
+		}
+	}
+	return checkpoints
+}
+
+// saveImportCheckpoints records how far each service's import history has
+// been read. Callers hold importsMu.
+func saveImportCheckpoints() {
+	if !config.DiskCache {
+		return
+	}
+	if err := writeCacheFile("checkpoints.json", importsSince); err != nil {
+		log.Printf("Disk cache: %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ============================================================================
+// qBittorrent
+// ============================================================================
+
+var (
+	qbittorrentMu  sync.Mutex
+	qbittorrentSID string
+)
+
+// qbittorrentLogin signs in to the Web UI and stores the session cookie.
+func qbittorrentLogin(ctx context.Context) error {
+	form := url.Values{
+		"username": {qbittorrent.username},
+		"password": {qbittorrent.password},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", qbittorrent.baseURL+"/api/v2/auth/login", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// qBittorrent's CSRF protection rejects logins without a matching Referer
+	req.Header.Set("Referer", qbittorrent.baseURL)
+
+	client := &http.Client{Timeout: 30 * time.Second, Transport: httpTransport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	for _, c := range resp.Cookies() {
+		if c.Name == "SID" {
+			qbittorrentSID = c.Value
+			return nil
+		}
+	}
+	return fmt.Errorf("qBittorrent login failed (HTTP %d); check QBITTORRENT_USERNAME and QBITTORRENT_PASSWORD", resp.StatusCode)
+}
+
+// qbittorrentRequest calls the qBittorrent Web API, signing in first if there
+// is no session yet and again if the session has expired. form is sent as
+// the body of POST requests.
+func qbittorrentRequest(ctx context.Context, method, endpoint string, form url.Values) ([]byte, error) {
+	qbittorrentMu.Lock()
+	defer qbittorrentMu.Unlock()
+
+	if qbittorrentSID == "" {
+		if err := qbittorrentLogin(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	send := func() ([]byte, error) {
+		headers := map[string]string{
+			"Cookie":  "SID=" + qbittorrentSID,
+			"Referer": qbittorrent.baseURL,
+		}
+		urlStr := qbittorrent.baseURL + "/api/v2" + endpoint
+		if method == "POST" {
+			headers["Content-Type"] = "application/x-www-form-urlencoded"
+			return doRequest(ctx, method, urlStr, headers, strings.NewReader(form.Encode()))
+		}
+		return doRequest(ctx, method, urlStr, headers, nil)
+	}
+
+	data, err := send()
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusForbidden {
+		if err := qbittorrentLogin(ctx); err != nil {
+			return nil, err
+		}
+		return send()
+	}
+	return data, err
+}
+
+type qbittorrentService struct {
+	baseURL  string
+	username string
+	password string
+}
+
+var qbittorrent = &qbittorrentService{}
+
+func (*qbittorrentService) Name() string  { return "qBittorrent" }
+func (q *qbittorrentService) URL() string { return q.baseURL }
+
+func (q *qbittorrentService) Configure() bool {
+	q.baseURL = strings.TrimSuffix(os.Getenv("QBITTORRENT_URL"), "/")
+	q.username = os.Getenv("QBITTORRENT_USERNAME")
+	q.password = os.Getenv("QBITTORRENT_PASSWORD")
+	return q.baseURL != ""
+}
+
+func (*qbittorrentService) RegisterTools(s *server.MCPServer) { registerTorrentTools(s) }
+
+func (*qbittorrentService) HealthCheck(ctx context.Context) (string, error) {
+	qbittorrentMu.Lock()
+	err := qbittorrentLogin(ctx)
+	qbittorrentMu.Unlock()
+	var urlErr *url.Error
+	if err != nil && !errors.As(err, &urlErr) {
+		return "", &authError{err.Error()}
+	} else if err != nil {
+		return "", err
+	}
+	data, err := qbittorrentRequest(ctx, "GET", "/app/version", nil)
+	return strings.TrimSpace(string(data)), err
+}
+
+// ============================================================================
+// SABnzbd
+// ============================================================================
+
+func sabnzbdRequest(ctx context.Context, mode string, params url.Values) ([]byte, error) {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("mode", mode)
+	params.Set("apikey", sabnzbd.apiKey)
+	params.Set("output", "json")
+	return doRequest(ctx, "GET", sabnzbd.baseURL+"/api?"+params.Encode(), nil, nil)
+}
+
+type sabnzbdService struct {
+	baseURL string
+	apiKey  string
+}
+
+var sabnzbd = &sabnzbdService{}
+
+func (*sabnzbdService) Name() string  { return "SABnzbd" }
+func (s *sabnzbdService) URL() string { return s.baseURL }
+
+func (s *sabnzbdService) Configure() bool {
+	s.baseURL = strings.TrimSuffix(os.Getenv("SABNZBD_URL"), "/")
+	s.apiKey = os.Getenv("SABNZBD_API_KEY")
+	return s.baseURL != ""
+}
+
+// SABnzbd has no tools of its own; ultimarr_bandwidth covers both clients
+func (*sabnzbdService) RegisterTools(s *server.MCPServer) {}
+
+func (*sabnzbdService) HealthCheck(ctx context.Context) (string, error) {
+	// SABnzbd reports a wrong key in the body of a 200 response
+	data, err := sabnzbdRequest(ctx, "queue", url.Values{"limit": {"1"}})
+	if err != nil {
+		return "", err
+	}
+	var result map[string]interface{}
+	json.Unmarshal(data, &result)
+	if msg, ok := result["error"].(string); ok {
+		return "", &authError{msg}
+	}
+	return versionField(sabnzbdRequest(ctx, "version", nil))
+}
+
+// registerDownloadClientTools adds the tools that cover both download
+// clients, if either is configured.
+func registerDownloadClientTools(s *server.MCPServer) {
+	if !isConfigured(qbittorrent) && !isConfigured(sabnzbd) {
+		return
+	}
+
+	// Bandwidth
+	s.AddTool(
+		mcp.NewTool("ultimarr_bandwidth",
+			mcp.WithDescription("Report download client transfer statistics: current speeds and totals for today, the last 7 days, this month, and all time. Use for \"how much have we downloaded this month?\" on capped connections."),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[bandwidthReport](),
+		),
+		handleUltimarrBandwidth,
+	)
+
+}
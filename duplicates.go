@@ -0,0 +1,507 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// Duplicate Files
+// ============================================================================
+
+// Sonarr and Radarr keep one file per episode or movie, but failed upgrades
+// and manual imports leave extra files behind: episode files no episode
+// points to, and movie files other than the movie's own. Files deleted with
+// a recycle bin configured are only moved there, and linger when its cleanup
+// doesn't run.
+
+// How deep to look into a recycle bin
+const recycleBinDepth = 4
+
+// How long to wait for CleanUpRecycleBin to finish
+const recycleBinCleanupTimeout = time.Minute
+
+// Episode number in a file name, e.g. "S02E05"
+var episodeLabel = regexp.MustCompile(`(?i)S(\d{1,2})E(\d{1,3})`)
+
+// duplicateReport is the structured result of ultimarr_duplicate_cleanup. A
+// dry run lists what would be removed and returns ConfirmToken; a confirmed
+// run sets Purged and Reclaimed.
+type duplicateReport struct {
+	DryRun       bool            `json:"dryRun"`
+	Purged       bool            `json:"purged"`
+	Duplicates   []duplicateFile `json:"duplicates"`
+	RecycleBins  []recycleBin    `json:"recycleBins"`
+	Reclaimable  int64           `json:"reclaimable"`
+	Reclaimed    int64           `json:"reclaimed,omitempty"`
+	ConfirmToken string          `json:"confirmToken,omitempty"`
+	ExpiresAt    *time.Time      `json:"expiresAt,omitempty"`
+	Errors       []string        `json:"errors,omitempty"`
+}
+
+// duplicateFile is a file Sonarr or Radarr tracks but no episode or movie
+// uses. Episode is set when the file name names one.
+type duplicateFile struct {
+	Service string `json:"service"`
+	FileID  int    `json:"fileId"`
+	ItemID  int    `json:"itemId"` // series or movie
+	Title   string `json:"title"`
+	Episode string `json:"episode,omitempty"`
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Reason  string `json:"reason"`
+}
+
+// recycleBin is a recycle bin and the files in it older than its cleanup
+// window. A bin shared by Sonarr and Radarr is listed once.
+type recycleBin struct {
+	Path        string     `json:"path"`
+	Services    []string   `json:"services"`
+	CleanupDays int        `json:"cleanupDays"` // 0 when automatic cleanup is off
+	Files       int        `json:"files"`
+	Size        int64      `json:"size"`
+	Expired     int        `json:"expired"`
+	ExpiredSize int64      `json:"expiredSize"`
+	Oldest      *time.Time `json:"oldest,omitempty"`
+}
+
+// pendingCleanup is a dry run of ultimarr_duplicate_cleanup waiting for
+// confirmation. Confirming removes exactly the files that were shown and
+// empties the bins that had expired files.
+type pendingCleanup struct {
+	Duplicates []duplicateFile
+	Bins       []recycleBin
+	Expires    time.Time
+}
+
+var (
+	pendingCleanupsMu sync.Mutex
+	pendingCleanups   = map[string]pendingCleanup{}
+)
+
+func handleUltimarrDuplicateCleanup(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	if token, ok := args["confirm_token"].(string); ok && token != "" {
+		return executeDuplicateCleanup(ctx, token)
+	}
+	only, _ := args["service"].(string)
+
+	report := duplicateReport{DryRun: true, Duplicates: []duplicateFile{}, RecycleBins: []recycleBin{}}
+	bins := map[string]*recycleBin{}
+	var binOrder []string
+	scanned := 0
+	for _, svc := range arrServices() {
+		if only != "" && !strings.EqualFold(only, svc.Name) {
+			continue
+		}
+		scanned++
+		var dups []duplicateFile
+		var err error
+		if svc.Name == "Sonarr" {
+			dups, err = duplicateEpisodeFiles(ctx, svc)
+		} else {
+			dups, err = duplicateMovieFiles(ctx, svc)
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", svc.Name, err))
+		}
+		report.Duplicates = append(report.Duplicates, dups...)
+
+		path, days, err := recycleBinSettings(ctx, svc)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s recycle bin: %v", svc.Name, err))
+			continue
+		}
+		if path == "" {
+			continue
+		}
+		key := strings.TrimRight(path, "/")
+		if bin := bins[key]; bin != nil {
+			// Either service's cleanup empties the shared bin, so the shorter
+			// window is the one that applies
+			bin.Services = append(bin.Services, svc.Name)
+			if days > 0 && (bin.CleanupDays == 0 || days < bin.CleanupDays) {
+				bin.CleanupDays = days
+			}
+			continue
+		}
+		bins[key] = &recycleBin{Path: path, Services: []string{svc.Name}, CleanupDays: days}
+		binOrder = append(binOrder, key)
+	}
+	if scanned == 0 {
+		return mcp.NewToolResultError("Neither Sonarr nor Radarr is configured"), nil
+	}
+
+	for _, key := range binOrder {
+		bin := bins[key]
+		if err := scanRecycleBin(ctx, bin); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("Recycle bin %s: %v", bin.Path, err))
+			continue
+		}
+		report.RecycleBins = append(report.RecycleBins, *bin)
+	}
+
+	var lines []string
+	pc := pendingCleanup{Expires: time.Now().Add(confirmTokenTTL)}
+	if len(report.Duplicates) > 0 {
+		lines = append(lines, fmt.Sprintf("Duplicate files (%d):", len(report.Duplicates)))
+		for _, d := range report.Duplicates {
+			report.Reclaimable += d.Size
+			lines = append(lines, fmt.Sprintf("  %s [file %d] %s: %s (%s) - %s", d.Service, d.FileID, d.Title, d.Path, formatBytes(d.Size), d.Reason))
+		}
+		pc.Duplicates = report.Duplicates
+	} else {
+		lines = append(lines, "No duplicate files.")
+	}
+	for _, bin := range report.RecycleBins {
+		line := fmt.Sprintf("Recycle bin %s (%s): %d file(s), %s", bin.Path, strings.Join(bin.Services, ", "), bin.Files, formatBytes(bin.Size))
+		switch {
+		case bin.CleanupDays == 0:
+			line += "; automatic cleanup is off, so nothing counts as expired"
+		case bin.Expired > 0:
+			line += fmt.Sprintf("; %d file(s) older than %d days (%s) should have been cleaned up", bin.Expired, bin.CleanupDays, formatBytes(bin.ExpiredSize))
+			report.Reclaimable += bin.ExpiredSize
+			pc.Bins = append(pc.Bins, bin)
+		default:
+			line += fmt.Sprintf("; nothing older than %d days", bin.CleanupDays)
+		}
+		if bin.Oldest != nil {
+			line += fmt.Sprintf(", oldest from %s", bin.Oldest.Local().Format("2006-01-02"))
+		}
+		lines = append(lines, line)
+	}
+	for _, e := range report.Errors {
+		lines = append(lines, "Error: "+e)
+	}
+
+	if len(pc.Duplicates) == 0 && len(pc.Bins) == 0 {
+		return mcp.NewToolResultStructured(report, strings.Join(lines, "\n")+"\nNothing to clean up."), nil
+	}
+
+	token := newConfirmToken()
+	pendingCleanupsMu.Lock()
+	pendingCleanups[token] = pc
+	pendingCleanupsMu.Unlock()
+	report.ConfirmToken = token
+	report.ExpiresAt = &pc.Expires
+
+	header := fmt.Sprintf("DRY RUN: %s could be reclaimed.\n", formatBytes(report.Reclaimable))
+	footer := fmt.Sprintf("\nNothing has been deleted. To remove exactly these duplicates and empty the expired recycle bin files, call again with confirm_token=%q within %d minutes.", token, int(confirmTokenTTL.Minutes()))
+	return mcp.NewToolResultStructured(report, header+strings.Join(lines, "\n")+"\n"+footer), nil
+}
+
+func executeDuplicateCleanup(ctx context.Context, token string) (*mcp.CallToolResult, error) {
+	pendingCleanupsMu.Lock()
+	pc, ok := pendingCleanups[token]
+	if ok {
+		delete(pendingCleanups, token)
+	}
+	pendingCleanupsMu.Unlock()
+	if !ok || time.Now().After(pc.Expires) {
+		return mcp.NewToolResultError("Unknown or expired confirm_token. Run ultimarr_duplicate_cleanup again without a token to get a fresh dry run."), nil
+	}
+
+	requests := map[string]arrRequestFunc{}
+	for _, svc := range arrServices() {
+		requests[svc.Name] = svc.Request
+	}
+
+	report := duplicateReport{Purged: true, Duplicates: []duplicateFile{}, RecycleBins: []recycleBin{}}
+	var lines []string
+	binned := map[string]bool{} // whether each service moves deleted files to a recycle bin
+	for _, d := range pc.Duplicates {
+		request := requests[d.Service]
+		if request == nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s is no longer configured", d.Service))
+			continue
+		}
+		endpoint := fmt.Sprintf("/moviefile/%d", d.FileID)
+		if d.Service == "Sonarr" {
+			endpoint = fmt.Sprintf("/episodefile/%d", d.FileID)
+		}
+		if _, err := request(ctx, "DELETE", endpoint, nil); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s file %d (%s): %v", d.Service, d.FileID, d.Path, err))
+			continue
+		}
+		report.Duplicates = append(report.Duplicates, d)
+		report.Reclaimed += d.Size
+		lines = append(lines, fmt.Sprintf("Deleted %s file %d: %s (%s)", d.Service, d.FileID, d.Path, formatBytes(d.Size)))
+		if _, checked := binned[d.Service]; !checked {
+			path, _, err := recycleBinSettings(ctx, arrService{Name: d.Service, Request: request})
+			binned[d.Service] = err == nil && path != ""
+		}
+	}
+
+	for _, bin := range pc.Bins {
+		var status string
+		var err error
+		for _, name := range bin.Services {
+			if request := requests[name]; request != nil {
+				status, err = runCommand(ctx, request, map[string]interface{}{"name": "CleanUpRecycleBin"}, recycleBinCleanupTimeout)
+				break
+			}
+		}
+		switch {
+		case err != nil:
+			report.Errors = append(report.Errors, fmt.Sprintf("Recycle bin %s: %v", bin.Path, err))
+		case status != "completed":
+			report.Errors = append(report.Errors, fmt.Sprintf("Recycle bin %s: cleanup %s", bin.Path, status))
+		default:
+			report.RecycleBins = append(report.RecycleBins, bin)
+			report.Reclaimed += bin.ExpiredSize
+			lines = append(lines, fmt.Sprintf("Emptied %d expired file(s) from recycle bin %s (%s)", bin.Expired, bin.Path, formatBytes(bin.ExpiredSize)))
+		}
+	}
+	for _, e := range report.Errors {
+		lines = append(lines, "Error: "+e)
+	}
+
+	lines = append(lines, fmt.Sprintf("Reclaimed %s.", formatBytes(report.Reclaimed)))
+	var names []string
+	for name, ok := range binned {
+		if ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		lines = append(lines, fmt.Sprintf("%s moved the deleted duplicates to the recycle bin; their space is freed when it's emptied.", strings.Join(names, " and ")))
+	}
+	return mcp.NewToolResultStructured(report, strings.Join(lines, "\n")), nil
+}
+
+// duplicateEpisodeFiles finds episode files no episode of their series uses.
+func duplicateEpisodeFiles(ctx context.Context, svc arrService) ([]duplicateFile, error) {
+	data, err := svc.Request(ctx, "GET", "/series", nil)
+	if err != nil {
+		return nil, err
+	}
+	var series []map[string]interface{}
+	json.Unmarshal(data, &series)
+
+	var ids []int
+	titles := map[int]string{}
+	for _, s := range series {
+		stats, _ := s["statistics"].(map[string]interface{})
+		if size, _ := stats["sizeOnDisk"].(float64); size == 0 {
+			continue
+		}
+		id, _ := s["id"].(float64)
+		ids = append(ids, int(id))
+		titles[int(id)], _ = s["title"].(string)
+	}
+
+	return scanItems(ids, func(id int) ([]duplicateFile, error) {
+		data, err := svc.Request(ctx, "GET", fmt.Sprintf("/episode?seriesId=%d", id), nil)
+		if err != nil {
+			return nil, err
+		}
+		var episodes []map[string]interface{}
+		json.Unmarshal(data, &episodes)
+
+		data, err = svc.Request(ctx, "GET", fmt.Sprintf("/episodefile?seriesId=%d", id), nil)
+		if err != nil {
+			return nil, err
+		}
+		var files []map[string]interface{}
+		json.Unmarshal(data, &files)
+
+		used := map[int]bool{}
+		withFile := map[string]bool{}
+		for _, e := range episodes {
+			if fileID, _ := e["episodeFileId"].(float64); fileID > 0 {
+				used[int(fileID)] = true
+				season, _ := e["seasonNumber"].(float64)
+				number, _ := e["episodeNumber"].(float64)
+				withFile[fmt.Sprintf("S%02dE%02d", int(season), int(number))] = true
+			}
+		}
+
+		var dups []duplicateFile
+		for _, f := range files {
+			fileID, _ := f["id"].(float64)
+			if used[int(fileID)] {
+				continue
+			}
+			size, _ := f["size"].(float64)
+			path, _ := f["relativePath"].(string)
+			d := duplicateFile{Service: svc.Name, FileID: int(fileID), ItemID: id, Title: titles[id], Path: path, Size: int64(size),
+				Reason: "not linked to any episode"}
+			if m := episodeLabel.FindStringSubmatch(path); m != nil {
+				var season, number int
+				fmt.Sscan(m[1], &season)
+				fmt.Sscan(m[2], &number)
+				d.Episode = fmt.Sprintf("S%02dE%02d", season, number)
+				if withFile[d.Episode] {
+					d.Reason = "duplicate of " + d.Episode + ", which has another file"
+				}
+			}
+			dups = append(dups, d)
+		}
+		return dups, nil
+	})
+}
+
+// duplicateMovieFiles finds movie files other than the one each movie uses.
+func duplicateMovieFiles(ctx context.Context, svc arrService) ([]duplicateFile, error) {
+	data, err := svc.Request(ctx, "GET", "/movie", nil)
+	if err != nil {
+		return nil, err
+	}
+	var movies []map[string]interface{}
+	json.Unmarshal(data, &movies)
+
+	var ids []int
+	titles := map[int]string{}
+	current := map[int]int{}
+	for _, m := range movies {
+		if size, _ := m["sizeOnDisk"].(float64); size == 0 {
+			continue
+		}
+		id, _ := m["id"].(float64)
+		ids = append(ids, int(id))
+		titles[int(id)], _ = m["title"].(string)
+		if file, ok := m["movieFile"].(map[string]interface{}); ok {
+			fileID, _ := file["id"].(float64)
+			current[int(id)] = int(fileID)
+		}
+	}
+
+	return scanItems(ids, func(id int) ([]duplicateFile, error) {
+		data, err := svc.Request(ctx, "GET", fmt.Sprintf("/moviefile?movieId=%d", id), nil)
+		if err != nil {
+			return nil, err
+		}
+		var files []map[string]interface{}
+		json.Unmarshal(data, &files)
+
+		var dups []duplicateFile
+		for _, f := range files {
+			fileID, _ := f["id"].(float64)
+			if int(fileID) == current[id] {
+				continue
+			}
+			size, _ := f["size"].(float64)
+			path, _ := f["relativePath"].(string)
+			reason := "not the movie's current file"
+			if current[id] == 0 {
+				reason = "the movie has no current file"
+			}
+			dups = append(dups, duplicateFile{Service: svc.Name, FileID: int(fileID), ItemID: id, Title: titles[id], Path: path, Size: int64(size), Reason: reason})
+		}
+		return dups, nil
+	})
+}
+
+// scanItems runs scan for each series or movie, several at a time, and
+// collects the results in order. It fails only if every scan did.
+func scanItems(ids []int, scan func(id int) ([]duplicateFile, error)) ([]duplicateFile, error) {
+	found := make([][]duplicateFile, len(ids))
+	errs := make([]error, len(ids))
+	sem := make(chan struct{}, gapScanWorkers)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i, id int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			found[i], errs[i] = scan(id)
+		}(i, id)
+	}
+	wg.Wait()
+
+	var dups []duplicateFile
+	var firstErr error
+	failed := 0
+	for i := range ids {
+		if errs[i] != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			continue
+		}
+		dups = append(dups, found[i]...)
+	}
+	if failed > 0 && failed == len(ids) {
+		return nil, firstErr
+	}
+	return dups, nil
+}
+
+// recycleBinSettings returns a service's recycle bin folder, empty if it
+// deletes files outright, and how many days files are kept there.
+func recycleBinSettings(ctx context.Context, svc arrService) (string, int, error) {
+	data, err := svc.Request(ctx, "GET", "/config/mediamanagement", nil)
+	if err != nil {
+		return "", 0, err
+	}
+	var mm map[string]interface{}
+	json.Unmarshal(data, &mm)
+	path, _ := mm["recycleBin"].(string)
+	days, _ := mm["recycleBinCleanupDays"].(float64)
+	return path, int(days), nil
+}
+
+// scanRecycleBin counts the files in a recycle bin and those older than its
+// cleanup window. The bin is listed through the first of its services.
+func scanRecycleBin(ctx context.Context, bin *recycleBin) error {
+	var request arrRequestFunc
+	for _, svc := range arrServices() {
+		if svc.Name == bin.Services[0] {
+			request = svc.Request
+		}
+	}
+	cutoff := time.Now().AddDate(0, 0, -bin.CleanupDays)
+
+	var walk func(path string, depth int) error
+	walk = func(path string, depth int) error {
+		data, err := request(ctx, "GET", "/filesystem?includeFiles=true&path="+url.QueryEscape(path), nil)
+		if err != nil {
+			return err
+		}
+		var listing struct {
+			Directories []struct {
+				Path string `json:"path"`
+			} `json:"directories"`
+			Files []struct {
+				Size         float64   `json:"size"`
+				LastModified time.Time `json:"lastModified"`
+			} `json:"files"`
+		}
+		json.Unmarshal(data, &listing)
+
+		for _, f := range listing.Files {
+			bin.Files++
+			bin.Size += int64(f.Size)
+			if bin.Oldest == nil || f.LastModified.Before(*bin.Oldest) {
+				modified := f.LastModified
+				bin.Oldest = &modified
+			}
+			if bin.CleanupDays > 0 && f.LastModified.Before(cutoff) {
+				bin.Expired++
+				bin.ExpiredSize += int64(f.Size)
+			}
+		}
+		if depth == recycleBinDepth {
+			return nil
+		}
+		for _, d := range listing.Directories {
+			if err := walk(d.Path, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(bin.Path, 1)
+}
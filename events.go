@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Events
+// ============================================================================
+
+// Event types published on the event bus
+const (
+	EventMediaAvailable = "media_available"
+	EventImported       = "imported"
+)
+
+// Event is a status change observed on one of the backend services, either by
+// polling or by a webhook pushed from the service itself.
+type Event struct {
+	Type      string
+	Title     string // set on imports, e.g. "Severance S02E09"
+	RequestID int
+	TmdbID    int
+	MediaType string
+	Partial   bool
+	Time      time.Time
+}
+
+var (
+	eventMu          sync.Mutex
+	eventSubscribers []func(Event)
+	eventPollers     []func(ctx context.Context)
+)
+
+// subscribe registers fn to be called for every published event.
+func subscribe(fn func(Event)) {
+	eventMu.Lock()
+	defer eventMu.Unlock()
+	eventSubscribers = append(eventSubscribers, fn)
+}
+
+// addPoller registers fn to be called on every poll tick.
+func addPoller(fn func(ctx context.Context)) {
+	eventMu.Lock()
+	defer eventMu.Unlock()
+	eventPollers = append(eventPollers, fn)
+}
+
+func publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	eventMu.Lock()
+	subs := append([]func(Event){}, eventSubscribers...)
+	eventMu.Unlock()
+
+	for _, fn := range subs {
+		fn(e)
+	}
+}
+
+// startEventSources starts the background poller and, if configured, the
+// webhook listener. Both feed the same event bus.
+func startEventSources() {
+	if config.PollInterval > 0 {
+		go pollLoop(config.PollInterval)
+	}
+	if config.WebhookAddr != "" {
+		go serveWebhooks(config.WebhookAddr)
+	}
+}
+
+func pollLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		eventMu.Lock()
+		pollers := append([]func(ctx context.Context){}, eventPollers...)
+		eventMu.Unlock()
+
+		for _, fn := range pollers {
+			fn(context.Background())
+		}
+	}
+}
+
+// ============================================================================
+// Webhooks
+// ============================================================================
+
+// jellyseerrWebhook matches Jellyseerr's default webhook JSON payload, where
+// every template value is rendered as a string.
+type jellyseerrWebhook struct {
+	NotificationType string `json:"notification_type"`
+	Media            *struct {
+		MediaType string `json:"media_type"`
+		TmdbID    string `json:"tmdbId"`
+		Status    string `json:"status"`
+	} `json:"media"`
+	Request *struct {
+		RequestID string `json:"request_id"`
+	} `json:"request"`
+}
+
+func serveWebhooks(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/webhook/jellyseerr", handleJellyseerrWebhook)
+
+	log.Printf("Listening for webhooks on %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Printf("Webhook server error: %v", err)
+	}
+}
+
+func handleJellyseerrWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(config.WebhookSecret)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var payload jellyseerrWebhook
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+
+	if payload.NotificationType != "MEDIA_AVAILABLE" || payload.Media == nil {
+		return
+	}
+
+	e := Event{
+		Type:      EventMediaAvailable,
+		MediaType: payload.Media.MediaType,
+		Partial:   payload.Media.Status == "PARTIALLY_AVAILABLE",
+	}
+	e.TmdbID, _ = strconv.Atoi(payload.Media.TmdbID)
+	if payload.Request != nil {
+		e.RequestID, _ = strconv.Atoi(payload.Request.RequestID)
+	}
+	publish(e)
+}
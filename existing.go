@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ============================================================================
+// Add Existing Folders
+// ============================================================================
+
+// existingImport describes how one *arr adds an item whose files are already
+// on disk (e.g. a library copied from an old server).
+type existingImport struct {
+	Service  string
+	Request  arrRequestFunc
+	Provider string // "tvdb" or "tmdb": the ID the *arr looks items up by
+	Endpoint string // "/series" or "/movie"
+	Files    string // file listing for an item, e.g. "/episodefile?seriesId=%d"
+
+	// prepare fills in service-specific fields of the item to add, and
+	// refresh is the command that rescans it once added.
+	prepare func(ctx context.Context, item map[string]interface{}, monitor string)
+	refresh func(id int) map[string]interface{}
+}
+
+var (
+	// Folder name tags like "{tvdb-81189}", "[tmdbid-603]" or "{tmdb 603}"
+	folderIDPattern = regexp.MustCompile(`(?i)[\[{](tvdb|tmdb)(?:id)?[-= ](\d+)[\]}]`)
+	// Release year in a folder name, e.g. "The Matrix (1999)"
+	folderYearPattern = regexp.MustCompile(`\((\d{4})\)`)
+)
+
+// existingResult is the structured result of the *_add_existing tools.
+// Defaults is the ULTIMARR_DEFAULTS media kind used for the profile/tags.
+type existingResult struct {
+	Service   string `json:"service"`
+	ID        int    `json:"id"`
+	Title     string `json:"title"`
+	Year      int    `json:"year"`
+	Path      string `json:"path"`
+	ProfileID int    `json:"profileId"`
+	Defaults  string `json:"defaults,omitempty"`
+	Files     int    `json:"files"`
+	Size      int64  `json:"size"`
+}
+
+func (e existingImport) handler() server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+		folder := strings.TrimRight(args["folder"].(string), "/")
+		monitor, _ := args["monitor"].(string)
+		if monitor == "" {
+			monitor = "all"
+		}
+
+		item, err := e.lookup(ctx, folder, args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		title, _ := item["title"].(string)
+		year, _ := item["year"].(float64)
+		if id, _ := item["id"].(float64); id > 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("%s (%d) is already in %s (ID %d)", title, int(year), e.Service, int(id))), nil
+		}
+
+		result := existingResult{Service: e.Service, Title: title, Year: int(year), Path: folder}
+		kind := "movie"
+		if e.Provider == "tvdb" {
+			kind = "tv"
+			if seriesType, _ := item["seriesType"].(string); seriesType == "anime" {
+				if _, ok := config.Defaults["anime"]; ok {
+					kind = "anime"
+				}
+			}
+		}
+		defaults, hasDefaults := config.Defaults[kind]
+		if p, ok := args["profile_id"].(float64); ok {
+			result.ProfileID = int(p)
+		} else if hasDefaults && defaults.ProfileID > 0 {
+			result.ProfileID = defaults.ProfileID
+			result.Defaults = kind
+		} else {
+			return mcp.NewToolResultError(e.profileHint(ctx)), nil
+		}
+		if hasDefaults && len(defaults.Tags) > 0 {
+			item["tags"] = defaults.Tags
+			result.Defaults = kind
+		}
+
+		// An explicit path adds the item where its files already are
+		item["path"] = folder
+		item["qualityProfileId"] = result.ProfileID
+		item["monitored"] = monitor != "none"
+		e.prepare(ctx, item, monitor)
+
+		body, _ := json.Marshal(item)
+		data, err := e.Request(ctx, "POST", e.Endpoint, strings.NewReader(string(body)))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		var added map[string]interface{}
+		json.Unmarshal(data, &added)
+		id, _ := added["id"].(float64)
+		result.ID = int(id)
+
+		// Adding queues a refresh too; running one and waiting means the
+		// files have been scanned and imported before counting them
+		status, err := runCommand(ctx, e.Request, e.refresh(result.ID), 5*time.Minute)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Added %s (ID %d) but the rescan failed: %v", title, result.ID, err)), nil
+		}
+
+		if data, err := e.Request(ctx, "GET", fmt.Sprintf(e.Files, result.ID), nil); err == nil {
+			var files []map[string]interface{}
+			json.Unmarshal(data, &files)
+			result.Files = len(files)
+			for _, f := range files {
+				size, _ := f["size"].(float64)
+				result.Size += int64(size)
+			}
+		}
+
+		lines := []string{fmt.Sprintf("Added %s (%d) to %s (ID %d) at %s with quality profile %d, without searching.", title, result.Year, e.Service, result.ID, folder, result.ProfileID)}
+		if result.Defaults != "" {
+			lines = append(lines, fmt.Sprintf("Used %s defaults from ULTIMARR_DEFAULTS.", result.Defaults))
+		}
+		switch {
+		case status != "completed":
+			lines = append(lines, fmt.Sprintf("Rescan %s; files may still be importing.", status))
+		case result.Files == 0:
+			lines = append(lines, fmt.Sprintf("No files were imported. Check that %s can see %s (the path must be as %s sees it, e.g. inside its container).", e.Service, folder, e.Service))
+		default:
+			lines = append(lines, fmt.Sprintf("Imported %d file(s), %s.", result.Files, formatBytes(result.Size)))
+		}
+		return mcp.NewToolResultStructured(result, strings.Join(lines, "\n")), nil
+	}
+}
+
+// lookup identifies the item in folder: by the ID argument, by an ID tag in
+// the folder name, or by searching for the folder name. A name search has to
+// come down to one result, after keeping only those from the folder's year if
+// it has one; otherwise the candidates are listed so an ID can be passed.
+func (e existingImport) lookup(ctx context.Context, folder string, args map[string]interface{}) (map[string]interface{}, error) {
+	name := path.Base(folder)
+	term := ""
+	byID := true
+	if id, ok := args[e.Provider+"_id"].(float64); ok {
+		term = fmt.Sprintf("%s:%d", e.Provider, int(id))
+	} else if m := folderIDPattern.FindStringSubmatch(name); m != nil && strings.EqualFold(m[1], e.Provider) {
+		term = e.Provider + ":" + m[2]
+	} else {
+		term = folderIDPattern.ReplaceAllString(name, "")
+		term = strings.TrimSpace(folderYearPattern.ReplaceAllString(term, ""))
+		byID = false
+	}
+
+	data, err := e.Request(ctx, "GET", e.Endpoint+"/lookup?term="+url.QueryEscape(term), nil)
+	if err != nil {
+		return nil, err
+	}
+	var results []map[string]interface{}
+	json.Unmarshal(data, &results)
+	if len(results) == 0 {
+		return nil, fmt.Errorf("%s found nothing for %q; pass %s_id", e.Service, term, e.Provider)
+	}
+	if byID {
+		return results[0], nil
+	}
+
+	if m := folderYearPattern.FindStringSubmatch(name); m != nil {
+		want, _ := strconv.Atoi(m[1])
+		var sameYear []map[string]interface{}
+		for _, r := range results {
+			if year, _ := r["year"].(float64); int(year) == want {
+				sameYear = append(sameYear, r)
+			}
+		}
+		if len(sameYear) > 0 {
+			results = sameYear
+		}
+	}
+	if len(results) == 1 {
+		return results[0], nil
+	}
+
+	const maxCandidates = 10
+	var candidates []string
+	for _, r := range results[:min(len(results), maxCandidates)] {
+		title, _ := r["title"].(string)
+		year, _ := r["year"].(float64)
+		id, _ := r[e.Provider+"Id"].(float64)
+		candidates = append(candidates, fmt.Sprintf("%s (%d, %s_id %d)", title, int(year), e.Provider, int(id)))
+	}
+	if len(results) > maxCandidates {
+		candidates = append(candidates, fmt.Sprintf("and %d more", len(results)-maxCandidates))
+	}
+	return nil, fmt.Errorf("%q matches %d titles in %s: %s. Pass %s_id for the right one", term, len(results), e.Service, strings.Join(candidates, "; "), e.Provider)
+}
+
+// profileHint lists the quality profiles to choose from when none was given.
+func (e existingImport) profileHint(ctx context.Context) string {
+	msg := "No quality profile given and none configured in ULTIMARR_DEFAULTS; pass profile_id"
+	data, err := e.Request(ctx, "GET", "/qualityprofile", nil)
+	if err != nil {
+		return msg
+	}
+	var profiles []map[string]interface{}
+	json.Unmarshal(data, &profiles)
+	var names []string
+	for _, p := range profiles {
+		id, _ := p["id"].(float64)
+		name, _ := p["name"].(string)
+		names = append(names, fmt.Sprintf("%d (%s)", int(id), name))
+	}
+	return msg + ": " + strings.Join(names, ", ")
+}
+
+var sonarrExisting = existingImport{
+	Service:  "Sonarr",
+	Request:  sonarrRequest,
+	Provider: "tvdb",
+	Endpoint: "/series",
+	Files:    "/episodefile?seriesId=%d",
+	prepare: func(ctx context.Context, item map[string]interface{}, monitor string) {
+		item["seasonFolder"] = true
+		// Required by Sonarr v3, ignored by v4
+		if id, ok := sonarrLanguageProfile(ctx); ok {
+			item["languageProfileId"] = id
+		}
+		item["addOptions"] = map[string]interface{}{
+			"monitor":                      monitor,
+			"searchForMissingEpisodes":     false,
+			"searchForCutoffUnmetEpisodes": false,
+		}
+	},
+	refresh: func(id int) map[string]interface{} {
+		return map[string]interface{}{"name": "RefreshSeries", "seriesId": id}
+	},
+}
+
+// sonarrLanguageProfile returns the first language profile, which Sonarr v3
+// needs when adding a series. Sonarr v4 has none.
+func sonarrLanguageProfile(ctx context.Context) (int, bool) {
+	data, err := sonarrRequest(ctx, "GET", "/languageprofile", nil)
+	if err != nil {
+		return 0, false
+	}
+	var profiles []struct {
+		ID int `json:"id"`
+	}
+	if json.Unmarshal(data, &profiles) != nil || len(profiles) == 0 {
+		return 0, false
+	}
+	return profiles[0].ID, true
+}
+
+var radarrExisting = existingImport{
+	Service:  "Radarr",
+	Request:  radarrRequest,
+	Provider: "tmdb",
+	Endpoint: "/movie",
+	Files:    "/moviefile?movieId=%d",
+	prepare: func(ctx context.Context, item map[string]interface{}, monitor string) {
+		if monitor != "none" {
+			monitor = "movieOnly"
+		}
+		item["minimumAvailability"] = "released"
+		item["addOptions"] = map[string]interface{}{
+			"monitor":        monitor,
+			"searchForMovie": false,
+		}
+	},
+	refresh: func(id int) map[string]interface{} {
+		return map[string]interface{}{"name": "RefreshMovie", "movieIds": []int{id}}
+	},
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// Episode Gaps
+// ============================================================================
+
+// A file this much smaller than the median of its season is likely a sample
+// or a broken import
+const smallEpisodeRatio = 0.4
+
+// Series scanned at once when checking the whole library
+const gapScanWorkers = 4
+
+// gapReport is the structured result of sonarr_find_gaps.
+type gapReport struct {
+	Checked int          `json:"checked"` // series scanned
+	Series  []seriesGaps `json:"series"`
+}
+
+type seriesGaps struct {
+	SeriesID int          `json:"seriesId"`
+	Title    string       `json:"title"`
+	Seasons  []seasonGaps `json:"seasons"`
+}
+
+// seasonGaps lists a season's aired episodes with no file between episodes
+// that have one, and files much smaller than the season's median.
+type seasonGaps struct {
+	Season  int            `json:"season"`
+	Missing []int          `json:"missing"`
+	Small   []smallEpisode `json:"small"`
+}
+
+type smallEpisode struct {
+	Episode int    `json:"episode"`
+	Size    int64  `json:"size"`
+	Median  int64  `json:"median"`
+	Path    string `json:"path"`
+}
+
+func handleSonarrFindGaps(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+
+	var ids []int
+	titles := map[int]string{}
+	if id, ok := args["series_id"].(float64); ok {
+		ids = append(ids, int(id))
+	} else {
+		data, err := sonarrRequest(ctx, "GET", "/series", nil)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		var series []map[string]interface{}
+		json.Unmarshal(data, &series)
+		for _, s := range series {
+			id, _ := s["id"].(float64)
+			stats, _ := s["statistics"].(map[string]interface{})
+			if files, _ := stats["episodeFileCount"].(float64); files > 0 {
+				ids = append(ids, int(id))
+				titles[int(id)], _ = s["title"].(string)
+			}
+		}
+	}
+
+	found := make([]seriesGaps, len(ids))
+	errs := make([]error, len(ids))
+	sem := make(chan struct{}, gapScanWorkers)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i, id int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			found[i], errs[i] = findSeriesGaps(ctx, id, titles[id])
+		}(i, id)
+	}
+	wg.Wait()
+
+	report := gapReport{Checked: len(ids), Series: []seriesGaps{}}
+	var lines []string
+	for i, sg := range found {
+		if errs[i] != nil {
+			if len(ids) == 1 {
+				return mcp.NewToolResultError(errs[i].Error()), nil
+			}
+			lines = append(lines, fmt.Sprintf("Series %d: %v", ids[i], errs[i]))
+			continue
+		}
+		if len(sg.Seasons) == 0 {
+			continue
+		}
+		report.Series = append(report.Series, sg)
+		lines = append(lines, fmt.Sprintf("%s (ID %d):", sg.Title, sg.SeriesID))
+		for _, se := range sg.Seasons {
+			if len(se.Missing) > 0 {
+				var eps []string
+				for _, n := range se.Missing {
+					eps = append(eps, fmt.Sprintf("E%02d", n))
+				}
+				lines = append(lines, fmt.Sprintf("  Season %d missing %s", se.Season, strings.Join(eps, ", ")))
+			}
+			for _, sm := range se.Small {
+				lines = append(lines, fmt.Sprintf("  S%02dE%02d is only %s (season median %s): %s", se.Season, sm.Episode, formatBytes(sm.Size), formatBytes(sm.Median), sm.Path))
+			}
+		}
+	}
+
+	if len(report.Series) == 0 {
+		lines = append(lines, fmt.Sprintf("No gaps or suspiciously small files in %d series.", report.Checked))
+	} else {
+		lines = append(lines, "", "Search missing episodes with sonarr_search_series; delete small files in Sonarr and search again to replace them.")
+	}
+	return mcp.NewToolResultStructured(report, strings.Join(lines, "\n")), nil
+}
+
+// findSeriesGaps checks each season of a series that has files.
+func findSeriesGaps(ctx context.Context, seriesID int, title string) (seriesGaps, error) {
+	sg := seriesGaps{SeriesID: seriesID, Title: title, Seasons: []seasonGaps{}}
+	if title == "" {
+		data, err := sonarrRequest(ctx, "GET", fmt.Sprintf("/series/%d", seriesID), nil)
+		if err != nil {
+			return sg, err
+		}
+		var series map[string]interface{}
+		json.Unmarshal(data, &series)
+		sg.Title, _ = series["title"].(string)
+	}
+
+	data, err := sonarrRequest(ctx, "GET", fmt.Sprintf("/episode?seriesId=%d", seriesID), nil)
+	if err != nil {
+		return sg, err
+	}
+	var episodes []map[string]interface{}
+	json.Unmarshal(data, &episodes)
+
+	data, err = sonarrRequest(ctx, "GET", fmt.Sprintf("/episodefile?seriesId=%d", seriesID), nil)
+	if err != nil {
+		return sg, err
+	}
+	var files []map[string]interface{}
+	json.Unmarshal(data, &files)
+
+	type fileInfo struct {
+		size     int64
+		path     string
+		episodes int
+	}
+	fileByID := map[int]*fileInfo{}
+	for _, f := range files {
+		id, _ := f["id"].(float64)
+		size, _ := f["size"].(float64)
+		path, _ := f["relativePath"].(string)
+		fileByID[int(id)] = &fileInfo{size: int64(size), path: path}
+	}
+
+	type episodeInfo struct {
+		number int
+		aired  bool
+		file   *fileInfo
+	}
+	seasons := map[int][]episodeInfo{}
+	for _, e := range episodes {
+		season, _ := e["seasonNumber"].(float64)
+		if season == 0 {
+			continue // specials are often incomplete on purpose
+		}
+		number, _ := e["episodeNumber"].(float64)
+		airDate, _ := e["airDateUtc"].(string)
+		aired, err := time.Parse(time.RFC3339, airDate)
+		ep := episodeInfo{number: int(number), aired: err == nil && aired.Before(time.Now())}
+		if fileID, _ := e["episodeFileId"].(float64); fileID > 0 {
+			if f := fileByID[int(fileID)]; f != nil {
+				f.episodes++
+				ep.file = f
+			}
+		}
+		seasons[int(season)] = append(seasons[int(season)], ep)
+	}
+
+	var numbers []int
+	for n := range seasons {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	for _, n := range numbers {
+		eps := seasons[n]
+		sort.Slice(eps, func(i, j int) bool { return eps[i].number < eps[j].number })
+
+		last := 0
+		var sizes []int64
+		for _, e := range eps {
+			if e.file != nil {
+				last = e.number
+				// Multi-episode files count once per episode they hold
+				sizes = append(sizes, e.file.size/int64(max(e.file.episodes, 1)))
+			}
+		}
+		if last == 0 {
+			continue
+		}
+
+		se := seasonGaps{Season: n, Missing: []int{}, Small: []smallEpisode{}}
+		for _, e := range eps {
+			if e.file == nil && e.aired && e.number < last {
+				se.Missing = append(se.Missing, e.number)
+			}
+		}
+
+		// Small files only stand out against a few siblings
+		if len(sizes) >= 3 {
+			sort.Slice(sizes, func(i, j int) bool { return sizes[i] < sizes[j] })
+			median := sizes[len(sizes)/2]
+			for _, e := range eps {
+				if e.file == nil {
+					continue
+				}
+				size := e.file.size / int64(max(e.file.episodes, 1))
+				if float64(size) < float64(median)*smallEpisodeRatio {
+					se.Small = append(se.Small, smallEpisode{Episode: e.number, Size: e.file.size, Median: median, Path: e.file.path})
+				}
+			}
+		}
+
+		if len(se.Missing) > 0 || len(se.Small) > 0 {
+			sg.Seasons = append(sg.Seasons, se)
+		}
+	}
+	return sg, nil
+}
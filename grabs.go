@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// Grab Audit Log
+// ============================================================================
+
+// Sonarr/Radarr history shows that a release was grabbed but not why, so the
+// *_download_release tools record the reason they were given in the data
+// directory. With tag=true the series or movie is also tagged with the
+// reason, which shows up in Sonarr/Radarr itself.
+
+// Reasons a specific release is grabbed
+var grabReasons = []string{"user_choice", "best_match", "upgrade_campaign", "other"}
+
+// Grab records kept, oldest dropped first
+const maxGrabRecords = 1000
+
+// Prefix of the tags added with tag=true, e.g. "grab-best-match"
+const grabTagPrefix = "grab-"
+
+// grabRecord is one release grabbed through a *_download_release tool.
+type grabRecord struct {
+	Time      time.Time `json:"time"`
+	Service   string    `json:"service"`
+	SeriesID  int       `json:"seriesId,omitempty"`
+	MovieID   int       `json:"movieId,omitempty"`
+	GUID      string    `json:"guid"`
+	IndexerID int       `json:"indexerId"`
+	Release   string    `json:"release,omitempty"` // release title, when the service returned it
+	Reason    string    `json:"reason"`
+	Comment   string    `json:"comment,omitempty"`
+	Tag       string    `json:"tag,omitempty"`
+}
+
+var (
+	grabMu     sync.Mutex
+	grabLog    []grabRecord
+	grabLoaded bool
+)
+
+func grabsPath() string {
+	return filepath.Join(config.DataDir, "grabs.json")
+}
+
+func loadGrabsLocked() {
+	if grabLoaded {
+		return
+	}
+	grabLoaded = true
+	if data, err := os.ReadFile(grabsPath()); err == nil {
+		json.Unmarshal(data, &grabLog)
+	}
+}
+
+func saveGrabsLocked() error {
+	if err := os.MkdirAll(config.DataDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(grabLog, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := grabsPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, grabsPath())
+}
+
+// grabOptions are the arguments the *_download_release tools take to
+// explain a grab.
+func grabOptions() []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithString("reason", mcp.Enum(grabReasons...), mcp.Description("Why this release: user_choice (the user picked it), best_match (you picked the best result), upgrade_campaign (replacing a file below cutoff), or other. Recorded in the grab log (default user_choice)")),
+		mcp.WithString("comment", mcp.Description("Short note on why this release was chosen, e.g. 'only release with English subs'")),
+		mcp.WithBoolean("tag", mcp.Description("Also tag the series/movie with the reason (e.g. grab-best-match) so it shows in Sonarr/Radarr (default false)")),
+	}
+}
+
+// recordGrab adds a grab to the log, tagging the item first if asked to. It
+// returns a note for the tool's text result, empty if there's nothing to say.
+func recordGrab(ctx context.Context, args map[string]interface{}, rec grabRecord, request arrRequestFunc, itemPath string) string {
+	rec.Time = time.Now()
+	rec.Reason, _ = args["reason"].(string)
+	if rec.Reason == "" {
+		rec.Reason = "user_choice"
+	}
+	rec.Comment, _ = args["comment"].(string)
+
+	var notes []string
+	if tag, _ := args["tag"].(bool); tag {
+		label := grabTagPrefix + strings.ReplaceAll(rec.Reason, "_", "-")
+		if err := tagArrItem(ctx, request, itemPath, label); err != nil {
+			notes = append(notes, fmt.Sprintf("Couldn't tag it %q: %v", label, err))
+		} else {
+			rec.Tag = label
+			notes = append(notes, fmt.Sprintf("Tagged %q in %s.", label, rec.Service))
+		}
+	}
+
+	grabMu.Lock()
+	loadGrabsLocked()
+	grabLog = append(grabLog, rec)
+	if len(grabLog) > maxGrabRecords {
+		grabLog = grabLog[len(grabLog)-maxGrabRecords:]
+	}
+	err := saveGrabsLocked()
+	grabMu.Unlock()
+	if err != nil {
+		log.Printf("Grab log: %v", err)
+		notes = append(notes, "The grab couldn't be written to the grab log: "+err.Error())
+	}
+	return strings.Join(notes, " ")
+}
+
+// releaseTitle returns the title from a *arr's response to a grab, if any.
+func releaseTitle(data []byte) string {
+	var release map[string]interface{}
+	json.Unmarshal(data, &release)
+	title, _ := release["title"].(string)
+	return title
+}
+
+// tagArrItem adds the tag with the given label to a series or movie
+// (itemPath, e.g. "/series/12"), creating the tag if needed.
+func tagArrItem(ctx context.Context, request arrRequestFunc, itemPath, label string) error {
+	data, err := request(ctx, "GET", "/tag", nil)
+	if err != nil {
+		return err
+	}
+	var tags []map[string]interface{}
+	json.Unmarshal(data, &tags)
+	tagID := 0
+	for _, t := range tags {
+		if l, _ := t["label"].(string); strings.EqualFold(l, label) {
+			id, _ := t["id"].(float64)
+			tagID = int(id)
+		}
+	}
+	if tagID == 0 {
+		body, _ := json.Marshal(map[string]string{"label": label})
+		data, err := request(ctx, "POST", "/tag", strings.NewReader(string(body)))
+		if err != nil {
+			return err
+		}
+		var created map[string]interface{}
+		json.Unmarshal(data, &created)
+		id, _ := created["id"].(float64)
+		if id == 0 {
+			return fmt.Errorf("the new tag has no ID")
+		}
+		tagID = int(id)
+	}
+
+	data, err = request(ctx, "GET", itemPath, nil)
+	if err != nil {
+		return err
+	}
+	var item map[string]interface{}
+	json.Unmarshal(data, &item)
+	existing, _ := item["tags"].([]interface{})
+	for _, t := range existing {
+		if id, _ := t.(float64); int(id) == tagID {
+			return nil
+		}
+	}
+	item["tags"] = append(existing, float64(tagID))
+	body, _ := json.Marshal(item)
+	_, err = request(ctx, "PUT", itemPath, strings.NewReader(string(body)))
+	return err
+}
+
+// grabLogResult is the structured result of ultimarr_grab_log, newest first.
+type grabLogResult struct {
+	Grabs []grabRecord `json:"grabs"`
+}
+
+func handleUltimarrGrabLog(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	service, _ := args["service"].(string)
+	seriesID, _ := args["series_id"].(float64)
+	movieID, _ := args["movie_id"].(float64)
+	reason, _ := args["reason"].(string)
+	limit := 20
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+
+	grabMu.Lock()
+	loadGrabsLocked()
+	records := append([]grabRecord(nil), grabLog...)
+	grabMu.Unlock()
+
+	result := grabLogResult{Grabs: []grabRecord{}}
+	for i := len(records) - 1; i >= 0 && len(result.Grabs) < limit; i-- {
+		r := records[i]
+		switch {
+		case service != "" && !strings.EqualFold(service, r.Service),
+			seriesID > 0 && r.SeriesID != int(seriesID),
+			movieID > 0 && r.MovieID != int(movieID),
+			reason != "" && r.Reason != reason:
+			continue
+		}
+		result.Grabs = append(result.Grabs, r)
+	}
+	if len(result.Grabs) == 0 {
+		return mcp.NewToolResultStructured(result, "No grabs recorded that match."), nil
+	}
+
+	lines := []string{fmt.Sprintf("Grabs (%d, newest first):", len(result.Grabs))}
+	for _, r := range result.Grabs {
+		item := fmt.Sprintf("series %d", r.SeriesID)
+		if r.MovieID > 0 {
+			item = fmt.Sprintf("movie %d", r.MovieID)
+		}
+		release := r.Release
+		if release == "" {
+			release = r.GUID
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s %s: %s", r.Time.Local().Format("2006-01-02 15:04"), r.Service, item, release))
+		why := "    Reason: " + r.Reason
+		if r.Comment != "" {
+			why += " - " + r.Comment
+		}
+		lines = append(lines, why)
+	}
+	return mcp.NewToolResultStructured(result, strings.Join(lines, "\n")), nil
+}
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// Indexer Tests
+// ============================================================================
+
+// indexerTest is one indexer's result in ultimarr_test_indexers. Results is
+// how many releases it returned in the item search, if one was run. Problem
+// is "auth", "rate limited", "timeout", "unreachable", "disabled", "no
+// results", or "error".
+type indexerTest struct {
+	Service       string `json:"service"`
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	Protocol      string `json:"protocol"`
+	Millis        int64  `json:"millis"`
+	Results       *int   `json:"results,omitempty"`
+	DisabledUntil string `json:"disabledUntil,omitempty"`
+	Problem       string `json:"problem,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// indexerReport is the structured result of ultimarr_test_indexers.
+type indexerReport struct {
+	Indexers []indexerTest `json:"indexers"`
+	Skipped  []string      `json:"skipped"` // indexers with every search disabled
+	Problems int           `json:"problems"`
+}
+
+func handleUltimarrTestIndexers(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	only, _ := args["service"].(string)
+
+	// An item search shows how many releases each indexer actually returns
+	searches := map[string]func() ([]byte, error){}
+	if id, ok := args["series_id"].(float64); ok {
+		searches["Sonarr"] = func() ([]byte, error) {
+			return doAPIKeyRequestTimeout(ctx, "GET", fmt.Sprintf("%s/api/v3/release?seriesId=%d", sonarr.baseURL, int(id)), sonarr.keys, nil, releaseSearchTimeout)
+		}
+	}
+	if id, ok := args["movie_id"].(float64); ok {
+		searches["Radarr"] = func() ([]byte, error) {
+			return doAPIKeyRequestTimeout(ctx, "GET", fmt.Sprintf("%s/api/v3/release?movieId=%d", radarr.baseURL, int(id)), radarr.keys, nil, releaseSearchTimeout)
+		}
+	}
+
+	var services []arrService
+	for _, svc := range arrServices() {
+		if only == "" || strings.EqualFold(only, svc.Name) {
+			services = append(services, svc)
+		}
+	}
+	if len(services) == 0 {
+		return mcp.NewToolResultError("Neither Sonarr nor Radarr is configured"), nil
+	}
+
+	report := indexerReport{Indexers: []indexerTest{}, Skipped: []string{}}
+	for _, svc := range services {
+		tests, skipped, err := testIndexers(ctx, svc, searches[svc.Name])
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%s: %v", svc.Name, err)), nil
+		}
+		report.Indexers = append(report.Indexers, tests...)
+		report.Skipped = append(report.Skipped, skipped...)
+	}
+
+	var lines []string
+	service := ""
+	for _, t := range report.Indexers {
+		if t.Service != service {
+			if service != "" {
+				lines = append(lines, "")
+			}
+			service = t.Service
+			lines = append(lines, service+":")
+		}
+		line := fmt.Sprintf("  %s (%s): ", t.Name, t.Protocol)
+		if t.Problem == "" {
+			line += "OK"
+		} else {
+			line += "PROBLEM (" + t.Problem + ")"
+			report.Problems++
+		}
+		line += fmt.Sprintf(", test took %.1fs", float64(t.Millis)/1000)
+		if t.Results != nil {
+			line += fmt.Sprintf(", %d result(s)", *t.Results)
+		}
+		lines = append(lines, line)
+		if t.DisabledUntil != "" {
+			lines = append(lines, "    Disabled by "+t.Service+" after repeated failures until "+t.DisabledUntil)
+		}
+		if t.Error != "" {
+			lines = append(lines, "    "+t.Error)
+		}
+	}
+	if len(report.Skipped) > 0 {
+		lines = append(lines, "", "Skipped (all searches disabled): "+strings.Join(report.Skipped, ", "))
+	}
+
+	lines = append(lines, "")
+	switch {
+	case len(report.Indexers) == 0:
+		lines = append(lines, "No enabled indexers; interactive searches will always be empty.")
+	case report.Problems == 0:
+		lines = append(lines, "All indexers responded.")
+	default:
+		lines = append(lines, fmt.Sprintf("%d indexer(s) with problems.", report.Problems))
+	}
+	if len(searches) == 0 {
+		lines = append(lines, "Pass series_id or movie_id to also count each indexer's results for a real search.")
+	}
+
+	return mcp.NewToolResultStructured(report, strings.Join(lines, "\n")), nil
+}
+
+// testIndexers runs the *arr's own test against each enabled indexer, which
+// queries the indexer, and times it. If search is set, it runs a release
+// search alongside whose results are counted per indexer.
+func testIndexers(ctx context.Context, svc arrService, search func() ([]byte, error)) ([]indexerTest, []string, error) {
+	data, err := svc.Request(ctx, "GET", "/indexer", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var indexers []map[string]interface{}
+	json.Unmarshal(data, &indexers)
+
+	// Indexers the *arr has backed off from after failures
+	disabled := map[int]string{}
+	if data, err := svc.Request(ctx, "GET", "/indexerstatus", nil); err == nil {
+		var statuses []map[string]interface{}
+		json.Unmarshal(data, &statuses)
+		for _, st := range statuses {
+			id, _ := st["indexerId"].(float64)
+			till, _ := st["disabledTill"].(string)
+			if t, err := time.Parse(time.RFC3339, till); err == nil && t.After(time.Now()) {
+				disabled[int(id)] = t.Local().Format("Jan 2 15:04")
+			}
+		}
+	}
+
+	var tests []indexerTest
+	var skipped []string
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, ix := range indexers {
+		name, _ := ix["name"].(string)
+		rss, _ := ix["enableRss"].(bool)
+		auto, _ := ix["enableAutomaticSearch"].(bool)
+		interactive, _ := ix["enableInteractiveSearch"].(bool)
+		if !rss && !auto && !interactive {
+			skipped = append(skipped, svc.Name+" "+name)
+			continue
+		}
+
+		id, _ := ix["id"].(float64)
+		protocol, _ := ix["protocol"].(string)
+		t := indexerTest{Service: svc.Name, ID: int(id), Name: name, Protocol: protocol, DisabledUntil: disabled[int(id)]}
+		wg.Add(1)
+		go func(ix map[string]interface{}) {
+			defer wg.Done()
+			body, _ := json.Marshal(ix)
+			start := time.Now()
+			_, err := svc.Request(ctx, "POST", "/indexer/test", strings.NewReader(string(body)))
+			t.Millis = time.Since(start).Milliseconds()
+			if err != nil {
+				t.Error = describeValidationError(err)
+				t.Problem = classifyIndexerError(t.Error)
+			} else if t.DisabledUntil != "" {
+				t.Problem = "disabled"
+			}
+			mu.Lock()
+			tests = append(tests, t)
+			mu.Unlock()
+		}(ix)
+	}
+
+	var counts map[int]int
+	if search != nil {
+		data, err := search()
+		if err != nil {
+			wg.Wait()
+			return nil, nil, fmt.Errorf("release search failed: %v", err)
+		}
+		var releases []map[string]interface{}
+		json.Unmarshal(data, &releases)
+		counts = map[int]int{}
+		for _, r := range releases {
+			id, _ := r["indexerId"].(float64)
+			counts[int(id)]++
+		}
+	}
+	wg.Wait()
+
+	sort.Slice(tests, func(i, j int) bool { return tests[i].Name < tests[j].Name })
+	for i := range tests {
+		t := &tests[i]
+		if counts == nil {
+			continue
+		}
+		n := counts[t.ID]
+		t.Results = &n
+		if n == 0 && t.Problem == "" && len(counts) > 0 {
+			t.Problem = "no results"
+			t.Error = "Returned nothing for an item other indexers found releases for; check its categories and search capabilities"
+		}
+	}
+	return tests, skipped, nil
+}
+
+// classifyIndexerError sorts an indexer test failure into a broad cause.
+func classifyIndexerError(msg string) string {
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.Contains(lower, "api key") || strings.Contains(lower, "apikey") || strings.Contains(lower, "unauthorized") ||
+		strings.Contains(lower, "401") || strings.Contains(lower, "403") || strings.Contains(lower, "passkey") ||
+		strings.Contains(lower, "login") || strings.Contains(lower, "cookie") || strings.Contains(lower, "credentials"):
+		return "auth"
+	case strings.Contains(lower, "429") || strings.Contains(lower, "rate limit") || strings.Contains(lower, "too many") ||
+		strings.Contains(lower, "request limit") || strings.Contains(lower, "query limit"):
+		return "rate limited"
+	case strings.Contains(lower, "timed out") || strings.Contains(lower, "timeout"):
+		return "timeout"
+	case strings.Contains(lower, "unable to connect") || strings.Contains(lower, "name or service not known") ||
+		strings.Contains(lower, "connection refused") || strings.Contains(lower, "no such host"):
+		return "unreachable"
+	}
+	return "error"
+}
+
+// ============================================================================
+// Indexer Grab Report
+// ============================================================================
+
+// History event types, the same in Sonarr and Radarr
+const (
+	historyGrabbed  = 1
+	historyImported = 3
+	historyFailed   = 4
+)
+
+// indexerStats is one indexer's grabs in ultimarr_indexer_report. Indexers
+// with the same name in Sonarr and Radarr (as Prowlarr syncs them) are
+// combined. Pending grabs were neither imported nor failed yet.
+type indexerStats struct {
+	Name          string   `json:"name"`
+	Services      []string `json:"services"`
+	Grabs         int      `json:"grabs"`
+	Imported      int      `json:"imported"`
+	Failed        int      `json:"failed"`
+	Pending       int      `json:"pending"`
+	FailureRate   float64  `json:"failureRate"` // failed / (imported + failed)
+	AvgResolution int      `json:"avgResolution,omitempty"`
+	TopQuality    string   `json:"topQuality,omitempty"`
+	AvgSize       int64    `json:"avgSize,omitempty"`
+
+	resolutions []int
+	qualities   map[string]int
+	sizes       []int64
+}
+
+// indexerGrabReport is the structured result of ultimarr_indexer_report.
+type indexerGrabReport struct {
+	Days     int            `json:"days"`
+	Indexers []indexerStats `json:"indexers"`
+	Errors   []string       `json:"errors,omitempty"`
+}
+
+func handleUltimarrIndexerReport(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	only, _ := args["service"].(string)
+	days := 30
+	if d, ok := args["days"].(float64); ok && d > 0 {
+		days = int(d)
+	}
+	since := time.Now().AddDate(0, 0, -days)
+
+	report := indexerGrabReport{Days: days, Indexers: []indexerStats{}}
+	stats := map[string]*indexerStats{}
+	get := func(name, service string) *indexerStats {
+		s, ok := stats[strings.ToLower(name)]
+		if !ok {
+			s = &indexerStats{Name: name, qualities: map[string]int{}}
+			stats[strings.ToLower(name)] = s
+		}
+		for _, svc := range s.Services {
+			if svc == service {
+				return s
+			}
+		}
+		s.Services = append(s.Services, service)
+		return s
+	}
+
+	scanned := 0
+	for _, svc := range arrServices() {
+		if only != "" && !strings.EqualFold(only, svc.Name) {
+			continue
+		}
+		scanned++
+		if err := indexerGrabs(ctx, svc, since, get); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", svc.Name, err))
+		}
+	}
+	if scanned == 0 {
+		return mcp.NewToolResultError("Neither Sonarr nor Radarr is configured"), nil
+	}
+	if len(report.Errors) == scanned {
+		return mcp.NewToolResultError(strings.Join(report.Errors, "\n")), nil
+	}
+
+	for _, s := range stats {
+		s.Pending = max(0, s.Grabs-s.Imported-s.Failed)
+		if done := s.Imported + s.Failed; done > 0 {
+			s.FailureRate = float64(s.Failed) / float64(done)
+		}
+		if len(s.resolutions) > 0 {
+			total := 0
+			for _, r := range s.resolutions {
+				total += r
+			}
+			s.AvgResolution = total / len(s.resolutions)
+		}
+		if len(s.sizes) > 0 {
+			var total int64
+			for _, size := range s.sizes {
+				total += size
+			}
+			s.AvgSize = total / int64(len(s.sizes))
+		}
+		for q, n := range s.qualities {
+			if n > s.qualities[s.TopQuality] || n == s.qualities[s.TopQuality] && q < s.TopQuality {
+				s.TopQuality = q
+			}
+		}
+		sort.Strings(s.Services)
+		report.Indexers = append(report.Indexers, *s)
+	}
+	sort.Slice(report.Indexers, func(i, j int) bool {
+		a, b := report.Indexers[i], report.Indexers[j]
+		if a.Grabs != b.Grabs {
+			return a.Grabs > b.Grabs
+		}
+		return a.Name < b.Name
+	})
+
+	lines := []string{fmt.Sprintf("Grabs per indexer over the last %d days:", days)}
+	for _, s := range report.Indexers {
+		if s.Grabs == 0 {
+			lines = append(lines, fmt.Sprintf("  %s (%s): no grabs", s.Name, strings.Join(s.Services, ", ")))
+			continue
+		}
+		line := fmt.Sprintf("  %s (%s): %d grabs, %d imported, %d failed", s.Name, strings.Join(s.Services, ", "), s.Grabs, s.Imported, s.Failed)
+		if s.Imported+s.Failed > 0 {
+			line += fmt.Sprintf(" (%.0f%% failure rate)", 100*s.FailureRate)
+		}
+		if s.Pending > 0 {
+			line += fmt.Sprintf(", %d pending", s.Pending)
+		}
+		lines = append(lines, line)
+		var quality []string
+		if s.TopQuality != "" {
+			quality = append(quality, "mostly "+s.TopQuality)
+		}
+		if s.AvgResolution > 0 {
+			quality = append(quality, fmt.Sprintf("average %dp", s.AvgResolution))
+		}
+		if s.AvgSize > 0 {
+			quality = append(quality, "average "+formatBytes(s.AvgSize))
+		}
+		if len(quality) > 0 {
+			lines = append(lines, "    "+strings.Join(quality, ", "))
+		}
+	}
+	for _, e := range report.Errors {
+		lines = append(lines, "Warning: "+e)
+	}
+	return mcp.NewToolResultStructured(report, strings.Join(lines, "\n")), nil
+}
+
+// indexerGrabs adds a service's grabs since since to the stats, and marks
+// each as imported or failed by its download ID. Configured indexers
+// without grabs are listed too, so unused ones stand out.
+func indexerGrabs(ctx context.Context, svc arrService, since time.Time, get func(name, service string) *indexerStats) error {
+	history := map[int][]map[string]interface{}{}
+	for _, eventType := range []int{historyGrabbed, historyImported, historyFailed} {
+		params := url.Values{"date": {since.UTC().Format(time.RFC3339)}, "eventType": {strconv.Itoa(eventType)}}
+		data, err := svc.Request(ctx, "GET", "/history/since?"+params.Encode(), nil)
+		if err != nil {
+			return err
+		}
+		var records []map[string]interface{}
+		json.Unmarshal(data, &records)
+		history[eventType] = records
+	}
+
+	// Season packs import as many records with one download ID
+	outcome := map[string]int{}
+	for _, eventType := range []int{historyImported, historyFailed} {
+		for _, h := range history[eventType] {
+			if id, _ := h["downloadId"].(string); id != "" {
+				outcome[strings.ToUpper(id)] = eventType
+			}
+		}
+	}
+
+	counted := map[string]bool{}
+	for _, h := range history[historyGrabbed] {
+		data, _ := h["data"].(map[string]interface{})
+		name, _ := data["indexer"].(string)
+		if name == "" {
+			name = "(unknown indexer)"
+		}
+		id, _ := h["downloadId"].(string)
+		id = strings.ToUpper(id)
+		if id != "" {
+			// A season pack grab is also recorded once per episode
+			if counted[id] {
+				continue
+			}
+			counted[id] = true
+		}
+
+		s := get(name, svc.Name)
+		s.Grabs++
+		switch outcome[id] {
+		case historyImported:
+			s.Imported++
+		case historyFailed:
+			s.Failed++
+		}
+		quality, _ := h["quality"].(map[string]interface{})
+		q, _ := quality["quality"].(map[string]interface{})
+		if qname, _ := q["name"].(string); qname != "" {
+			s.qualities[qname]++
+		}
+		if res, _ := q["resolution"].(float64); res > 0 {
+			s.resolutions = append(s.resolutions, int(res))
+		}
+		// History data values are strings
+		if size, err := strconv.ParseInt(fmt.Sprint(data["size"]), 10, 64); err == nil && size > 0 {
+			s.sizes = append(s.sizes, size)
+		}
+	}
+
+	if data, err := svc.Request(ctx, "GET", "/indexer", nil); err == nil {
+		var indexers []map[string]interface{}
+		json.Unmarshal(data, &indexers)
+		for _, ix := range indexers {
+			if name, _ := ix["name"].(string); name != "" {
+				get(name, svc.Name)
+			}
+		}
+	}
+	return nil
+}
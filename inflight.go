@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// In Flight
+// ============================================================================
+
+// inFlight is the structured result of ultimarr_in_flight. Errors lists
+// services that couldn't be read, so their items are missing.
+type inFlight struct {
+	Titles []inFlightTitle `json:"titles"`
+	Errors []string        `json:"errors,omitempty"`
+}
+
+// inFlightTitle is everything in progress for one movie or series. Origin is
+// "Jellyseerr" when it was requested there, otherwise the *arr it was added
+// to directly. Missing counts aired, monitored episodes (or the movie)
+// without a file.
+type inFlightTitle struct {
+	Title       string           `json:"title"`
+	MediaType   string           `json:"mediaType"`
+	Origin      string           `json:"origin"`
+	Request     *inFlightRequest `json:"request,omitempty"`
+	Missing     int              `json:"missing"`
+	Downloading []queueItem      `json:"downloading"`
+	Imported    []importedFile   `json:"imported"`
+}
+
+type inFlightRequest struct {
+	ID          int    `json:"id"`
+	TmdbID      int    `json:"tmdbId"`
+	Status      string `json:"status"` // "pending approval" or "approved"
+	RequestedBy string `json:"requestedBy"`
+}
+
+type importedFile struct {
+	Release string    `json:"release"`
+	Date    time.Time `json:"date"`
+}
+
+// inFlightGroups collects items by title. Movies are keyed by TMDB ID and
+// series by TVDB ID, which both Jellyseerr and the *arrs know; key is e.g.
+// "tvdb:81189".
+type inFlightGroups map[string]*inFlightTitle
+
+func (g inFlightGroups) get(key, mediaType, title, origin string) *inFlightTitle {
+	t, ok := g[key]
+	if !ok {
+		t = &inFlightTitle{MediaType: mediaType, Origin: origin, Downloading: []queueItem{}, Imported: []importedFile{}}
+		g[key] = t
+	}
+	if t.Title == "" {
+		t.Title = title
+	}
+	return t
+}
+
+func handleUltimarrInFlight(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	hours := 24
+	if h, ok := args["hours"].(float64); ok && h >= 0 {
+		hours = int(h)
+	}
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+
+	groups := inFlightGroups{}
+	result := inFlight{Titles: []inFlightTitle{}}
+
+	if isConfigured(jellyseerr) {
+		if err := jellyseerrInFlight(ctx, groups); err != nil {
+			result.Errors = append(result.Errors, "Jellyseerr: "+err.Error())
+		}
+	}
+	for _, svc := range arrServices() {
+		if err := arrInFlight(ctx, svc, groups, since); err != nil {
+			result.Errors = append(result.Errors, svc.Name+": "+err.Error())
+		}
+	}
+
+	var keys []titleKey
+	for _, t := range groups {
+		if t.Title == "" && t.Request != nil {
+			keys = append(keys, titleKey{t.MediaType, t.Request.TmdbID})
+		}
+	}
+	prefetchTitles(ctx, keys)
+
+	for _, t := range groups {
+		// Requests not yet in Sonarr/Radarr have no title
+		if t.Title == "" && t.Request != nil {
+			t.Title = jellyseerrTitle(ctx, t.MediaType, t.Request.TmdbID)
+			if t.Title == "" {
+				t.Title = fmt.Sprintf("request #%d", t.Request.ID)
+			}
+		}
+		result.Titles = append(result.Titles, *t)
+	}
+	sort.Slice(result.Titles, func(i, j int) bool {
+		return strings.ToLower(result.Titles[i].Title) < strings.ToLower(result.Titles[j].Title)
+	})
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("In flight (%d titles):", len(result.Titles)))
+	for _, t := range result.Titles {
+		lines = append(lines, fmt.Sprintf("\n%s (%s, via %s)", t.Title, t.MediaType, t.Origin))
+		if r := t.Request; r != nil {
+			lines = append(lines, fmt.Sprintf("  Request #%d %s, by %s", r.ID, r.Status, r.RequestedBy))
+		}
+		if t.Missing > 0 {
+			if t.MediaType == "movie" {
+				lines = append(lines, "  Missing: no file yet")
+			} else {
+				lines = append(lines, fmt.Sprintf("  Missing: %d episodes", t.Missing))
+			}
+		}
+		for _, q := range t.Downloading {
+			lines = append(lines, fmt.Sprintf("  Downloading: %s (%s, %s left)", q.Title, q.Status, formatBytes(q.SizeLeft)))
+		}
+		for _, i := range t.Imported {
+			lines = append(lines, fmt.Sprintf("  Imported %s: %s", i.Date.Local().Format("Jan 2 15:04"), i.Release))
+		}
+	}
+	if len(result.Titles) == 0 {
+		lines = append(lines, "  Nothing pending, downloading, or recently imported.")
+	}
+	for _, e := range result.Errors {
+		lines = append(lines, "\nWarning: "+e)
+	}
+
+	return mcp.NewToolResultStructured(result, strings.Join(lines, "\n")), nil
+}
+
+// jellyseerrInFlight adds requests that are awaiting approval or approved
+// but not yet available.
+func jellyseerrInFlight(ctx context.Context, groups inFlightGroups) error {
+	statuses := map[string]string{"pending": "pending approval", "processing": "approved"}
+	for _, filter := range []string{"pending", "processing"} {
+		data, err := jellyseerrRequest(ctx, "GET", "/request?take=100&filter="+filter, nil)
+		if err != nil {
+			return err
+		}
+		var response map[string]interface{}
+		json.Unmarshal(data, &response)
+		results, _ := response["results"].([]interface{})
+
+		for _, r := range results {
+			item := r.(map[string]interface{})
+			media, _ := item["media"].(map[string]interface{})
+			mediaType, _ := media["mediaType"].(string)
+			tmdbID, _ := media["tmdbId"].(float64)
+			tvdbID, _ := media["tvdbId"].(float64)
+
+			key := fmt.Sprintf("tmdb:%d", int(tmdbID))
+			if mediaType == "tv" && tvdbID > 0 {
+				key = fmt.Sprintf("tvdb:%d", int(tvdbID))
+			}
+			t := groups.get(key, mediaType, "", "Jellyseerr")
+			t.Origin = "Jellyseerr"
+
+			user := "Unknown"
+			if rb, ok := item["requestedBy"].(map[string]interface{}); ok {
+				if dn, ok := rb["displayName"].(string); ok {
+					user = dn
+				}
+			}
+			id, _ := item["id"].(float64)
+			t.Request = &inFlightRequest{ID: int(id), TmdbID: int(tmdbID), Status: statuses[filter], RequestedBy: user}
+		}
+	}
+	return nil
+}
+
+// arrInFlight adds an *arr's missing items, queue, and imports since since.
+func arrInFlight(ctx context.Context, svc arrService, groups inFlightGroups, since time.Time) error {
+	mediaType, provider, include := "tv", "tvdb", "includeSeries=true&includeEpisode=true"
+	item := func(record map[string]interface{}) map[string]interface{} {
+		series, _ := record["series"].(map[string]interface{})
+		return series
+	}
+	if svc.Name == "Radarr" {
+		mediaType, provider, include = "movie", "tmdb", "includeMovie=true"
+		item = func(record map[string]interface{}) map[string]interface{} {
+			movie, _ := record["movie"].(map[string]interface{})
+			return movie
+		}
+	}
+	group := func(media map[string]interface{}) *inFlightTitle {
+		id, _ := media[provider+"Id"].(float64)
+		title, _ := media["title"].(string)
+		return groups.get(fmt.Sprintf("%s:%d", provider, int(id)), mediaType, title, svc.Name)
+	}
+
+	// Missing
+	if svc.Name == "Radarr" {
+		data, err := svc.Request(ctx, "GET", "/movie", nil)
+		if err != nil {
+			return err
+		}
+		var movies []map[string]interface{}
+		json.Unmarshal(data, &movies)
+		for _, m := range movies {
+			monitored, _ := m["monitored"].(bool)
+			hasFile, _ := m["hasFile"].(bool)
+			available, _ := m["isAvailable"].(bool)
+			if monitored && available && !hasFile {
+				group(m).Missing++
+			}
+		}
+	} else {
+		data, err := svc.Request(ctx, "GET", "/wanted/missing?pageSize=1000&monitored=true&includeSeries=true", nil)
+		if err != nil {
+			return err
+		}
+		var response map[string]interface{}
+		json.Unmarshal(data, &response)
+		records, _ := response["records"].([]interface{})
+		for _, r := range records {
+			episode := r.(map[string]interface{})
+			if series, ok := episode["series"].(map[string]interface{}); ok {
+				group(series).Missing++
+			}
+		}
+	}
+
+	// Queue
+	data, err := svc.Request(ctx, "GET", "/queue?pageSize=200&"+include, nil)
+	if err != nil {
+		return err
+	}
+	var queue map[string]interface{}
+	json.Unmarshal(data, &queue)
+	records, _ := queue["records"].([]interface{})
+	for _, r := range records {
+		record := r.(map[string]interface{})
+		media := item(record)
+		if media == nil {
+			continue
+		}
+		title, _ := record["title"].(string)
+		status, _ := record["status"].(string)
+		sizeLeft, _ := record["sizeleft"].(float64)
+		t := group(media)
+		t.Downloading = append(t.Downloading, queueItem{Title: title, Status: status, SizeLeft: int64(sizeLeft)})
+	}
+
+	// Recent imports (event type 3 is downloadFolderImported in both)
+	params := url.Values{"date": {since.UTC().Format(time.RFC3339)}, "eventType": {"3"}}
+	data, err = svc.Request(ctx, "GET", "/history/since?"+params.Encode()+"&"+include, nil)
+	if err != nil {
+		return err
+	}
+	var history []map[string]interface{}
+	json.Unmarshal(data, &history)
+	for _, h := range history {
+		media := item(h)
+		if media == nil {
+			continue
+		}
+		release, _ := h["sourceTitle"].(string)
+		date, _ := h["date"].(string)
+		imported, _ := time.Parse(time.RFC3339, date)
+		t := group(media)
+		t.Imported = append(t.Imported, importedFile{Release: release, Date: imported})
+	}
+
+	return nil
+}
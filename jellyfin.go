@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ============================================================================
+// Jellyfin
+// ============================================================================
+
+func jellyfinRequest(ctx context.Context, method, endpoint string, body io.Reader) ([]byte, error) {
+	if jellyfin.baseURL == "" {
+		return nil, fmt.Errorf("Jellyfin is not configured; set JELLYFIN_URL and JELLYFIN_API_KEY")
+	}
+	headers := map[string]string{
+		"Authorization": fmt.Sprintf(`MediaBrowser Token="%s"`, jellyfin.apiKey),
+		"Content-Type":  "application/json",
+	}
+	return doRequest(ctx, method, jellyfin.baseURL+endpoint, headers, body)
+}
+
+type jellyfinService struct {
+	baseURL string
+	apiKey  string
+}
+
+var jellyfin = &jellyfinService{}
+
+func (*jellyfinService) Name() string  { return "Jellyfin" }
+func (j *jellyfinService) URL() string { return j.baseURL }
+
+func (j *jellyfinService) Configure() bool {
+	j.baseURL = strings.TrimSuffix(os.Getenv("JELLYFIN_URL"), "/")
+	j.apiKey = os.Getenv("JELLYFIN_API_KEY")
+	return j.baseURL != ""
+}
+
+func (*jellyfinService) RegisterTools(s *server.MCPServer) { registerJellyfinTools(s) }
+
+func (*jellyfinService) HealthCheck(ctx context.Context) (string, error) {
+	return versionField(jellyfinRequest(ctx, "GET", "/System/Info", nil))
+}
+
+func registerJellyfinTools(s *server.MCPServer) {
+	// Continue Watching
+	s.AddTool(
+		mcp.NewTool("jellyfin_continue_watching",
+			mcp.WithDescription("Show each Jellyfin user's continue-watching (partially played) and next-up items, e.g. to combine \"you're 3 episodes into season 2\" with what has just downloaded"),
+			mcp.WithString("user", mcp.Description("Jellyfin username (omit for all users)")),
+			mcp.WithNumber("limit", mcp.Description("Items per list per user (default 10)")),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[watchingReport](),
+		),
+		handleJellyfinContinueWatching,
+	)
+}
+
+// jellyfinUsers returns Jellyfin users as ID/name pairs, optionally only the
+// one matching name (case-insensitive).
+func jellyfinUsers(ctx context.Context, name string) ([]map[string]interface{}, error) {
+	data, err := jellyfinRequest(ctx, "GET", "/Users", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []map[string]interface{}
+	json.Unmarshal(data, &users)
+
+	if name == "" {
+		return users, nil
+	}
+	for _, u := range users {
+		if n, _ := u["Name"].(string); strings.EqualFold(n, name) {
+			return []map[string]interface{}{u}, nil
+		}
+	}
+	return nil, fmt.Errorf("no Jellyfin user named %q", name)
+}
+
+// jellyfinItemLabel renders an item as "Show S02E04 - Title" or "Movie (Year)".
+func jellyfinItemLabel(item map[string]interface{}) string {
+	name, _ := item["Name"].(string)
+	if series, ok := item["SeriesName"].(string); ok && series != "" {
+		season, _ := item["ParentIndexNumber"].(float64)
+		episode, _ := item["IndexNumber"].(float64)
+		return fmt.Sprintf("%s S%02dE%02d - %s", series, int(season), int(episode), name)
+	}
+	if year, ok := item["ProductionYear"].(float64); ok {
+		return fmt.Sprintf("%s (%d)", name, int(year))
+	}
+	return name
+}
+
+// watchingReport is the structured result of jellyfin_continue_watching.
+type watchingReport struct {
+	Users []userWatching `json:"users"`
+}
+
+type userWatching struct {
+	User   string         `json:"user"`
+	Resume []resumingItem `json:"resume"`
+	NextUp []string       `json:"nextUp"`
+}
+
+// resumingItem is a partially played item, labeled as by jellyfinItemLabel.
+type resumingItem struct {
+	Item             string `json:"item"`
+	PlayedPercentage int    `json:"playedPercentage"`
+}
+
+func handleJellyfinContinueWatching(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	name, _ := args["user"].(string)
+	limit := 10
+	if l, ok := args["limit"].(float64); ok {
+		limit = int(l)
+	}
+
+	users, err := jellyfinUsers(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	report := watchingReport{Users: []userWatching{}}
+	var lines []string
+	for _, u := range users {
+		userID, _ := u["Id"].(string)
+		userName, _ := u["Name"].(string)
+
+		params := url.Values{
+			"Limit":      {fmt.Sprint(limit)},
+			"MediaTypes": {"Video"},
+			"Fields":     {"ProductionYear"},
+		}
+		data, err := jellyfinRequest(ctx, "GET", fmt.Sprintf("/Users/%s/Items/Resume?%s", userID, params.Encode()), nil)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		var resume map[string]interface{}
+		json.Unmarshal(data, &resume)
+		resumeItems, _ := resume["Items"].([]interface{})
+
+		params = url.Values{
+			"userId": {userID},
+			"Limit":  {fmt.Sprint(limit)},
+		}
+		data, err = jellyfinRequest(ctx, "GET", "/Shows/NextUp?"+params.Encode(), nil)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		var nextUp map[string]interface{}
+		json.Unmarshal(data, &nextUp)
+		nextItems, _ := nextUp["Items"].([]interface{})
+
+		if len(resumeItems) == 0 && len(nextItems) == 0 && name == "" {
+			continue
+		}
+
+		watching := userWatching{User: userName, Resume: []resumingItem{}, NextUp: []string{}}
+		lines = append(lines, fmt.Sprintf("%s:", userName))
+		lines = append(lines, "  Continue watching:")
+		for _, r := range resumeItems {
+			item := r.(map[string]interface{})
+			resuming := resumingItem{Item: jellyfinItemLabel(item)}
+			progress := ""
+			if ud, ok := item["UserData"].(map[string]interface{}); ok {
+				if pct, ok := ud["PlayedPercentage"].(float64); ok {
+					progress = fmt.Sprintf(" (%d%% watched)", int(pct))
+					resuming.PlayedPercentage = int(pct)
+				}
+			}
+			lines = append(lines, "    "+resuming.Item+progress)
+			watching.Resume = append(watching.Resume, resuming)
+		}
+		if len(resumeItems) == 0 {
+			lines = append(lines, "    (none)")
+		}
+
+		lines = append(lines, "  Next up:")
+		for _, n := range nextItems {
+			label := jellyfinItemLabel(n.(map[string]interface{}))
+			lines = append(lines, "    "+label)
+			watching.NextUp = append(watching.NextUp, label)
+		}
+		if len(nextItems) == 0 {
+			lines = append(lines, "    (none)")
+		}
+		lines = append(lines, "")
+		report.Users = append(report.Users, watching)
+	}
+
+	if len(lines) == 0 {
+		return mcp.NewToolResultStructured(report, "Nobody has anything in progress."), nil
+	}
+	return mcp.NewToolResultStructured(report, strings.TrimRight(strings.Join(lines, "\n"), "\n")), nil
+}
+
+// jellyfinLastPlayed returns when any Jellyfin user last played each movie
+// (mediaType "movie", keyed by TMDB ID) or any episode of each series
+// (mediaType "tv", keyed by TVDB ID). Titles in Jellyfin that were never
+// played have the zero time; titles Jellyfin doesn't have are left out.
+func jellyfinLastPlayed(ctx context.Context, mediaType string) (map[string]time.Time, error) {
+	users, err := jellyfinUsers(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	lastPlayed := map[string]time.Time{}
+	record := func(key string, played string) {
+		if key == "" {
+			return
+		}
+		// Unplayed titles parse to the zero time
+		t, _ := time.Parse(time.RFC3339, played)
+		if last, ok := lastPlayed[key]; !ok || t.After(last) {
+			lastPlayed[key] = t
+		}
+	}
+
+	for _, u := range users {
+		userID, _ := u["Id"].(string)
+
+		if mediaType == "movie" {
+			items, err := jellyfinItems(ctx, userID, url.Values{"IncludeItemTypes": {"Movie"}, "Fields": {"ProviderIds"}})
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				ud, _ := item["UserData"].(map[string]interface{})
+				played, _ := ud["LastPlayedDate"].(string)
+				record(jellyfinProviderID(item, "Tmdb"), played)
+			}
+			continue
+		}
+
+		// Episodes carry play dates; series carry the TVDB IDs
+		series, err := jellyfinItems(ctx, userID, url.Values{"IncludeItemTypes": {"Series"}, "Fields": {"ProviderIds"}})
+		if err != nil {
+			return nil, err
+		}
+		tvdbBySeries := map[string]string{}
+		for _, s := range series {
+			id, _ := s["Id"].(string)
+			tvdbBySeries[id] = jellyfinProviderID(s, "Tvdb")
+			record(tvdbBySeries[id], "")
+		}
+
+		episodes, err := jellyfinItems(ctx, userID, url.Values{"IncludeItemTypes": {"Episode"}, "Filters": {"IsPlayed"}})
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range episodes {
+			seriesID, _ := e["SeriesId"].(string)
+			ud, _ := e["UserData"].(map[string]interface{})
+			played, _ := ud["LastPlayedDate"].(string)
+			record(tvdbBySeries[seriesID], played)
+		}
+	}
+
+	return lastPlayed, nil
+}
+
+// jellyfinLibrary returns the movies and series in any Jellyfin user's
+// library, keyed like "movie:tmdb:603", "tv:tvdb:81189", and "tv:tmdb:1396".
+func jellyfinLibrary(ctx context.Context) (map[string]bool, error) {
+	users, err := jellyfinUsers(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	library := map[string]bool{}
+	for _, u := range users {
+		userID, _ := u["Id"].(string)
+		items, err := jellyfinItems(ctx, userID, url.Values{"IncludeItemTypes": {"Movie,Series"}, "Fields": {"ProviderIds"}})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			mediaType := "movie"
+			if item["Type"] == "Series" {
+				mediaType = "tv"
+			}
+			for _, provider := range []string{"Tmdb", "Tvdb"} {
+				if id := jellyfinProviderID(item, provider); id != "" {
+					library[mediaType+":"+strings.ToLower(provider)+":"+id] = true
+				}
+			}
+		}
+	}
+	return library, nil
+}
+
+// jellyfinItems lists a user's library items matching params, recursively.
+func jellyfinItems(ctx context.Context, userID string, params url.Values) ([]map[string]interface{}, error) {
+	params.Set("Recursive", "true")
+	data, err := jellyfinRequest(ctx, "GET", fmt.Sprintf("/Users/%s/Items?%s", userID, params.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Items []map[string]interface{}
+	}
+	json.Unmarshal(data, &result)
+	return result.Items, nil
+}
+
+// jellyfinProviderID returns an item's external ID for provider (e.g. "Tmdb").
+func jellyfinProviderID(item map[string]interface{}, provider string) string {
+	ids, _ := item["ProviderIds"].(map[string]interface{})
+	for k, v := range ids {
+		if strings.EqualFold(k, provider) {
+			s, _ := v.(string)
+			return s
+		}
+	}
+	return ""
+}
@@ -1,62 +1,274 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
+	"unicode"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
-// Config holds all service configurations
+// Config holds the settings that aren't specific to one service; each
+// Service keeps its own, see services.go
 type Config struct {
-	JellyseerrURL    string
-	JellyseerrAPIKey string
-	SonarrURL        string
-	SonarrAPIKey     string
-	RadarrURL        string
-	RadarrAPIKey     string
+	// Register admin-only tools such as restart/shutdown
+	AdminTools bool
+
+	QuietHours      *quietWindow
+	QuietHoursLimit int
+
+	// Quality profiles and tags to request for each preferred audio language
+	AudioLanguages map[string]languageProfile
+
+	// Settings used when a request doesn't specify them, keyed by media kind
+	Defaults map[string]mediaDefaults
+
+	// What each user's playback clients handle without transcoding
+	ClientProfiles map[string]clientProfile
+
+	// Rules for what the library no longer needs to keep
+	Retention []retentionPolicy
+
+	// Highest content rating to allow, what to do above it ("flag" or
+	// "block"), the country whose ratings are used, and whether blocking
+	// also refuses titles with no rating that can be compared
+	MaxRating     string
+	RatingAction  string
+	RatingCountry string
+	BlockUnrated  bool
+
+	// Keep library snapshots, titles, and history checkpoints on disk, and
+	// how long a library snapshot is reused
+	DiskCache   bool
+	CacheMaxAge time.Duration
+
+	// Serve MCP over HTTP on this address instead of stdio, the users allowed
+	// to, by bearer token, and the quotas for each role
+	HTTPAddr string
+	Users    map[string]apiUser
+	Quotas   map[string]quota
+
+	DataDir       string
+	PollInterval  time.Duration
+	ToolTimeout   time.Duration
+	WebhookAddr   string
+	WebhookSecret string
+	Notify        bool
 }
 
+// languageProfile is the set of *arr settings that selects releases with a
+// particular audio language, e.g. a "French VF" quality profile and tag.
+type languageProfile struct {
+	MovieProfileID int   `json:"movieProfileId"`
+	TVProfileID    int   `json:"tvProfileId"`
+	Tags           []int `json:"tags"`
+}
+
+// mediaDefaults are the settings used for one kind of media ("movie", "tv",
+// "anime", "movie4k" or "tv4k") when a tool call doesn't give them.
+type mediaDefaults struct {
+	ServerID   *int   `json:"serverId"`
+	ProfileID  int    `json:"profileId"`
+	RootFolder string `json:"rootFolder"`
+	Tags       []int  `json:"tags"`
+}
+
+// Media kinds that ULTIMARR_DEFAULTS may configure
+var mediaKinds = []string{"movie", "tv", "anime", "movie4k", "tv4k"}
+
 var config Config
 
 func main() {
+	// A profile fills in settings the environment doesn't give
+	if path := os.Getenv("ULTIMARR_PROFILE"); path != "" {
+		if err := loadProfile(path); err != nil {
+			log.Fatalf("Invalid ULTIMARR_PROFILE: %v", err)
+		}
+	}
+
 	// Load config from environment
 	config = Config{
-		JellyseerrURL:    getEnv("JELLYSEERR_URL", "http://localhost:5055"),
-		JellyseerrAPIKey: os.Getenv("JELLYSEERR_API_KEY"),
-		SonarrURL:        getEnv("SONARR_URL", "http://localhost:8989"),
-		SonarrAPIKey:     os.Getenv("SONARR_API_KEY"),
-		RadarrURL:        getEnv("RADARR_URL", "http://localhost:7878"),
-		RadarrAPIKey:     os.Getenv("RADARR_API_KEY"),
+		// Service URLs and credentials are read by each Service's Configure
+		AdminTools: getEnvBool("ULTIMARR_ADMIN_TOOLS", false),
+
+		QuietHoursLimit: getEnvInt("ULTIMARR_QUIET_HOURS_LIMIT", 0),
+
+		MaxRating:     os.Getenv("ULTIMARR_MAX_RATING"),
+		RatingAction:  getEnv("ULTIMARR_RATING_ACTION", ratingFlag),
+		RatingCountry: strings.ToUpper(getEnv("ULTIMARR_RATING_COUNTRY", "US")),
+		BlockUnrated:  getEnvBool("ULTIMARR_BLOCK_UNRATED", false),
+
+		DiskCache:   getEnvBool("ULTIMARR_DISK_CACHE", false),
+		CacheMaxAge: getEnvDuration("ULTIMARR_CACHE_MAX_AGE", 10*time.Minute),
+
+		HTTPAddr: os.Getenv("ULTIMARR_HTTP_ADDR"),
+
+		DataDir:       getEnv("ULTIMARR_DATA_DIR", defaultDataDir()),
+		PollInterval:  getEnvDuration("ULTIMARR_POLL_INTERVAL", 5*time.Minute),
+		ToolTimeout:   getEnvDuration("ULTIMARR_TOOL_TIMEOUT", 20*time.Second),
+		WebhookAddr:   os.Getenv("ULTIMARR_WEBHOOK_ADDR"),
+		WebhookSecret: os.Getenv("ULTIMARR_WEBHOOK_SECRET"),
+		Notify:        getEnvBool("ULTIMARR_NOTIFY", false),
+	}
+
+	if v := os.Getenv("ULTIMARR_QUIET_HOURS"); v != "" {
+		w, err := parseQuietHours(v)
+		if err != nil {
+			log.Fatalf("Invalid ULTIMARR_QUIET_HOURS: %v", err)
+		}
+		config.QuietHours = w
+	}
+
+	if v := os.Getenv("ULTIMARR_AUDIO_LANGUAGES"); v != "" {
+		if err := json.Unmarshal([]byte(v), &config.AudioLanguages); err != nil {
+			log.Fatalf("Invalid ULTIMARR_AUDIO_LANGUAGES: %v", err)
+		}
+	}
+
+	if v := os.Getenv("ULTIMARR_DEFAULTS"); v != "" {
+		if err := json.Unmarshal([]byte(v), &config.Defaults); err != nil {
+			log.Fatalf("Invalid ULTIMARR_DEFAULTS: %v", err)
+		}
+	kinds:
+		for kind := range config.Defaults {
+			for _, k := range mediaKinds {
+				if k == kind {
+					continue kinds
+				}
+			}
+			log.Fatalf("Invalid ULTIMARR_DEFAULTS: unknown media kind %q (use %s)", kind, strings.Join(mediaKinds, ", "))
+		}
+	}
+
+	if v := os.Getenv("ULTIMARR_CLIENT_PROFILES"); v != "" {
+		if err := json.Unmarshal([]byte(v), &config.ClientProfiles); err != nil {
+			log.Fatalf("Invalid ULTIMARR_CLIENT_PROFILES: %v", err)
+		}
+	}
+
+	if v := os.Getenv("ULTIMARR_RETENTION"); v != "" {
+		if err := json.Unmarshal([]byte(v), &config.Retention); err != nil {
+			log.Fatalf("Invalid ULTIMARR_RETENTION: %v", err)
+		}
+		if err := validateRetention(config.Retention); err != nil {
+			log.Fatalf("Invalid ULTIMARR_RETENTION: %v", err)
+		}
+	}
+
+	if v := os.Getenv("ULTIMARR_USERS"); v != "" {
+		if err := json.Unmarshal([]byte(v), &config.Users); err != nil {
+			log.Fatalf("Invalid ULTIMARR_USERS: %v", err)
+		}
+	}
+
+	if v := os.Getenv("ULTIMARR_QUOTAS"); v != "" {
+		if err := json.Unmarshal([]byte(v), &config.Quotas); err != nil {
+			log.Fatalf("Invalid ULTIMARR_QUOTAS: %v", err)
+		}
+	}
+
+	if config.MaxRating != "" {
+		if err := validateMaxRating(); err != nil {
+			log.Fatalf("Invalid ULTIMARR_MAX_RATING: %v", err)
+		}
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "export-config" {
+		exportConfigCommand()
+		return
+	}
+
+	// Anyone who can reach the listener could otherwise mark media available
+	if config.WebhookAddr != "" && config.WebhookSecret == "" {
+		log.Fatalf("ULTIMARR_WEBHOOK_ADDR is set but ULTIMARR_WEBHOOK_SECRET isn't; set a secret for Jellyseerr to send in the Authorization header")
+	}
+
+	if os.Getenv("ULTIMARR_MODE") == "demo" {
+		enableDemoMode()
 	}
 
 	s := server.NewMCPServer(
 		"ultimarr",
 		"1.0.0",
 		server.WithToolCapabilities(true),
-		server.WithInstructions("MCP server for the *arr stack - control Jellyseerr, Sonarr, and Radarr. Use jellyseerr_* tools to search and request media, sonarr_* tools to manage TV series, and radarr_* tools to manage movies."),
+		server.WithLogging(),
+		server.WithToolHandlerMiddleware(withToolDeadline),
+		server.WithToolHandlerMiddleware(withToolQuota),
+		server.WithToolHandlerMiddleware(withRecoveryHint),
+		server.WithInstructions("MCP server for the *arr stack - control Jellyseerr, Sonarr, and Radarr. Use jellyseerr_* tools to search and request media, sonarr_* tools to manage TV series, radarr_* tools to manage movies, and jellyfin_* tools to see what people are watching. At the start of a conversation, call jellyseerr_my_reminders to tell the user about anything that has become available since they last asked."),
 	)
 
-	// Register Jellyseerr tools
-	registerJellyseerrTools(s)
+	// Configure each service and register its tools
+	configureServices(s)
+
+	// Register cross-service diagnostic tools
+	registerDiagnosticTools(s)
+
+	// Register download client tools
+	registerDownloadClientTools(s)
 
-	// Register Sonarr tools
-	registerSonarrTools(s)
+	// Register remote path mapping tools
+	registerPathMappingTools(s)
 
-	// Register Radarr tools
-	registerRadarrTools(s)
+	// Register upgrade campaign tools
+	registerCampaignTools(s)
+
+	if config.DiskCache {
+		loadDiskCache()
+	}
+
+	// Load reminders and start watching for request status changes
+	if err := loadReminders(); err != nil {
+		log.Printf("Reminders: %v", err)
+	}
+	subscribe(handleReminderEvent)
+	if err := loadCampaigns(); err != nil {
+		log.Printf("Upgrade campaigns: %v", err)
+	}
+	startBandwidthSampling()
+	if config.Notify {
+		startCompletionNotifications(s)
+	}
+	startEventSources()
+
+	// Save what's only kept in memory when the server is stopped or the
+	// client goes away
+	var shutdown sync.Once
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+		shutdown.Do(saveDiskCache)
+		os.Exit(0)
+	}()
 
 	// Start server
-	if err := server.ServeStdio(s); err != nil {
+	var err error
+	if config.HTTPAddr != "" {
+		err = serveHTTP(s, config.HTTPAddr)
+	} else {
+		err = server.ServeStdio(s)
+	}
+	shutdown.Do(saveDiskCache)
+	if err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
@@ -68,14 +280,72 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %s", key, v, fallback)
+		return fallback
+	}
+	return d
+}
+
+func getEnvInt(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %d", key, v, fallback)
+		return fallback
+	}
+	return n
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %v", key, v, fallback)
+		return fallback
+	}
+	return b
+}
+
+func defaultDataDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ".ultimarr"
+	}
+	return filepath.Join(dir, "ultimarr")
+}
+
 // ============================================================================
 // HTTP Client Helpers
 // ============================================================================
 
-func doRequest(method, urlStr string, headers map[string]string, body io.Reader) ([]byte, error) {
-	client := &http.Client{Timeout: 30 * time.Second}
+// Timeout for ordinary API calls
+const requestTimeout = 30 * time.Second
+
+// Transport for all backend calls; demo mode swaps in canned responses
+var httpTransport http.RoundTripper = http.DefaultTransport
+
+func doRequest(ctx context.Context, method, urlStr string, headers map[string]string, body io.Reader) ([]byte, error) {
+	return doRequestTimeout(ctx, method, urlStr, headers, body, requestTimeout)
+}
+
+func doRequestTimeout(ctx context.Context, method, urlStr string, headers map[string]string, body io.Reader, timeout time.Duration) ([]byte, error) {
+	client := &http.Client{Timeout: timeout, Transport: httpTransport}
+	defer trackBackendCall(ctx, method, urlStr)()
 
-	req, err := http.NewRequest(method, urlStr, body)
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, body)
 	if err != nil {
 		return nil, err
 	}
@@ -96,12 +366,123 @@ func doRequest(method, urlStr string, headers map[string]string, body io.Reader)
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(data[:min(200, len(data))]))
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Body: string(data)}
 	}
 
 	return data, nil
 }
 
+// HTTPError is returned by doRequest when a service responds with an error status.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body[:min(200, len(e.Body))])
+}
+
+// apiKeyPair holds a service's API key and an optional alternate used while
+// keys are being rotated. Whichever key last succeeded is tried first.
+type apiKeyPair struct {
+	mu        sync.Mutex
+	service   string
+	primary   string
+	alternate string
+}
+
+func newAPIKeyPair(service, primary, alternate string) *apiKeyPair {
+	return &apiKeyPair{service: service, primary: primary, alternate: alternate}
+}
+
+func (k *apiKeyPair) get() (string, string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.primary, k.alternate
+}
+
+func (k *apiKeyPair) promote(key string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.alternate == key {
+		k.primary, k.alternate = k.alternate, k.primary
+		log.Printf("%s rejected the current API key, switched to the alternate key", k.service)
+	}
+}
+
+// doAPIKeyRequest sends a request authenticated with X-Api-Key, retrying with
+// the alternate key if the service answers 401.
+func doAPIKeyRequest(ctx context.Context, method, urlStr string, keys *apiKeyPair, body io.Reader) ([]byte, error) {
+	return doAPIKeyRequestTimeout(ctx, method, urlStr, keys, body, requestTimeout)
+}
+
+func doAPIKeyRequestTimeout(ctx context.Context, method, urlStr string, keys *apiKeyPair, body io.Reader, timeout time.Duration) ([]byte, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = io.ReadAll(body); err != nil {
+			return nil, err
+		}
+	}
+
+	send := func(key string) ([]byte, error) {
+		headers := map[string]string{
+			"X-Api-Key":    key,
+			"Content-Type": "application/json",
+		}
+		var r io.Reader
+		if payload != nil {
+			r = bytes.NewReader(payload)
+		}
+		return doRequestTimeout(ctx, method, urlStr, headers, r, timeout)
+	}
+
+	primary, alternate := keys.get()
+	data, err := send(primary)
+
+	var httpErr *HTTPError
+	if alternate == "" || !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		return data, err
+	}
+
+	data, err = send(alternate)
+	if err == nil {
+		keys.promote(alternate)
+	}
+	return data, err
+}
+
+// formatBytes renders a byte count in human-readable binary units.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// parseTimeLeft parses an *arr "timeleft" value such as "01:02:03" or "1.02:03:04".
+func parseTimeLeft(v string) (time.Duration, bool) {
+	days := 0
+	if i := strings.Index(v, "."); i >= 0 && i < strings.Index(v, ":") {
+		d, err := strconv.Atoi(v[:i])
+		if err != nil {
+			return 0, false
+		}
+		days, v = d, v[i+1:]
+	}
+	var h, m, sec int
+	if _, err := fmt.Sscanf(v, "%d:%d:%d", &h, &m, &sec); err != nil {
+		return 0, false
+	}
+	return time.Duration(days)*24*time.Hour + time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second, true
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -113,174 +494,1655 @@ func min(a, b int) int {
 // Jellyseerr
 // ============================================================================
 
-func jellyseerrRequest(method, endpoint string, body io.Reader) ([]byte, error) {
-	headers := map[string]string{
-		"X-Api-Key":    config.JellyseerrAPIKey,
-		"Content-Type": "application/json",
+func jellyseerrRequest(ctx context.Context, method, endpoint string, body io.Reader) ([]byte, error) {
+	urlStr := jellyseerr.baseURL + "/api/v1" + endpoint
+	if jellyseerr.email != "" && jellyseerr.password != "" {
+		return jellyseerrSessionRequest(ctx, method, urlStr, body)
+	}
+	return doAPIKeyRequest(ctx, method, urlStr, jellyseerr.keys, body)
+}
+
+// Setups where a proxy in front of Jellyseerr strips or blocks the API key
+// header can sign in with a user's email and password instead, like the web
+// UI does, and send the session cookie.
+
+var (
+	jellyseerrMu            sync.Mutex
+	jellyseerrSession       string // connect.sid cookie value
+	jellyseerrSessionExpiry time.Time
+	jellyseerrFallbackOnce  sync.Once
+	jellyseerrLoginErr      error // last failed sign-in, retried after jellyseerrLoginBackoff
+	jellyseerrLoginFailedAt time.Time
+)
+
+// jellyseerrLoginBackoff is how long a failed sign-in is remembered before
+// trying again, so that a wrong password doesn't mean a login on every call.
+const jellyseerrLoginBackoff = 5 * time.Minute
+
+// jellyseerrLogin signs in with JELLYSEERR_EMAIL and JELLYSEERR_PASSWORD and
+// stores the session cookie. Local accounts are tried first, then Jellyfin
+// accounts, which sign in with their Jellyfin username. Callers hold
+// jellyseerrMu.
+func jellyseerrLogin(ctx context.Context) error {
+	attempts := []struct {
+		endpoint string
+		body     map[string]string
+	}{
+		{"/auth/local", map[string]string{"email": jellyseerr.email, "password": jellyseerr.password}},
+		{"/auth/jellyfin", map[string]string{"username": jellyseerr.email, "password": jellyseerr.password}},
+	}
+
+	client := &http.Client{Timeout: requestTimeout, Transport: httpTransport}
+	status := 0
+	for _, a := range attempts {
+		body, _ := json.Marshal(a.body)
+		req, err := http.NewRequestWithContext(ctx, "POST", jellyseerr.baseURL+"/api/v1"+a.endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		status = resp.StatusCode
+		if status >= 400 {
+			continue
+		}
+		for _, c := range resp.Cookies() {
+			if c.Name == "connect.sid" {
+				jellyseerrSession = c.Value
+				jellyseerrSessionExpiry = c.Expires
+				return nil
+			}
+		}
+	}
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		return &authError{fmt.Sprintf("Jellyseerr login failed (HTTP %d); check JELLYSEERR_EMAIL and JELLYSEERR_PASSWORD", status)}
+	}
+	return fmt.Errorf("Jellyseerr login failed (HTTP %d); password sign-in may be disabled in Jellyseerr's settings", status)
+}
+
+// jellyseerrSessionRequest sends a request with the session cookie, signing
+// in first if there is no session or it has expired, and again if Jellyseerr
+// no longer accepts it. If signing in fails and there is also an API key, the
+// request is sent with the key instead until jellyseerrLoginBackoff passes.
+func jellyseerrSessionRequest(ctx context.Context, method, urlStr string, body io.Reader) ([]byte, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = io.ReadAll(body); err != nil {
+			return nil, err
+		}
+	}
+
+	session := func(stale string) (string, error) {
+		jellyseerrMu.Lock()
+		defer jellyseerrMu.Unlock()
+		expired := !jellyseerrSessionExpiry.IsZero() && time.Now().After(jellyseerrSessionExpiry)
+		// Another request may have signed in again already
+		if jellyseerrSession == "" || expired || jellyseerrSession == stale {
+			if jellyseerrLoginErr != nil && time.Since(jellyseerrLoginFailedAt) < jellyseerrLoginBackoff {
+				return "", jellyseerrLoginErr
+			}
+			if err := jellyseerrLogin(ctx); err != nil {
+				// A cancelled call says nothing about the credentials
+				if ctx.Err() == nil {
+					jellyseerrLoginErr, jellyseerrLoginFailedAt = err, time.Now()
+				}
+				return "", err
+			}
+			jellyseerrLoginErr = nil
+		}
+		return jellyseerrSession, nil
+	}
+	send := func(sid string) ([]byte, error) {
+		headers := map[string]string{
+			"Cookie":       "connect.sid=" + sid,
+			"Content-Type": "application/json",
+		}
+		var r io.Reader
+		if payload != nil {
+			r = bytes.NewReader(payload)
+		}
+		return doRequest(ctx, method, urlStr, headers, r)
+	}
+
+	withKey := func(err error) ([]byte, error) {
+		if jellyseerr.apiKey == "" {
+			return nil, err
+		}
+		jellyseerrFallbackOnce.Do(func() {
+			log.Printf("Jellyseerr: %v; using JELLYSEERR_API_KEY instead", err)
+		})
+		var r io.Reader
+		if payload != nil {
+			r = bytes.NewReader(payload)
+		}
+		return doAPIKeyRequest(ctx, method, urlStr, jellyseerr.keys, r)
+	}
+
+	sid, err := session("")
+	if err != nil {
+		return withKey(err)
+	}
+	data, err := send(sid)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return data, err
+	}
+	switch httpErr.StatusCode {
+	case http.StatusUnauthorized:
+		// Session rejected; sign in again below
+	case http.StatusForbidden:
+		// Jellyseerr answers 403 both without a valid session and when the
+		// user lacks a permission; only the first is fixed by signing in
+		if jellyseerrSessionValid(ctx, sid) {
+			return data, err
+		}
+	default:
+		return data, err
+	}
+	if sid, err = session(sid); err != nil {
+		return withKey(err)
+	}
+	return send(sid)
+}
+
+// jellyseerrSessionValid reports whether Jellyseerr still accepts sid, by
+// asking who it belongs to.
+func jellyseerrSessionValid(ctx context.Context, sid string) bool {
+	_, err := doRequest(ctx, "GET", jellyseerr.baseURL+"/api/v1/auth/me", map[string]string{"Cookie": "connect.sid=" + sid}, nil)
+	var httpErr *HTTPError
+	return !errors.As(err, &httpErr) || (httpErr.StatusCode != http.StatusUnauthorized && httpErr.StatusCode != http.StatusForbidden)
+}
+
+type jellyseerrService struct {
+	baseURL string
+	apiKey  string
+	keys    *apiKeyPair
+
+	// Login used instead of the API key when both are set
+	email    string
+	password string
+}
+
+var jellyseerr = &jellyseerrService{}
+
+func (*jellyseerrService) Name() string  { return "Jellyseerr" }
+func (j *jellyseerrService) URL() string { return j.baseURL }
+
+func (j *jellyseerrService) Configure() bool {
+	j.baseURL = getEnv("JELLYSEERR_URL", "http://localhost:5055")
+	j.apiKey = os.Getenv("JELLYSEERR_API_KEY")
+	j.email = os.Getenv("JELLYSEERR_EMAIL")
+	j.password = os.Getenv("JELLYSEERR_PASSWORD")
+	j.keys = newAPIKeyPair("Jellyseerr", j.apiKey, os.Getenv("JELLYSEERR_API_KEY_ALT"))
+	if !jellyseerrConfigured() {
+		return false
+	}
+	loadJellyseerrAccount(context.Background())
+	return true
+}
+
+// jellyseerrConfigured reports whether there is an API key or a login to
+// reach Jellyseerr with.
+func jellyseerrConfigured() bool {
+	return jellyseerr.apiKey != "" || (jellyseerr.email != "" && jellyseerr.password != "")
+}
+
+func (*jellyseerrService) RegisterTools(s *server.MCPServer) { registerJellyseerrTools(s) }
+
+func (*jellyseerrService) Status(ctx context.Context, st *serviceStatus) { jellyseerrStatus(ctx, st) }
+
+func (*jellyseerrService) HealthCheck(ctx context.Context) (string, error) {
+	// /status answers without a key, so check the credentials separately
+	if _, err := jellyseerrRequest(ctx, "GET", "/auth/me", nil); err != nil {
+		return "", err
 	}
-	return doRequest(method, config.JellyseerrURL+"/api/v1"+endpoint, headers, body)
+	return versionField(jellyseerrRequest(ctx, "GET", "/status", nil))
 }
 
 func registerJellyseerrTools(s *server.MCPServer) {
 	// Search
 	s.AddTool(
 		mcp.NewTool("jellyseerr_search",
-			mcp.WithDescription("Search for movies and TV shows on Jellyseerr"),
+			mcp.WithDescription("Search for movies and TV shows on Jellyseerr, with each title's age rating and content warnings. If nothing matches, the search is retried with normalized punctuation/accents and without a trailing year; same-titled results show their country and language."),
 			mcp.WithString("query", mcp.Required(), mcp.Description("Search query")),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[searchResult](),
 		),
 		handleJellyseerrSearch,
 	)
 
-	// Request Media
+	// Discover
 	s.AddTool(
-		mcp.NewTool("jellyseerr_request",
-			mcp.WithDescription("Request a movie or TV show on Jellyseerr"),
-			mcp.WithNumber("tmdb_id", mcp.Required(), mcp.Description("TMDB ID of the media")),
-			mcp.WithString("media_type", mcp.Required(), mcp.Description("Type: 'movie' or 'tv'")),
+		mcp.NewTool("jellyseerr_discover",
+			mcp.WithDescription("Browse Jellyseerr's curated discover categories the same way the web UI does, with whether each title is already available or requested. Use 'movie_genres', 'tv_genres', 'studios', or 'networks' to list the IDs available for the genre, studio, and network categories; 'studio' and 'network' also take a name (e.g. \"which A24 movies are we missing?\")."),
+			mcp.WithString("category", mcp.Required(), mcp.Enum(discoverCategoryNames()...), mcp.Description("Discover category")),
+			mcp.WithNumber("id", mcp.Description("Genre, studio, or network ID (required for movie_genre and tv_genre, and for studio and network unless name is given)")),
+			mcp.WithString("name", mcp.Description("Studio or network name for the studio and network categories (e.g. 'A24', 'HBO'); studios not featured on the discover page are looked up by name")),
+			mcp.WithBoolean("missing_only", mcp.Description("Only list titles that aren't available yet (default false)")),
+			mcp.WithNumber("page", mcp.Description("Page number (default 1)")),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[discoverResult](),
 		),
-		handleJellyseerrRequest,
+		handleJellyseerrDiscover,
 	)
 
+	// Request Media, with only the options the API key's user may use
+	canMovies := jellyseerrCan("request", "request_movie")
+	canTV := jellyseerrCan("request", "request_tv")
+	if canMovies || canTV {
+		description := "Request a movie or TV show on Jellyseerr"
+		mediaTypes := []string{"movie", "tv"}
+		if !canTV {
+			description, mediaTypes = "Request a movie on Jellyseerr (this account can't request TV shows)", []string{"movie"}
+		} else if !canMovies {
+			description, mediaTypes = "Request a TV show on Jellyseerr (this account can't request movies)", []string{"tv"}
+		}
+		if config.MaxRating != "" && config.RatingAction == ratingBlock {
+			description += fmt.Sprintf(". Titles rated above %s can't be requested", config.MaxRating)
+		}
+		requestOpts := []mcp.ToolOption{
+			mcp.WithDescription(description),
+			mcp.WithNumber("tmdb_id", mcp.Required(), mcp.Description("TMDB ID of the media")),
+			mcp.WithString("media_type", mcp.Required(), mcp.Enum(mediaTypes...), mcp.Description("Type: 'movie' or 'tv'")),
+			mcp.WithBoolean("remove_exclusion", mcp.Description("If the title is on a Radarr/Sonarr exclusion list or the Jellyseerr blacklist, remove it from there and request anyway (default false)")),
+			mcp.WithOutputSchema[requestResult](),
+		}
+		if jellyseerrCan("request_4k", "request_4k_movie", "request_4k_tv") {
+			requestOpts = append(requestOpts, mcp.WithBoolean("is_4k", mcp.Description("Request the 4K version (default false)")))
+		}
+		if jellyseerrCan("request_advanced") {
+			requestOpts = append(requestOpts,
+				mcp.WithNumber("server_id", mcp.Description("Radarr/Sonarr server ID as configured in Jellyseerr (omit for the configured default)")),
+				mcp.WithNumber("profile_id", mcp.Description("Quality profile ID (omit for the configured default)")),
+				mcp.WithString("root_folder", mcp.Description("Root folder path (omit for the configured default)")),
+			)
+			if len(config.AudioLanguages) > 0 {
+				requestOpts = append(requestOpts, mcp.WithString("audio_language",
+					mcp.Enum(audioLanguageNames()...),
+					mcp.Description("Preferred audio language (e.g. for dubbed or original-audio versions); omit for the server default")))
+			}
+		}
+		s.AddTool(mcp.NewTool("jellyseerr_request", requestOpts...), handleJellyseerrRequest)
+	}
+
 	// List Requests
+	listDescription := "List media requests on Jellyseerr"
+	if !jellyseerrCan("manage_requests", "request_view") {
+		listDescription += " (this account only sees its own requests)"
+	}
 	s.AddTool(
 		mcp.NewTool("jellyseerr_list_requests",
-			mcp.WithDescription("List media requests on Jellyseerr"),
+			mcp.WithDescription(listDescription),
 			mcp.WithNumber("limit", mcp.Description("Number of requests to return (default 20)")),
+			formatOption(),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[requestList](),
 		),
 		handleJellyseerrListRequests,
 	)
-}
-
-func handleJellyseerrSearch(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	args := req.GetArguments()
-	query := args["query"].(string)
-
-	data, err := jellyseerrRequest("GET", "/search?query="+url.QueryEscape(query), nil)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
-	}
-
-	var result map[string]interface{}
-	json.Unmarshal(data, &result)
-
-	results, _ := result["results"].([]interface{})
-	var lines []string
-	lines = append(lines, fmt.Sprintf("Found %d results:\n", len(results)))
 
-	for i, r := range results {
-		if i >= 15 {
-			break
+	// Modify Request; others' requests need manage_requests, and changing
+	// where a request goes needs request_advanced
+	if canMovies || canTV {
+		modifyDescription := "Edit an existing Jellyseerr request instead of deleting and re-creating it: add seasons, or change the target server, quality profile, or root folder. Settings not given are kept."
+		advanced := jellyseerrCan("request_advanced")
+		if !advanced {
+			modifyDescription = "Edit an existing Jellyseerr TV request instead of deleting and re-creating it by adding or replacing its seasons."
 		}
-		item := r.(map[string]interface{})
-		mediaType := item["mediaType"].(string)
-		name := ""
-		if n, ok := item["name"].(string); ok {
-			name = n
-		} else if t, ok := item["title"].(string); ok {
-			name = t
+		if !jellyseerrCan("manage_requests") {
+			modifyDescription += " This account can only edit its own requests."
 		}
-		year := ""
-		if d, ok := item["firstAirDate"].(string); ok && len(d) >= 4 {
-			year = d[:4]
-		} else if d, ok := item["releaseDate"].(string); ok && len(d) >= 4 {
-			year = d[:4]
+		modifyOpts := []mcp.ToolOption{
+			mcp.WithDescription(modifyDescription),
+			mcp.WithNumber("request_id", mcp.Required(), mcp.Description("Jellyseerr request ID")),
+			mcp.WithArray("add_seasons", mcp.WithNumberItems(), mcp.Description("TV only: season numbers to add to the request")),
+			mcp.WithArray("seasons", mcp.WithNumberItems(), mcp.Description("TV only: the complete list of seasons to request, replacing the current list")),
+			mcp.WithOutputSchema[modifiedRequest](),
 		}
-		id := int(item["id"].(float64))
-
-		status := ""
-		if mi, ok := item["mediaInfo"].(map[string]interface{}); ok {
-			if s, ok := mi["status"].(float64); ok {
-				statusMap := map[int]string{2: "[Pending]", 3: "[Processing]", 4: "[Available]", 5: "[Partial]"}
-				status = statusMap[int(s)]
+		if advanced {
+			modifyOpts = append(modifyOpts,
+				mcp.WithNumber("server_id", mcp.Description("Radarr/Sonarr server ID as configured in Jellyseerr")),
+				mcp.WithNumber("profile_id", mcp.Description("Quality profile ID")),
+				mcp.WithString("root_folder", mcp.Description("Root folder path")),
+			)
+			if len(config.AudioLanguages) > 0 {
+				modifyOpts = append(modifyOpts, mcp.WithString("audio_language",
+					mcp.Enum(audioLanguageNames()...),
+					mcp.Description("Switch to the quality profile and tags configured for this audio language")))
 			}
 		}
+		s.AddTool(mcp.NewTool("jellyseerr_modify_request", modifyOpts...), handleJellyseerrModifyRequest)
+	}
 
-		lines = append(lines, fmt.Sprintf("  [%s] %s (%s) - TMDB: %d %s", strings.ToUpper(mediaType), name, year, id, status))
+	// Create User, an admin tool since it can grant any permission
+	if config.AdminTools && jellyseerrCan("manage_users") {
+		s.AddTool(
+			mcp.NewTool("jellyseerr_create_user",
+				mcp.WithDescription("Create a local Jellyseerr user with permissions and request quotas"),
+				mcp.WithString("email", mcp.Required(), mcp.Description("Email address (used to sign in)")),
+				mcp.WithString("username", mcp.Description("Display name (defaults to the email address)")),
+				mcp.WithString("password", mcp.Description("Password (omit to have Jellyseerr email a generated one; requires email notifications)")),
+				mcp.WithArray("permissions", mcp.WithStringEnumItems(jellyseerrPermissionNames()), mcp.Description("Permissions to grant (omit for Jellyseerr's default user permissions)")),
+				mcp.WithNumber("movie_quota_limit", mcp.Description("Movie requests allowed per quota period (0 for unlimited)")),
+				mcp.WithNumber("movie_quota_days", mcp.Description("Movie quota period in days")),
+				mcp.WithNumber("tv_quota_limit", mcp.Description("Season requests allowed per quota period (0 for unlimited)")),
+				mcp.WithNumber("tv_quota_days", mcp.Description("TV quota period in days")),
+				mcp.WithOutputSchema[userResult](),
+			),
+			handleJellyseerrCreateUser,
+		)
 	}
 
-	return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
-}
+	// Remind Me
+	s.AddTool(
+		mcp.NewTool("jellyseerr_remind_me",
+			mcp.WithDescription("Remember a pending or processing Jellyseerr request and report it in jellyseerr_my_reminders once it becomes available"),
+			mcp.WithNumber("request_id", mcp.Required(), mcp.Description("Jellyseerr request ID")),
+			mcp.WithOutputSchema[reminderResult](),
+		),
+		handleJellyseerrRemindMe,
+	)
 
-func handleJellyseerrRequest(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	args := req.GetArguments()
-	tmdbID := int(args["tmdb_id"].(float64))
-	mediaType := args["media_type"].(string)
+	// My Reminders
+	s.AddTool(
+		mcp.NewTool("jellyseerr_my_reminders",
+			mcp.WithDescription("List reminded requests, showing which have become available and which are still waiting"),
+			mcp.WithBoolean("clear_ready", mcp.Description("Forget reminders that are already available after listing them (default false)")),
+			mcp.WithOutputSchema[reminderList](),
+		),
+		handleJellyseerrMyReminders,
+	)
 
-	payload := map[string]interface{}{
-		"mediaType": mediaType,
-		"mediaId":   tmdbID,
-	}
-	if mediaType == "tv" {
-		payload["seasons"] = "all"
+	// Request Trends; only meaningful when every user's requests are visible
+	if jellyseerrCan("manage_requests", "request_view") {
+		s.AddTool(
+			mcp.NewTool("jellyseerr_request_trends",
+				mcp.WithDescription("Summarize request trends for reporting household usage: requests per week, most requested genres, requests per user, fulfillment rate, and average time from request to available"),
+				mcp.WithNumber("weeks", mcp.Description("Number of weeks to cover, including this one (default 12, max 52)")),
+				mcp.WithReadOnlyHintAnnotation(true),
+				mcp.WithOutputSchema[requestTrends](),
+			),
+			handleJellyseerrRequestTrends,
+		)
 	}
+}
 
-	body, _ := json.Marshal(payload)
-	data, err := jellyseerrRequest("POST", "/request", strings.NewReader(string(body)))
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
-	}
+// discoverCategories maps discover category names to Jellyseerr endpoints.
+// A %d in the endpoint is replaced with the genre, studio, or network ID.
+var discoverCategories = []struct {
+	Name      string
+	Endpoint  string
+	MediaType string
+}{
+	{"trending", "/discover/trending", ""},
+	{"popular_movies", "/discover/movies", "movie"},
+	{"upcoming_movies", "/discover/movies/upcoming", "movie"},
+	{"popular_tv", "/discover/tv", "tv"},
+	{"upcoming_tv", "/discover/tv/upcoming", "tv"},
+	{"movie_genre", "/discover/movies/genre/%d", "movie"},
+	{"tv_genre", "/discover/tv/genre/%d", "tv"},
+	{"studio", "/discover/movies/studio/%d", "movie"},
+	{"network", "/discover/tv/network/%d", "tv"},
+	{"movie_genres", "/discover/genreslider/movie", ""},
+	{"tv_genres", "/discover/genreslider/tv", ""},
+	{"studios", "", ""},
+	{"networks", "", ""},
+}
 
-	var result map[string]interface{}
-	json.Unmarshal(data, &result)
+// Studios and networks featured on Jellyseerr's discover page
+var discoverStudios = []struct {
+	Name string
+	ID   int
+}{
+	{"Disney", 2}, {"20th Century", 127928}, {"Sony Pictures", 34}, {"Warner Bros. Pictures", 174},
+	{"Universal", 33}, {"Paramount", 4}, {"Pixar", 3}, {"DreamWorks", 521},
+	{"Marvel Studios", 420}, {"DC", 9993}, {"A24", 41077},
+}
+
+var discoverNetworks = []struct {
+	Name string
+	ID   int
+}{
+	{"Netflix", 213}, {"Disney+", 2739}, {"Prime Video", 1024}, {"Apple TV+", 2552},
+	{"Hulu", 453}, {"HBO", 49}, {"Discovery+", 4353}, {"ABC", 2}, {"FOX", 19},
+	{"Cinemax", 359}, {"AMC", 174}, {"Showtime", 67}, {"Starz", 318}, {"The CW", 71},
+	{"NBC", 6}, {"CBS", 16}, {"Paramount+", 4330}, {"BBC One", 4},
+	{"Cartoon Network", 56}, {"Adult Swim", 80}, {"Nickelodeon", 13},
+}
 
-	if id, ok := result["id"]; ok {
-		return mcp.NewToolResultText(fmt.Sprintf("Request created successfully. Request ID: %v", id)), nil
+func discoverCategoryNames() []string {
+	var names []string
+	for _, c := range discoverCategories {
+		names = append(names, c.Name)
 	}
-	return mcp.NewToolResultText(fmt.Sprintf("Response: %s", string(data))), nil
+	return names
 }
 
-func handleJellyseerrListRequests(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// discoverOption is a genre, studio, or network usable with jellyseerr_discover.
+type discoverOption struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// discoverResult is the structured result of jellyseerr_discover. Listing
+// categories (genres, studios, networks) fill Options instead of Results.
+// Name is the studio or network browsed, and Hidden counts titles left out
+// by missing_only.
+type discoverResult struct {
+	Category   string           `json:"category"`
+	Name       string           `json:"name,omitempty"`
+	Page       int              `json:"page,omitempty"`
+	TotalPages int              `json:"totalPages,omitempty"`
+	Hidden     int              `json:"hidden,omitempty"`
+	Results    []mediaResult    `json:"results"`
+	Options    []discoverOption `json:"options,omitempty"`
+}
+
+func handleJellyseerrDiscover(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args := req.GetArguments()
-	limit := 20
-	if l, ok := args["limit"].(float64); ok {
-		limit = int(l)
+	category := args["category"].(string)
+	page := 1
+	if p, ok := args["page"].(float64); ok && p > 0 {
+		page = int(p)
 	}
 
-	data, err := jellyseerrRequest("GET", fmt.Sprintf("/request?take=%d", limit), nil)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+	result := discoverResult{Category: category, Results: []mediaResult{}}
+	var lines []string
+	switch category {
+	case "studios":
+		lines = append(lines, "Studios (use with category 'studio'):\n")
+		for _, st := range discoverStudios {
+			lines = append(lines, fmt.Sprintf("  [%d] %s", st.ID, st.Name))
+			result.Options = append(result.Options, discoverOption{ID: st.ID, Name: st.Name})
+		}
+		return mcp.NewToolResultStructured(result, strings.Join(lines, "\n")), nil
+	case "networks":
+		lines = append(lines, "Networks (use with category 'network'):\n")
+		for _, n := range discoverNetworks {
+			lines = append(lines, fmt.Sprintf("  [%d] %s", n.ID, n.Name))
+			result.Options = append(result.Options, discoverOption{ID: n.ID, Name: n.Name})
+		}
+		return mcp.NewToolResultStructured(result, strings.Join(lines, "\n")), nil
 	}
 
-	var result map[string]interface{}
-	json.Unmarshal(data, &result)
+	for _, c := range discoverCategories {
+		if c.Name != category {
+			continue
+		}
 
-	results, _ := result["results"].([]interface{})
-	var lines []string
-	lines = append(lines, fmt.Sprintf("Requests (%d):\n", len(results)))
+		endpoint := c.Endpoint
+		if strings.Contains(endpoint, "%d") {
+			id, ok := args["id"].(float64)
+			name, _ := args["name"].(string)
+			switch {
+			case ok:
+			case name != "" && (category == "studio" || category == "network"):
+				companyID, companyName, err := resolveCompany(ctx, category, name)
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				id, result.Name = float64(companyID), companyName
+			case category == "studio" || category == "network":
+				return mcp.NewToolResultError(fmt.Sprintf("Category %s requires an id or name", category)), nil
+			default:
+				return mcp.NewToolResultError(fmt.Sprintf("Category %s requires an id", category)), nil
+			}
+			endpoint = fmt.Sprintf(endpoint, int(id))
+		}
 
-	statusMap := map[int]string{1: "Pending", 2: "Approved", 3: "Declined"}
+		// Genre sliders return a plain list of genres rather than media results
+		if strings.HasPrefix(endpoint, "/discover/genreslider/") {
+			data, err := jellyseerrRequest(ctx, "GET", endpoint, nil)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			var genres []map[string]interface{}
+			json.Unmarshal(data, &genres)
 
-	for _, r := range results {
-		item := r.(map[string]interface{})
+			target := "movie_genre"
+			if category == "tv_genres" {
+				target = "tv_genre"
+			}
+			lines = append(lines, fmt.Sprintf("Genres (use with category '%s'):\n", target))
+			for _, g := range genres {
+				id := int(g["id"].(float64))
+				name, _ := g["name"].(string)
+				lines = append(lines, fmt.Sprintf("  [%d] %s", id, name))
+				result.Options = append(result.Options, discoverOption{ID: id, Name: name})
+			}
+			return mcp.NewToolResultStructured(result, strings.Join(lines, "\n")), nil
+		}
+
+		data, err := jellyseerrRequest(ctx, "GET", fmt.Sprintf("%s?page=%d", endpoint, page), nil)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		var response map[string]interface{}
+		json.Unmarshal(data, &response)
+
+		results, _ := response["results"].([]interface{})
+		result.Page = page
+		if tp, ok := response["totalPages"].(float64); ok {
+			result.TotalPages = int(tp)
+		}
+		missingOnly, _ := args["missing_only"].(bool)
+		for _, m := range parseMediaResults(results, 20, c.MediaType) {
+			if missingOnly && (m.Status == "available" || m.Status == "partial") {
+				result.Hidden++
+				continue
+			}
+			result.Results = append(result.Results, m)
+		}
+
+		heading := category
+		if result.Name != "" {
+			heading = result.Name
+		}
+		heading = fmt.Sprintf("%s (page %d of %d", heading, page, result.TotalPages)
+		if result.Hidden > 0 {
+			heading += fmt.Sprintf(", %d already available hidden", result.Hidden)
+		}
+		lines = append(lines, heading+"):\n")
+		lines = append(lines, formatMediaResults(result.Results)...)
+		return mcp.NewToolResultStructured(result, strings.Join(lines, "\n")), nil
+	}
+
+	return mcp.NewToolResultError(fmt.Sprintf("Unknown category %s", category)), nil
+}
+
+// resolveCompany turns a network or studio name (or numeric TMDB ID) into an
+// ID and display name. Names are matched against the networks and studios
+// featured on Jellyseerr's discover page; other studios are looked up with
+// Jellyseerr's company search.
+func resolveCompany(ctx context.Context, kind, query string) (int, string, error) {
+	if id, err := strconv.Atoi(strings.TrimSpace(query)); err == nil {
+		return id, fmt.Sprintf("%s %d", kind, id), nil
+	}
+
+	featured := discoverNetworks
+	if kind == "studio" {
+		featured = discoverStudios
+	}
+	var partial []int
+	for i, f := range featured {
+		if strings.EqualFold(f.Name, query) {
+			return f.ID, f.Name, nil
+		}
+		if strings.Contains(strings.ToLower(f.Name), strings.ToLower(query)) {
+			partial = append(partial, i)
+		}
+	}
+	if len(partial) == 1 {
+		return featured[partial[0]].ID, featured[partial[0]].Name, nil
+	}
+
+	if kind == "studio" {
+		data, err := jellyseerrRequest(ctx, "GET", "/search/company?query="+url.QueryEscape(query), nil)
+		if err != nil {
+			return 0, "", err
+		}
+		var response map[string]interface{}
+		json.Unmarshal(data, &response)
+		results, _ := response["results"].([]interface{})
+		for _, r := range results {
+			company, _ := r.(map[string]interface{})
+			id, ok := company["id"].(float64)
+			if !ok {
+				continue
+			}
+			name, _ := company["name"].(string)
+			return int(id), name, nil
+		}
+	}
+
+	var names []string
+	for _, f := range featured {
+		names = append(names, f.Name)
+	}
+	return 0, "", fmt.Errorf("unknown %s %q; pass its TMDB ID or one of: %s", kind, query, strings.Join(names, ", "))
+}
+
+// Jellyseerr treats TV shows with TMDB's "anime" keyword as anime
+const animeKeywordID = 210024
+
+// defaultsFor returns the configured defaults for a title and the media kind
+// they were configured under. 4K defaults are used for 4K requests; anime
+// defaults for shows Jellyseerr would treat as anime, falling back to tv.
+func defaultsFor(ctx context.Context, mediaType string, tmdbID int, is4k bool) (string, mediaDefaults, bool) {
+	kind := mediaType
+	if is4k {
+		kind += "4k"
+	} else if _, ok := config.Defaults["anime"]; ok && mediaType == "tv" && isAnime(ctx, tmdbID) {
+		kind = "anime"
+	}
+	d, ok := config.Defaults[kind]
+	return kind, d, ok
+}
+
+// isAnime reports whether a TV show carries TMDB's anime keyword.
+func isAnime(ctx context.Context, tmdbID int) bool {
+	data, err := jellyseerrRequest(ctx, "GET", fmt.Sprintf("/tv/%d", tmdbID), nil)
+	if err != nil {
+		return false
+	}
+	var show struct {
+		Keywords []struct {
+			ID int `json:"id"`
+		} `json:"keywords"`
+	}
+	json.Unmarshal(data, &show)
+	for _, k := range show.Keywords {
+		if k.ID == animeKeywordID {
+			return true
+		}
+	}
+	return false
+}
+
+// audioLanguageNames returns the configured audio language keys in sorted order.
+func audioLanguageNames() []string {
+	var names []string
+	for name := range config.AudioLanguages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// jellyseerrPermissions maps permission names to Jellyseerr's permission bits.
+var jellyseerrPermissions = []struct {
+	Name string
+	Bit  int
+}{
+	{"admin", 2},
+	{"manage_settings", 4},
+	{"manage_users", 8},
+	{"manage_requests", 16},
+	{"request", 32},
+	{"vote", 64},
+	{"auto_approve", 128},
+	{"auto_approve_movie", 256},
+	{"auto_approve_tv", 512},
+	{"request_4k", 1024},
+	{"request_4k_movie", 2048},
+	{"request_4k_tv", 4096},
+	{"request_advanced", 8192},
+	{"request_view", 16384},
+	{"auto_approve_4k", 32768},
+	{"auto_approve_4k_movie", 65536},
+	{"auto_approve_4k_tv", 131072},
+	{"request_movie", 262144},
+	{"request_tv", 524288},
+	{"manage_issues", 1048576},
+	{"view_issues", 2097152},
+	{"create_issues", 4194304},
+	{"auto_request", 8388608},
+	{"auto_request_movie", 16777216},
+	{"auto_request_tv", 33554432},
+	{"recent_view", 67108864},
+	{"watchlist_view", 134217728},
+}
+
+func jellyseerrPermissionNames() []string {
+	var names []string
+	for _, p := range jellyseerrPermissions {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// jellyseerrAccount is the Jellyseerr user the API key acts as. Known is
+// false when it couldn't be looked up, in which case every tool is offered.
+var jellyseerrAccount struct {
+	Known       bool
+	Name        string
+	Permissions int
+}
+
+// loadJellyseerrAccount looks up the API key's user and permissions so tools
+// it isn't allowed to use aren't offered.
+func loadJellyseerrAccount(ctx context.Context) {
+	data, err := jellyseerrRequest(ctx, "GET", "/auth/me", nil)
+	if err != nil {
+		log.Printf("Jellyseerr: couldn't check the API key's permissions, offering all tools: %v", err)
+		return
+	}
+	var user map[string]interface{}
+	json.Unmarshal(data, &user)
+	permissions, _ := user["permissions"].(float64)
+	name, _ := user["displayName"].(string)
+	jellyseerrAccount.Known = true
+	jellyseerrAccount.Name = name
+	jellyseerrAccount.Permissions = int(permissions)
+	if !jellyseerrCan() {
+		log.Printf("Jellyseerr: API key acts as %s, who isn't an admin; tools are limited to their permissions", name)
+	}
+}
+
+// jellyseerrCan reports whether the API key's user has any of the named
+// permissions (admin implies all of them). With no names it reports whether
+// the user is an admin.
+func jellyseerrCan(names ...string) bool {
+	if !jellyseerrAccount.Known {
+		return true
+	}
+	for _, p := range jellyseerrPermissions {
+		if jellyseerrAccount.Permissions&p.Bit == 0 {
+			continue
+		}
+		if p.Name == "admin" {
+			return true
+		}
+		for _, n := range names {
+			if p.Name == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// userResult is the structured result of jellyseerr_create_user. Warnings
+// list settings that couldn't be applied after the user was created.
+type userResult struct {
+	UserID      int            `json:"userId"`
+	Username    string         `json:"username"`
+	Email       string         `json:"email"`
+	Permissions []string       `json:"permissions,omitempty"`
+	Quotas      map[string]int `json:"quotas,omitempty"`
+	Warnings    []string       `json:"warnings,omitempty"`
+}
+
+func handleJellyseerrCreateUser(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	email := args["email"].(string)
+	username, _ := args["username"].(string)
+	if username == "" {
+		username = email
+	}
+
+	permissions := -1
+	if names, ok := args["permissions"].([]interface{}); ok {
+		permissions = 0
+		for _, n := range names {
+			found := false
+			for _, p := range jellyseerrPermissions {
+				if p.Name == n {
+					permissions |= p.Bit
+					found = true
+				}
+			}
+			if !found {
+				return mcp.NewToolResultError(fmt.Sprintf("Unknown permission %v", n)), nil
+			}
+		}
+	}
+
+	payload := map[string]interface{}{
+		"email":    email,
+		"username": username,
+	}
+	if pw, ok := args["password"].(string); ok && pw != "" {
+		payload["password"] = pw
+	}
+	body, _ := json.Marshal(payload)
+
+	data, err := jellyseerrRequest(ctx, "POST", "/user", strings.NewReader(string(body)))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var user map[string]interface{}
+	json.Unmarshal(data, &user)
+	userID := int(user["id"].(float64))
+
+	result := userResult{UserID: userID, Username: username, Email: email}
+	var lines []string
+	lines = append(lines, fmt.Sprintf("Created user %s (ID: %d)", username, userID))
+
+	// Jellyseerr always creates users with the default permissions, so apply the requested set afterwards
+	if permissions >= 0 {
+		body, _ := json.Marshal(map[string]interface{}{"permissions": permissions})
+		if _, err := jellyseerrRequest(ctx, "POST", fmt.Sprintf("/user/%d/settings/permissions", userID), strings.NewReader(string(body))); err != nil {
+			lines = append(lines, "Failed to set permissions: "+err.Error())
+			result.Warnings = append(result.Warnings, "Failed to set permissions: "+err.Error())
+		} else {
+			lines = append(lines, fmt.Sprintf("Permissions: %v", args["permissions"]))
+			for _, n := range args["permissions"].([]interface{}) {
+				result.Permissions = append(result.Permissions, fmt.Sprint(n))
+			}
+		}
+	}
+
+	quotas := map[string]string{
+		"movie_quota_limit": "movieQuotaLimit",
+		"movie_quota_days":  "movieQuotaDays",
+		"tv_quota_limit":    "tvQuotaLimit",
+		"tv_quota_days":     "tvQuotaDays",
+	}
+	quotaSet := false
+	for arg := range quotas {
+		if _, ok := args[arg].(float64); ok {
+			quotaSet = true
+		}
+	}
+	if quotaSet {
+		// The main settings endpoint replaces every field, so start from the current values
+		data, err := jellyseerrRequest(ctx, "GET", fmt.Sprintf("/user/%d/settings/main", userID), nil)
+		if err != nil {
+			lines = append(lines, "Failed to set quotas: "+err.Error())
+			result.Warnings = append(result.Warnings, "Failed to set quotas: "+err.Error())
+			return mcp.NewToolResultStructured(result, strings.Join(lines, "\n")), nil
+		}
+		var settings map[string]interface{}
+		json.Unmarshal(data, &settings)
+		settings["username"] = username
+		for arg, field := range quotas {
+			if v, ok := args[arg].(float64); ok {
+				settings[field] = int(v)
+			}
+		}
+
+		body, _ := json.Marshal(settings)
+		if _, err := jellyseerrRequest(ctx, "POST", fmt.Sprintf("/user/%d/settings/main", userID), strings.NewReader(string(body))); err != nil {
+			lines = append(lines, "Failed to set quotas: "+err.Error())
+			result.Warnings = append(result.Warnings, "Failed to set quotas: "+err.Error())
+		} else {
+			lines = append(lines, fmt.Sprintf("Quotas: movies %v per %v days, TV seasons %v per %v days",
+				settings["movieQuotaLimit"], settings["movieQuotaDays"], settings["tvQuotaLimit"], settings["tvQuotaDays"]))
+			result.Quotas = map[string]int{}
+			for _, field := range quotas {
+				switch v := settings[field].(type) {
+				case float64:
+					result.Quotas[field] = int(v)
+				case int:
+					result.Quotas[field] = v
+				}
+			}
+		}
+	}
+
+	return mcp.NewToolResultStructured(result, strings.Join(lines, "\n")), nil
+}
+
+// jellyseerrTitle looks up the display title for a TMDB ID, returning an
+// empty string if it can't be resolved. Titles are cached.
+func jellyseerrTitle(ctx context.Context, mediaType string, tmdbID int) string {
+	if title, ok := cachedTitle(mediaType, tmdbID); ok {
+		return title
+	}
+
+	data, err := jellyseerrRequest(ctx, "GET", fmt.Sprintf("/%s/%d", mediaType, tmdbID), nil)
+	if err != nil {
+		return ""
+	}
+
+	var result map[string]interface{}
+	json.Unmarshal(data, &result)
+
+	title, ok := result["name"].(string)
+	if !ok {
+		title, _ = result["title"].(string)
+	}
+	rememberTitle(mediaType, tmdbID, title)
+	return title
+}
+
+// searchResult is the structured result of jellyseerr_search. RetriedWith is
+// set when the original query found nothing and a normalized one was used.
+type searchResult struct {
+	Query       string        `json:"query"`
+	RetriedWith string        `json:"retriedWith,omitempty"`
+	Total       int           `json:"total"`
+	Results     []mediaResult `json:"results"`
+}
+
+func handleJellyseerrSearch(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	query := args["query"].(string)
+
+	results, err := jellyseerrSearch(ctx, query)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := searchResult{Query: query}
+	var lines []string
+
+	// Retry fuzzy-remembered titles with normalized punctuation/diacritics, then without the year
+	year := ""
+	if len(results) == 0 {
+		normalized := normalizeQuery(query)
+		stripped, y := stripYear(normalized)
+		year = y
+		tried := map[string]bool{query: true}
+		for _, retry := range []string{normalized, stripped} {
+			if retry == "" || tried[retry] {
+				continue
+			}
+			tried[retry] = true
+			if results, err = jellyseerrSearch(ctx, retry); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if len(results) > 0 {
+				lines = append(lines, fmt.Sprintf("No results for %q; showing results for %q instead.", query, retry))
+				result.RetriedWith = retry
+				break
+			}
+		}
+	}
+
+	// Put results from the year the user mentioned first
+	if year != "" {
+		sort.SliceStable(results, func(i, j int) bool {
+			_, yi := mediaNameYear(results[i].(map[string]interface{}))
+			_, yj := mediaNameYear(results[j].(map[string]interface{}))
+			return yi == year && yj != year
+		})
+	}
+
+	result.Total = len(results)
+	result.Results = parseMediaResults(results, 15, "")
+	addRatings(ctx, result.Results)
+
+	lines = append(lines, fmt.Sprintf("Found %d results:\n", len(results)))
+	lines = append(lines, formatMediaResults(result.Results)...)
+
+	return mcp.NewToolResultStructured(result, strings.Join(lines, "\n")), nil
+}
+
+func jellyseerrSearch(ctx context.Context, query string) ([]interface{}, error) {
+	data, err := jellyseerrRequest(ctx, "GET", "/search?query="+url.QueryEscape(query), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]interface{}
+	json.Unmarshal(data, &result)
+
+	results, _ := result["results"].([]interface{})
+	return results, nil
+}
+
+var diacriticReplacer = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "æ", "ae",
+	"ç", "c", "è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ñ", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "œ", "oe",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ý", "y", "ÿ", "y", "ß", "ss",
+	"&", " and ", "’", "'", "‘", "'", "“", "", "”", "",
+)
+
+// normalizeQuery lowercases a search query, folds common diacritics, and
+// replaces punctuation with spaces.
+func normalizeQuery(q string) string {
+	q = diacriticReplacer.Replace(strings.ToLower(q))
+	q = strings.Map(func(r rune) rune {
+		if r == '\'' {
+			return -1
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '(' || r == ')' {
+			return r
+		}
+		return ' '
+	}, q)
+	return strings.Join(strings.Fields(q), " ")
+}
+
+var queryYear = regexp.MustCompile(`\s*\(?\b(19|20)\d{2}\b\)?\s*$`)
+
+// stripYear removes a trailing release year like "1999" or "(1999)",
+// returning the remaining query and the year.
+func stripYear(q string) (string, string) {
+	m := queryYear.FindString(q)
+	if m == "" {
+		return strings.Trim(q, "() "), ""
+	}
+	year := strings.Trim(m, "() ")
+	return strings.Trim(strings.TrimSuffix(q, m), "() "), year
+}
+
+// mediaNameYear returns the title and release year of a Jellyseerr result.
+func mediaNameYear(item map[string]interface{}) (string, string) {
+	name := ""
+	if n, ok := item["name"].(string); ok {
+		name = n
+	} else if t, ok := item["title"].(string); ok {
+		name = t
+	}
+	year := ""
+	if d, ok := item["firstAirDate"].(string); ok && len(d) >= 4 {
+		year = d[:4]
+	} else if d, ok := item["releaseDate"].(string); ok && len(d) >= 4 {
+		year = d[:4]
+	}
+	return name, year
+}
+
+// mediaResult is a Jellyseerr search or discover result. Origin is only set
+// when other results share the title. AboveMaxRating is set when the rating
+// is above ULTIMARR_MAX_RATING.
+type mediaResult struct {
+	TmdbID    int    `json:"tmdbId"`
+	MediaType string `json:"mediaType"`
+	Title     string `json:"title"`
+	Year      string `json:"year,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Origin    string `json:"origin,omitempty"`
+
+	// Only looked up for search results
+	Rating         *contentRating `json:"rating,omitempty"`
+	AboveMaxRating bool           `json:"aboveMaxRating,omitempty"`
+}
+
+// Jellyseerr media availability statuses
+var mediaStatusNames = map[int]string{2: "pending", 3: "processing", 4: "available", 5: "partial"}
+
+// parseMediaResults converts Jellyseerr search/discover results, up to max
+// entries, skipping people. defaultType fills in mediaType for endpoints that
+// omit it.
+func parseMediaResults(results []interface{}, max int, defaultType string) []mediaResult {
+	// Count titles so same-named results can show what tells them apart
+	nameCount := map[string]int{}
+	for _, r := range results {
+		name, _ := mediaNameYear(r.(map[string]interface{}))
+		nameCount[strings.ToLower(name)]++
+	}
+
+	parsed := []mediaResult{}
+	for i, r := range results {
+		if i >= max {
+			break
+		}
+		item := r.(map[string]interface{})
+		mediaType, ok := item["mediaType"].(string)
+		if !ok {
+			mediaType = defaultType
+		}
+		if mediaType == "person" {
+			continue
+		}
+		name, year := mediaNameYear(item)
+		m := mediaResult{TmdbID: int(item["id"].(float64)), MediaType: mediaType, Title: name, Year: year}
+		rememberTitle(mediaType, m.TmdbID, name)
+
+		if mi, ok := item["mediaInfo"].(map[string]interface{}); ok {
+			if s, ok := mi["status"].(float64); ok {
+				m.Status = mediaStatusNames[int(s)]
+			}
+		}
+		if nameCount[strings.ToLower(name)] > 1 {
+			m.Origin = mediaOrigin(item)
+		}
+		parsed = append(parsed, m)
+	}
+	return parsed
+}
+
+// formatMediaResults renders parsed search/discover results for the text result.
+func formatMediaResults(results []mediaResult) []string {
+	var lines []string
+	var ambiguous []string
+	for _, m := range results {
+		status := ""
+		if m.Status != "" {
+			status = "[" + strings.ToUpper(m.Status[:1]) + m.Status[1:] + "]"
+		}
+
+		line := fmt.Sprintf("  [%s] %s (%s) - TMDB: %d %s", strings.ToUpper(m.MediaType), m.Title, m.Year, m.TmdbID, status)
+		if m.Rating != nil {
+			line += "\n      " + describeRating(*m.Rating)
+			if m.AboveMaxRating {
+				line += fmt.Sprintf(" - above the %s limit", config.MaxRating)
+			}
+		}
+		if m.Origin != "" {
+			line += "\n      (" + m.Origin + ")"
+			if !contains(ambiguous, m.Title) {
+				ambiguous = append(ambiguous, m.Title)
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	for _, name := range ambiguous {
+		lines = append(lines, fmt.Sprintf("\nNote: several results are titled %q; check the year and origin above before requesting.", name))
+	}
+	return lines
+}
+
+// mediaOrigin describes where a result comes from (country and original
+// language), to tell apart remakes and same-named shows.
+func mediaOrigin(item map[string]interface{}) string {
+	var parts []string
+	if countries, ok := item["originCountry"].([]interface{}); ok && len(countries) > 0 {
+		var cs []string
+		for _, c := range countries {
+			if str, ok := c.(string); ok {
+				cs = append(cs, str)
+			}
+		}
+		parts = append(parts, "country: "+strings.Join(cs, ", "))
+	}
+	if lang, ok := item["originalLanguage"].(string); ok && lang != "" {
+		parts = append(parts, "language: "+lang)
+	}
+	if orig, ok := item["originalTitle"].(string); ok && orig != "" {
+		parts = append(parts, "original title: "+orig)
+	} else if orig, ok := item["originalName"].(string); ok && orig != "" {
+		parts = append(parts, "original title: "+orig)
+	}
+	if len(parts) == 0 {
+		return "origin unknown"
+	}
+	return strings.Join(parts, " | ")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// requestResult is the structured result of jellyseerr_request.
+// RemovedExclusions names the services whose exclusion lists the title was
+// removed from first; Defaults is the ULTIMARR_DEFAULTS media kind applied.
+type requestResult struct {
+	RequestID         int      `json:"requestId"`
+	TmdbID            int      `json:"tmdbId"`
+	MediaType         string   `json:"mediaType"`
+	Title             string   `json:"title"`
+	Is4k              bool     `json:"is4k"`
+	ServerID          *int     `json:"serverId,omitempty"`
+	ProfileID         int      `json:"profileId,omitempty"`
+	RootFolder        string   `json:"rootFolder,omitempty"`
+	Tags              []int    `json:"tags,omitempty"`
+	Defaults          string   `json:"defaults,omitempty"`
+	RemovedExclusions []string `json:"removedExclusions,omitempty"`
+
+	// Set when the title is above ULTIMARR_MAX_RATING and was flagged
+	Rating         *contentRating `json:"rating,omitempty"`
+	AboveMaxRating bool           `json:"aboveMaxRating,omitempty"`
+}
+
+func handleJellyseerrRequest(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	tmdbID := int(args["tmdb_id"].(float64))
+	mediaType := args["media_type"].(string)
+
+	is4k, _ := args["is_4k"].(bool)
+
+	// Explicit arguments win over the audio language, which wins over defaults
+	result := requestResult{TmdbID: tmdbID, MediaType: mediaType, Is4k: is4k}
+	if v, ok := args["server_id"].(float64); ok {
+		id := int(v)
+		result.ServerID = &id
+	}
+	if v, ok := args["profile_id"].(float64); ok {
+		result.ProfileID = int(v)
+	}
+	result.RootFolder, _ = args["root_folder"].(string)
+	if lang, ok := args["audio_language"].(string); ok && lang != "" {
+		profile, ok := config.AudioLanguages[lang]
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("Unknown audio language %q (configured: %s)", lang, strings.Join(audioLanguageNames(), ", "))), nil
+		}
+		profileID := profile.MovieProfileID
+		if mediaType == "tv" {
+			profileID = profile.TVProfileID
+		}
+		if result.ProfileID == 0 {
+			result.ProfileID = profileID
+		}
+		result.Tags = profile.Tags
+	}
+	var notes []string
+	if kind, defaults, ok := defaultsFor(ctx, mediaType, tmdbID, is4k); ok {
+		var applied []string
+		if result.ServerID == nil && defaults.ServerID != nil {
+			result.ServerID = defaults.ServerID
+			applied = append(applied, fmt.Sprintf("server %d", *defaults.ServerID))
+		}
+		if result.ProfileID == 0 && defaults.ProfileID > 0 {
+			result.ProfileID = defaults.ProfileID
+			applied = append(applied, fmt.Sprintf("profile %d", defaults.ProfileID))
+		}
+		if result.RootFolder == "" && defaults.RootFolder != "" {
+			result.RootFolder = defaults.RootFolder
+			applied = append(applied, "root folder "+defaults.RootFolder)
+		}
+		if len(result.Tags) == 0 && len(defaults.Tags) > 0 {
+			result.Tags = defaults.Tags
+			applied = append(applied, fmt.Sprintf("tags %v", defaults.Tags))
+		}
+		if len(applied) > 0 {
+			result.Defaults = kind
+			notes = append(notes, fmt.Sprintf("Using %s defaults: %s.", kind, strings.Join(applied, ", ")))
+		}
+	}
+
+	payload := map[string]interface{}{
+		"mediaType": mediaType,
+		"mediaId":   tmdbID,
+		"is4k":      is4k,
+	}
+	if mediaType == "tv" {
+		payload["seasons"] = "all"
+	}
+	if result.ServerID != nil {
+		payload["serverId"] = *result.ServerID
+	}
+	if result.ProfileID > 0 {
+		payload["profileId"] = result.ProfileID
+	}
+	if result.RootFolder != "" {
+		payload["rootFolder"] = result.RootFolder
+	}
+	if len(result.Tags) > 0 {
+		payload["tags"] = result.Tags
+	}
+
+	result.Title = jellyseerrTitle(ctx, mediaType, tmdbID)
+	title := result.Title
+	if title == "" {
+		title = fmt.Sprintf("TMDB %d", tmdbID)
+	}
+
+	if config.MaxRating != "" {
+		block := config.RatingAction == ratingBlock
+		r, err := titleRating(ctx, mediaType, tmdbID)
+		switch {
+		case err != nil:
+			// Without the rating there's no telling whether the limit allows it
+			if block {
+				return mcp.NewToolResultError(fmt.Sprintf("Couldn't check the rating of %s against the %s limit set for this server, so it wasn't requested: %v", title, config.MaxRating, err)), nil
+			}
+			notes = append(notes, fmt.Sprintf("Note: couldn't check the rating of %s against the %s limit: %v", title, config.MaxRating, err))
+		case aboveMaxRating(r.Rating):
+			result.Rating, result.AboveMaxRating = &r, true
+			if block {
+				return mcp.NewToolResultError(fmt.Sprintf("%s is rated %s, above the %s limit set for this server, so it can't be requested here.", title, r.Rating, config.MaxRating)), nil
+			}
+			notes = append(notes, fmt.Sprintf("Note: %s is rated %s, above the %s limit set for this server.", title, r.Rating, config.MaxRating))
+		case block && config.BlockUnrated && !comparableRating(r.Rating):
+			rating := "isn't rated"
+			if r.Rating != "" {
+				rating = fmt.Sprintf("is rated %s, which can't be compared", r.Rating)
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("%s %s with the %s limit set for this server, and unrated titles are blocked, so it can't be requested here.", title, rating, config.MaxRating)), nil
+		}
+	}
+
+	// Exclusions make the request fail (Jellyseerr) or the *arr add fail later, so check up front
+	if excl := findExclusions(ctx, mediaType, tmdbID); len(excl) > 0 {
+		if remove, _ := args["remove_exclusion"].(bool); !remove {
+			return mcp.NewToolResultError(describeExclusions(title, excl)), nil
+		}
+		for _, e := range excl {
+			if err := removeExclusion(ctx, e); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to remove %s exclusion: %v", e.Service, err)), nil
+			}
+			notes = append(notes, fmt.Sprintf("Removed %s from the %s exclusion list.", title, e.Service))
+			result.RemovedExclusions = append(result.RemovedExclusions, e.Service)
+		}
+	}
+
+	body, _ := json.Marshal(payload)
+	data, err := jellyseerrRequest(ctx, "POST", "/request", strings.NewReader(string(body)))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var created map[string]interface{}
+	json.Unmarshal(data, &created)
+
+	if err := trackRequest(mediaType, tmdbID); err != nil {
+		log.Printf("Tracking request for notifications: %v", err)
+		notes = append(notes, "Its import may not be announced: "+err.Error())
+	}
+	if id, ok := created["id"].(float64); ok {
+		result.RequestID = int(id)
+		notes = append(notes, fmt.Sprintf("Requested %s. Request ID: %d", title, result.RequestID))
+		return mcp.NewToolResultStructured(result, strings.Join(notes, "\n")), nil
+	}
+	return mcp.NewToolResultStructured(result, fmt.Sprintf("Response: %s", string(data))), nil
+}
+
+// requestSummary is one entry of jellyseerr_list_requests. Seasons is set
+// for TV requests.
+type requestSummary struct {
+	RequestID   int             `json:"requestId"`
+	Status      string          `json:"status"`
+	MediaType   string          `json:"mediaType"`
+	TmdbID      int             `json:"tmdbId"`
+	Title       string          `json:"title"`
+	RequestedBy string          `json:"requestedBy"`
+	Seasons     []requestSeason `json:"seasons,omitempty"`
+}
+
+// requestSeason is one requested season: the request's status for it
+// (pending/approved/declined) and how much of it is available so far.
+type requestSeason struct {
+	Season       int    `json:"season"`
+	Status       string `json:"status"`
+	Availability string `json:"availability,omitempty"`
+}
+
+type requestList struct {
+	Requests []requestSummary `json:"requests"`
+}
+
+func handleJellyseerrListRequests(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	limit := 20
+	if l, ok := args["limit"].(float64); ok {
+		limit = int(l)
+	}
+
+	data, err := jellyseerrRequest(ctx, "GET", fmt.Sprintf("/request?take=%d", limit), nil)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var result map[string]interface{}
+	json.Unmarshal(data, &result)
+
+	results, _ := result["results"].([]interface{})
+	out := newListOutput(req, "Request ID", "Status", "Type", "Title", "TMDB ID", "Requested By", "Seasons")
+	list := requestList{Requests: []requestSummary{}}
+
+	var keys []titleKey
+	for _, r := range results {
+		if media, ok := r.(map[string]interface{})["media"].(map[string]interface{}); ok {
+			mediaType, _ := media["mediaType"].(string)
+			tmdbID, _ := media["tmdbId"].(float64)
+			keys = append(keys, titleKey{mediaType, int(tmdbID)})
+		}
+	}
+	prefetchTitles(ctx, keys)
+
+	for _, r := range results {
+		item := r.(map[string]interface{})
 		reqID := int(item["id"].(float64))
-		status := statusMap[int(item["status"].(float64))]
+		status := requestStatusNames[int(item["status"].(float64))]
 		media := item["media"].(map[string]interface{})
 		mediaType := media["mediaType"].(string)
 		tmdbID := int(media["tmdbId"].(float64))
 
-		user := "Unknown"
-		if rb, ok := item["requestedBy"].(map[string]interface{}); ok {
-			if dn, ok := rb["displayName"].(string); ok {
-				user = dn
+		user := "Unknown"
+		if rb, ok := item["requestedBy"].(map[string]interface{}); ok {
+			if dn, ok := rb["displayName"].(string); ok {
+				user = dn
+			}
+		}
+
+		summary := requestSummary{RequestID: reqID, Status: status, MediaType: mediaType, TmdbID: tmdbID, Title: jellyseerrTitle(ctx, mediaType, tmdbID), RequestedBy: user}
+		if summary.Title == "" {
+			summary.Title = fmt.Sprintf("TMDB %d", tmdbID)
+		}
+		if mediaType == "tv" {
+			summary.Seasons = requestSeasons(item, media)
+		}
+
+		var seasons []string
+		for _, se := range summary.Seasons {
+			label := fmt.Sprintf("S%d %s", se.Season, se.Status)
+			if se.Availability != "" {
+				label += ", " + se.Availability
+			}
+			seasons = append(seasons, label)
+		}
+		line := fmt.Sprintf("  #%d [%s] %s %s (TMDB: %d) - by %s", reqID, status, mediaType, summary.Title, tmdbID, user)
+		if len(seasons) > 0 {
+			line += "\n    " + strings.Join(seasons, "; ")
+		}
+		out.add(line, strconv.Itoa(reqID), status, mediaType, summary.Title, strconv.Itoa(tmdbID), user, strings.Join(seasons, "; "))
+		list.Requests = append(list.Requests, summary)
+	}
+
+	return mcp.NewToolResultStructured(list, out.render(fmt.Sprintf("Requests (%d):", len(results)), "")), nil
+}
+
+// Jellyseerr request statuses, for whole requests and requested seasons
+var requestStatusNames = map[int]string{1: "Pending", 2: "Approved", 3: "Declined", 4: "Failed", 5: "Completed"}
+
+// requestSeasons lists the seasons a TV request covers, with each season's
+// request status and its availability from the media's season list.
+func requestSeasons(request, media map[string]interface{}) []requestSeason {
+	availability := map[int]string{}
+	mediaSeasons, _ := media["seasons"].([]interface{})
+	for _, ms := range mediaSeasons {
+		ms := ms.(map[string]interface{})
+		n, _ := ms["seasonNumber"].(float64)
+		st, _ := ms["status"].(float64)
+		availability[int(n)] = mediaStatusNames[int(st)]
+	}
+
+	var seasons []requestSeason
+	requested, _ := request["seasons"].([]interface{})
+	for _, rs := range requested {
+		rs := rs.(map[string]interface{})
+		n, _ := rs["seasonNumber"].(float64)
+		st, _ := rs["status"].(float64)
+		seasons = append(seasons, requestSeason{
+			Season:       int(n),
+			Status:       strings.ToLower(requestStatusNames[int(st)]),
+			Availability: availability[int(n)],
+		})
+	}
+	sort.Slice(seasons, func(i, j int) bool { return seasons[i].Season < seasons[j].Season })
+	return seasons
+}
+
+// modifiedRequest is the structured result of jellyseerr_modify_request,
+// showing the request's settings after the change.
+type modifiedRequest struct {
+	RequestID  int    `json:"requestId"`
+	MediaType  string `json:"mediaType"`
+	Seasons    []int  `json:"seasons,omitempty"`
+	ServerID   int    `json:"serverId,omitempty"`
+	ProfileID  int    `json:"profileId,omitempty"`
+	RootFolder string `json:"rootFolder,omitempty"`
+	Tags       []int  `json:"tags,omitempty"`
+}
+
+func handleJellyseerrModifyRequest(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	requestID := int(args["request_id"].(float64))
+
+	data, err := jellyseerrRequest(ctx, "GET", fmt.Sprintf("/request/%d", requestID), nil)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var current map[string]interface{}
+	json.Unmarshal(data, &current)
+
+	media, _ := current["media"].(map[string]interface{})
+	mediaType, _ := media["mediaType"].(string)
+	if mediaType == "" {
+		return mcp.NewToolResultError(fmt.Sprintf("Request %d has no media", requestID)), nil
+	}
+
+	// PUT replaces the whole request, so start from its current settings
+	result := modifiedRequest{RequestID: requestID, MediaType: mediaType}
+	if v, ok := current["serverId"].(float64); ok {
+		result.ServerID = int(v)
+	}
+	if v, ok := current["profileId"].(float64); ok {
+		result.ProfileID = int(v)
+	}
+	result.RootFolder, _ = current["rootFolder"].(string)
+	if tags, ok := current["tags"].([]interface{}); ok {
+		for _, t := range tags {
+			if id, ok := t.(float64); ok {
+				result.Tags = append(result.Tags, int(id))
+			}
+		}
+	}
+	if seasons, ok := current["seasons"].([]interface{}); ok {
+		for _, se := range seasons {
+			season, _ := se.(map[string]interface{})
+			if n, ok := season["seasonNumber"].(float64); ok {
+				result.Seasons = append(result.Seasons, int(n))
+			}
+		}
+	}
+
+	var changes []string
+	addSeasons, hasAdd := args["add_seasons"].([]interface{})
+	replaceSeasons, hasReplace := args["seasons"].([]interface{})
+	if (hasAdd || hasReplace) && mediaType != "tv" {
+		return mcp.NewToolResultError("Seasons can only be changed on TV requests"), nil
+	}
+	if hasReplace {
+		result.Seasons = nil
+		for _, n := range replaceSeasons {
+			season, ok := n.(float64)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("seasons must be season numbers, got %v", n)), nil
 			}
+			result.Seasons = append(result.Seasons, int(season))
 		}
+		changes = append(changes, "replaced seasons")
+	}
+	for _, n := range addSeasons {
+		f, ok := n.(float64)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("add_seasons must be season numbers, got %v", n)), nil
+		}
+		season := int(f)
+		if !containsInt(result.Seasons, season) {
+			result.Seasons = append(result.Seasons, season)
+			changes = append(changes, fmt.Sprintf("added season %d", season))
+		}
+	}
+	sort.Ints(result.Seasons)
+
+	if v, ok := args["server_id"].(float64); ok {
+		result.ServerID = int(v)
+		changes = append(changes, fmt.Sprintf("server %d", result.ServerID))
+	}
+	if v, ok := args["profile_id"].(float64); ok {
+		result.ProfileID = int(v)
+		changes = append(changes, fmt.Sprintf("profile %d", result.ProfileID))
+	}
+	if v, ok := args["root_folder"].(string); ok && v != "" {
+		result.RootFolder = v
+		changes = append(changes, "root folder "+v)
+	}
+	if lang, ok := args["audio_language"].(string); ok && lang != "" {
+		profile, ok := config.AudioLanguages[lang]
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("Unknown audio language %q (configured: %s)", lang, strings.Join(audioLanguageNames(), ", "))), nil
+		}
+		profileID := profile.MovieProfileID
+		if mediaType == "tv" {
+			profileID = profile.TVProfileID
+		}
+		if profileID > 0 {
+			result.ProfileID = profileID
+		}
+		if len(profile.Tags) > 0 {
+			result.Tags = profile.Tags
+		}
+		changes = append(changes, "audio language "+lang)
+	}
+
+	if len(changes) == 0 {
+		return mcp.NewToolResultError("Nothing to change; pass add_seasons, seasons, server_id, profile_id, root_folder, or audio_language"), nil
+	}
+
+	payload := map[string]interface{}{
+		"mediaType": mediaType,
+		"is4k":      current["is4k"] == true,
+	}
+	if mediaType == "tv" {
+		payload["seasons"] = result.Seasons
+	}
+	if result.ServerID > 0 {
+		payload["serverId"] = result.ServerID
+	}
+	if result.ProfileID > 0 {
+		payload["profileId"] = result.ProfileID
+	}
+	if result.RootFolder != "" {
+		payload["rootFolder"] = result.RootFolder
+	}
+	if len(result.Tags) > 0 {
+		payload["tags"] = result.Tags
+	}
+	body, _ := json.Marshal(payload)
 
-		lines = append(lines, fmt.Sprintf("  #%d [%s] %s (TMDB: %d) - by %s", reqID, status, mediaType, tmdbID, user))
+	if _, err := jellyseerrRequest(ctx, "PUT", fmt.Sprintf("/request/%d", requestID), strings.NewReader(string(body))); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+	text := fmt.Sprintf("Updated request #%d: %s.", requestID, strings.Join(changes, ", "))
+	if mediaType == "tv" {
+		var seasons []string
+		for _, n := range result.Seasons {
+			seasons = append(seasons, strconv.Itoa(n))
+		}
+		text += "\nSeasons now requested: " + strings.Join(seasons, ", ")
+	}
+	return mcp.NewToolResultStructured(result, text), nil
 }
 
 // ============================================================================
 // Sonarr
 // ============================================================================
 
-func sonarrRequest(method, endpoint string, body io.Reader) ([]byte, error) {
-	headers := map[string]string{
-		"X-Api-Key":    config.SonarrAPIKey,
-		"Content-Type": "application/json",
+func sonarrRequest(ctx context.Context, method, endpoint string, body io.Reader) ([]byte, error) {
+	fetch := func(ctx context.Context) ([]byte, error) {
+		return doAPIKeyRequest(ctx, method, sonarr.baseURL+"/api/v3"+endpoint, sonarr.keys, body)
+	}
+	if data, ok := cachedLibrary("Sonarr", sonarr.baseURL, method, endpoint, fetch); ok {
+		return data, nil
+	}
+	data, err := fetch(ctx)
+	if err == nil {
+		updateLibraryCache("Sonarr", sonarr.baseURL, method, endpoint, data)
 	}
-	return doRequest(method, config.SonarrURL+"/api/v3"+endpoint, headers, body)
+	return data, err
+}
+
+type sonarrService struct {
+	baseURL string
+	apiKey  string
+	keys    *apiKeyPair
+}
+
+var sonarr = &sonarrService{}
+
+func (*sonarrService) Name() string  { return "Sonarr" }
+func (s *sonarrService) URL() string { return s.baseURL }
+
+func (s *sonarrService) Configure() bool {
+	s.baseURL = getEnv("SONARR_URL", "http://localhost:8989")
+	s.apiKey = os.Getenv("SONARR_API_KEY")
+	s.keys = newAPIKeyPair("Sonarr", s.apiKey, os.Getenv("SONARR_API_KEY_ALT"))
+	return s.apiKey != ""
+}
+
+func (*sonarrService) RegisterTools(s *server.MCPServer) { registerSonarrTools(s) }
+
+func (*sonarrService) arr() arrService {
+	return arrService{Name: "Sonarr", Request: sonarrRequest, Category: "tvCategory"}
+}
+
+func (s *sonarrService) Status(ctx context.Context, st *serviceStatus) { arrStatus(ctx, s.arr(), st) }
+
+func (*sonarrService) HealthCheck(ctx context.Context) (string, error) {
+	return versionField(sonarrRequest(ctx, "GET", "/system/status", nil))
 }
 
 func registerSonarrTools(s *server.MCPServer) {
@@ -288,6 +2150,9 @@ func registerSonarrTools(s *server.MCPServer) {
 	s.AddTool(
 		mcp.NewTool("sonarr_list_series",
 			mcp.WithDescription("List all TV series in Sonarr"),
+			formatOption(),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[seriesList](),
 		),
 		handleSonarrListSeries,
 	)
@@ -295,142 +2160,675 @@ func registerSonarrTools(s *server.MCPServer) {
 	// Get Series
 	s.AddTool(
 		mcp.NewTool("sonarr_get_series",
-			mcp.WithDescription("Get details for a specific series in Sonarr"),
+			mcp.WithDescription("Get details for a specific series in Sonarr, with its poster and fanart so the right show can be confirmed visually"),
 			mcp.WithNumber("series_id", mcp.Required(), mcp.Description("Sonarr series ID")),
+			mcp.WithBoolean("include_images", mcp.Description("Attach the poster and fanart images (default true)")),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[seriesDetails](),
 		),
 		handleSonarrGetSeries,
 	)
 
-	// Search Series (trigger search for releases)
-	s.AddTool(
-		mcp.NewTool("sonarr_search_series",
-			mcp.WithDescription("Trigger a search for releases for a series in Sonarr"),
-			mcp.WithNumber("series_id", mcp.Required(), mcp.Description("Sonarr series ID")),
-		),
-		handleSonarrSearchSeries,
-	)
+	// List Episodes
+	s.AddTool(
+		mcp.NewTool("sonarr_list_episodes",
+			mcp.WithDescription("List episodes of a series in Sonarr with their download status"),
+			mcp.WithNumber("series_id", mcp.Required(), mcp.Description("Sonarr series ID")),
+			mcp.WithNumber("season", mcp.Description("Season number (optional, omit for all)")),
+			mcp.WithBoolean("include_specials", mcp.Description("Include specials (season 0) when listing all seasons (default false)")),
+			mcp.WithBoolean("missing_only", mcp.Description("Only list aired episodes without a file (default false)")),
+			mcp.WithNumber("overview_length", mcp.Description("Truncate episode overviews to this many characters (default 150, 0 to omit)")),
+			formatOption(),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[episodeList](),
+		),
+		handleSonarrListEpisodes,
+	)
+
+	// Calendar
+	s.AddTool(
+		mcp.NewTool("sonarr_calendar",
+			mcp.WithDescription("List upcoming (or recent) episodes for series in Sonarr, with titles, overviews, and runtimes"),
+			mcp.WithNumber("days", mcp.Description("Number of days ahead to include (default 7; negative to look back)")),
+			mcp.WithBoolean("include_specials", mcp.Description("Include specials (season 0) (default false)")),
+			mcp.WithNumber("overview_length", mcp.Description("Truncate episode overviews to this many characters (default 150, 0 to omit)")),
+			formatOption(),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[episodeList](),
+		),
+		handleSonarrCalendar,
+	)
+
+	// Monitor Episodes
+	s.AddTool(
+		mcp.NewTool("sonarr_monitor_episodes",
+			mcp.WithDescription("Set whether episodes of a series are monitored in Sonarr"),
+			mcp.WithNumber("series_id", mcp.Required(), mcp.Description("Sonarr series ID")),
+			mcp.WithBoolean("monitored", mcp.Required(), mcp.Description("true to monitor, false to unmonitor")),
+			mcp.WithNumber("season", mcp.Description("Season number (optional, omit for all)")),
+			mcp.WithBoolean("include_specials", mcp.Description("Also apply to specials (season 0) when changing all seasons (default false)")),
+			mcp.WithOutputSchema[monitorResult](),
+		),
+		handleSonarrMonitorEpisodes,
+	)
+
+	// Search Series (trigger search for releases)
+	s.AddTool(
+		mcp.NewTool("sonarr_search_series",
+			mcp.WithDescription("Trigger a search for releases for a series in Sonarr"),
+			mcp.WithNumber("series_id", mcp.Required(), mcp.Description("Sonarr series ID")),
+			mcp.WithBoolean("include_specials", mcp.Description("Also search monitored specials (season 0) (default true)")),
+			mcp.WithOutputSchema[searchCommand](),
+		),
+		withQuietHours(searchAutomatic, handleSonarrSearchSeries),
+	)
+
+	// Interactive Search (get available releases)
+	s.AddTool(
+		mcp.NewTool("sonarr_get_releases", append([]mcp.ToolOption{
+			mcp.WithDescription("Get available releases for a series (interactive search), with release age and warnings for releases that previously failed, were blocklisted, or would need heavy transcoding"),
+			mcp.WithNumber("series_id", mcp.Required(), mcp.Description("Sonarr series ID")),
+			mcp.WithNumber("season", mcp.Description("Season number (optional, omit for all)")),
+			mcp.WithNumber("max_wait_seconds", mcp.Description("Return after this many seconds even if indexers are still being searched; the search keeps running and calling again with the same arguments collects its results")),
+			mcp.WithOutputSchema[releaseList](),
+		}, playbackOptions()...)...),
+		withQuietHours(searchInteractive, handleSonarrGetReleases),
+	)
+
+	// Download Release
+	s.AddTool(
+		mcp.NewTool("sonarr_download_release", append([]mcp.ToolOption{
+			mcp.WithDescription("Download a specific release by GUID. Say why it was chosen with reason and comment; grabs are recorded for ultimarr_grab_log."),
+			mcp.WithString("guid", mcp.Required(), mcp.Description("Release GUID from sonarr_get_releases")),
+			mcp.WithNumber("indexer_id", mcp.Required(), mcp.Description("Indexer ID from the release")),
+			mcp.WithNumber("series_id", mcp.Required(), mcp.Description("Sonarr series ID")),
+			mcp.WithOutputSchema[releaseGrab](),
+		}, grabOptions()...)...),
+		handleSonarrDownloadRelease,
+	)
+
+	// Mark Failed
+	s.AddTool(
+		mcp.NewTool("sonarr_mark_failed",
+			mcp.WithDescription("Mark a grabbed download as failed, the proper way to redo a bad grab: Sonarr blocklists the release, removes the download, and searches for another. Identify the grab by history_id, download_id, or series_id (its most recent grab)."),
+			mcp.WithNumber("history_id", mcp.Description("Sonarr history record ID of the grab")),
+			mcp.WithString("download_id", mcp.Description("Download client ID of the grab (the torrent hash or SABnzbd nzo_id)")),
+			mcp.WithNumber("series_id", mcp.Description("Sonarr series ID; its most recent grab is marked failed")),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithOutputSchema[markFailedResult](),
+		),
+		withQuietHours(searchAutomatic, markFailedHandler("Sonarr", sonarrRequest, "series_id", "/history/series?seriesId=%d")),
+	)
+
+	// Queue
+	s.AddTool(
+		mcp.NewTool("sonarr_queue",
+			mcp.WithDescription("Get current download queue in Sonarr"),
+			formatOption(),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[queueList](),
+		),
+		handleSonarrQueue,
+	)
+
+	// Refresh and Verify
+	s.AddTool(
+		mcp.NewTool("sonarr_refresh_and_verify",
+			mcp.WithDescription("Refresh series metadata, rescan files, and report mismatches between expected and on-disk episodes (use when a show has wrong episode names, missing episodes, or missing artwork)"),
+			mcp.WithNumber("series_id", mcp.Required(), mcp.Description("Sonarr series ID")),
+			mcp.WithOutputSchema[verifyResult](),
+		),
+		handleSonarrRefreshAndVerify,
+	)
+
+	// Add Existing
+	s.AddTool(
+		mcp.NewTool("sonarr_add_existing",
+			mcp.WithDescription("Add a series whose files are already on disk (e.g. after copying an old library to a new server): adds it at that folder without searching, then rescans and imports the files. The series is identified by its TVDB ID, an ID tag in the folder name like {tvdb-123}, or the folder name."),
+			mcp.WithString("folder", mcp.Required(), mcp.Description("Full path of the series folder as Sonarr sees it")),
+			mcp.WithNumber("tvdb_id", mcp.Description("TVDB ID (default: from the folder name)")),
+			mcp.WithNumber("profile_id", mcp.Description("Quality profile ID (default: from ULTIMARR_DEFAULTS)")),
+			mcp.WithString("monitor", mcp.Enum("all", "future", "missing", "existing", "firstSeason", "latestSeason", "pilot", "none"), mcp.Description("Which episodes to monitor (default all)")),
+			mcp.WithOutputSchema[existingResult](),
+		),
+		sonarrExisting.handler(),
+	)
+
+	// Episode Gaps
+	s.AddTool(
+		mcp.NewTool("sonarr_find_gaps",
+			mcp.WithDescription("Find broken seasons before someone hits them: aired episodes with no file between episodes that do have one, and files much smaller than the rest of their season (likely samples or bad imports). Checks every series with files unless series_id is given."),
+			mcp.WithNumber("series_id", mcp.Description("Only check this series")),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[gapReport](),
+		),
+		handleSonarrFindGaps,
+	)
+
+	// Bulk Delete
+	s.AddTool(
+		mcp.NewTool("sonarr_bulk_delete", append([]mcp.ToolOption{
+			mcp.WithDescription("Delete series matching filter criteria. Without confirm_token this is a dry run that lists matches, reclaimable space, and a confirmation token; pass that token to delete exactly those series."),
+		}, bulkDeleteOptions("Sonarr")...)...),
+		handleSonarrBulkDelete,
+	)
+
+	if config.AdminTools {
+		registerSystemTools(s, "sonarr", "Sonarr", sonarrRequest)
+	}
+}
+
+// seriesSummary is one entry of sonarr_list_series.
+type seriesSummary struct {
+	ID        int    `json:"id"`
+	Title     string `json:"title"`
+	Year      int    `json:"year"`
+	Status    string `json:"status"`
+	Monitored bool   `json:"monitored"`
+}
+
+type seriesList struct {
+	Series []seriesSummary `json:"series"`
+}
+
+func handleSonarrListSeries(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	data, err := sonarrRequest(ctx, "GET", "/series", nil)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var series []map[string]interface{}
+	json.Unmarshal(data, &series)
+
+	out := newListOutput(req, "ID", "Title", "Year", "Status", "Monitored")
+	list := seriesList{Series: []seriesSummary{}}
+
+	for _, s := range series {
+		id := int(s["id"].(float64))
+		title := s["title"].(string)
+		year := 0
+		if y, ok := s["year"].(float64); ok {
+			year = int(y)
+		}
+		status := s["status"].(string)
+		monitored := s["monitored"].(bool)
+
+		monStr := ""
+		if !monitored {
+			monStr = " [unmonitored]"
+		}
+
+		out.add(fmt.Sprintf("  [%d] %s (%d) - %s%s", id, title, year, status, monStr),
+			strconv.Itoa(id), title, strconv.Itoa(year), status, strconv.FormatBool(monitored))
+		list.Series = append(list.Series, seriesSummary{ID: id, Title: title, Year: year, Status: status, Monitored: monitored})
+	}
+
+	return mcp.NewToolResultStructured(list, out.render(fmt.Sprintf("Series in Sonarr (%d):", len(series)), "")), nil
+}
+
+// seriesDetails is the structured result of sonarr_get_series. Episode counts
+// exclude specials, which are counted separately.
+type seriesDetails struct {
+	ID           int    `json:"id"`
+	Title        string `json:"title"`
+	Year         int    `json:"year"`
+	Status       string `json:"status"`
+	Monitored    bool   `json:"monitored"`
+	Path         string `json:"path"`
+	Episodes     int    `json:"episodes"`
+	EpisodeFiles int    `json:"episodeFiles"`
+	Specials     int    `json:"specials"`
+	SpecialFiles int    `json:"specialFiles"`
+	Rating       string `json:"rating,omitempty"`
+	PosterURL    string `json:"posterUrl,omitempty"`
+	FanartURL    string `json:"fanartUrl,omitempty"`
+}
+
+func handleSonarrGetSeries(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	seriesID := int(args["series_id"].(float64))
+
+	data, err := sonarrRequest(ctx, "GET", fmt.Sprintf("/series/%d", seriesID), nil)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var s map[string]interface{}
+	json.Unmarshal(data, &s)
+
+	title := s["title"].(string)
+	year := int(s["year"].(float64))
+	status := s["status"].(string)
+	path := s["path"].(string)
+	monitored := s["monitored"].(bool)
+
+	// Count specials separately so they don't make a complete show look incomplete
+	episodeCount, episodeFileCount := 0, 0
+	specialCount, specialFileCount := 0, 0
+	if seasons, ok := s["seasons"].([]interface{}); ok {
+		for _, se := range seasons {
+			season := se.(map[string]interface{})
+			stats, ok := season["statistics"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			ec, _ := stats["episodeCount"].(float64)
+			efc, _ := stats["episodeFileCount"].(float64)
+			if int(season["seasonNumber"].(float64)) == 0 {
+				specialCount += int(ec)
+				specialFileCount += int(efc)
+			} else {
+				episodeCount += int(ec)
+				episodeFileCount += int(efc)
+			}
+		}
+	}
+
+	info := fmt.Sprintf(`**%s** (%d)
+ID: %d
+Status: %s
+Monitored: %v
+Path: %s
+Episodes: %d/%d downloaded (excluding specials)`, title, year, seriesID, status, monitored, path, episodeFileCount, episodeCount)
+	if specialCount > 0 {
+		info += fmt.Sprintf("\nSpecials: %d/%d downloaded", specialFileCount, specialCount)
+	}
+	rating, _ := s["certification"].(string)
+	if rating != "" {
+		info += "\nRated: " + rating
+		if aboveMaxRating(rating) {
+			info += fmt.Sprintf(" (above the %s limit)", config.MaxRating)
+		}
+	}
+
+	details := seriesDetails{
+		ID: seriesID, Title: title, Year: year, Status: status, Monitored: monitored, Path: path,
+		Episodes: episodeCount, EpisodeFiles: episodeFileCount,
+		Specials: specialCount, SpecialFiles: specialFileCount, Rating: rating,
+	}
+	fetchImages := true
+	if v, ok := args["include_images"].(bool); ok {
+		fetchImages = v
+	}
+	art := itemArtwork(ctx, s, sonarr.baseURL, sonarr.keys, fetchImages)
+	details.PosterURL, details.FanartURL = art.PosterURL, art.FanartURL
+
+	result := mcp.NewToolResultStructured(details, info)
+	result.Content = append(result.Content, art.Images...)
+	return result, nil
+}
+
+func handleSonarrSearchSeries(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	seriesID := int(args["series_id"].(float64))
+	includeSpecials := true
+	if v, ok := args["include_specials"].(bool); ok {
+		includeSpecials = v
+	}
+
+	if !includeSpecials {
+		return sonarrSearchRegularSeasons(ctx, seriesID)
+	}
+
+	payload := map[string]interface{}{
+		"name":     "SeriesSearch",
+		"seriesId": seriesID,
+	}
+	body, _ := json.Marshal(payload)
+
+	data, err := sonarrRequest(ctx, "POST", "/command", strings.NewReader(string(body)))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var result map[string]interface{}
+	json.Unmarshal(data, &result)
+
+	id, _ := result["id"].(float64)
+	return mcp.NewToolResultStructured(searchCommand{CommandIDs: []int{int(id)}}, fmt.Sprintf("Search triggered. Command ID: %d", int(id))), nil
+}
+
+// sonarrSearchRegularSeasons searches each monitored season except specials,
+// since SeriesSearch always includes monitored specials.
+func sonarrSearchRegularSeasons(ctx context.Context, seriesID int) (*mcp.CallToolResult, error) {
+	data, err := sonarrRequest(ctx, "GET", fmt.Sprintf("/series/%d", seriesID), nil)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var series map[string]interface{}
+	json.Unmarshal(data, &series)
+
+	var commandIDs []string
+	cmd := searchCommand{CommandIDs: []int{}}
+	seasons, _ := series["seasons"].([]interface{})
+	for _, se := range seasons {
+		season := se.(map[string]interface{})
+		number := int(season["seasonNumber"].(float64))
+		if monitored, _ := season["monitored"].(bool); number == 0 || !monitored {
+			continue
+		}
+
+		payload := map[string]interface{}{
+			"name":         "SeasonSearch",
+			"seriesId":     seriesID,
+			"seasonNumber": number,
+		}
+		body, _ := json.Marshal(payload)
+
+		data, err := sonarrRequest(ctx, "POST", "/command", strings.NewReader(string(body)))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		var result map[string]interface{}
+		json.Unmarshal(data, &result)
+		id, _ := result["id"].(float64)
+		commandIDs = append(commandIDs, fmt.Sprintf("S%02d: %d", number, int(id)))
+		cmd.CommandIDs = append(cmd.CommandIDs, int(id))
+		cmd.Seasons = append(cmd.Seasons, number)
+	}
+
+	if len(commandIDs) == 0 {
+		return mcp.NewToolResultStructured(cmd, "No monitored seasons to search (specials excluded)."), nil
+	}
+	return mcp.NewToolResultStructured(cmd, fmt.Sprintf("Search triggered for %d season(s), specials excluded. Command IDs: %s", len(commandIDs), strings.Join(commandIDs, ", "))), nil
+}
+
+// sonarrEpisodes fetches a series' episodes, optionally limited to a season.
+// When listing all seasons, specials are dropped unless includeSpecials is set.
+func sonarrEpisodes(ctx context.Context, seriesID int, season *int, includeSpecials bool) ([]map[string]interface{}, error) {
+	endpoint := fmt.Sprintf("/episode?seriesId=%d", seriesID)
+	if season != nil {
+		endpoint += fmt.Sprintf("&seasonNumber=%d", *season)
+	}
+
+	data, err := sonarrRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
 
-	// Interactive Search (get available releases)
-	s.AddTool(
-		mcp.NewTool("sonarr_get_releases",
-			mcp.WithDescription("Get available releases for a series (interactive search)"),
-			mcp.WithNumber("series_id", mcp.Required(), mcp.Description("Sonarr series ID")),
-			mcp.WithNumber("season", mcp.Description("Season number (optional, omit for all)")),
-		),
-		handleSonarrGetReleases,
-	)
+	var episodes []map[string]interface{}
+	json.Unmarshal(data, &episodes)
 
-	// Download Release
-	s.AddTool(
-		mcp.NewTool("sonarr_download_release",
-			mcp.WithDescription("Download a specific release by GUID"),
-			mcp.WithString("guid", mcp.Required(), mcp.Description("Release GUID from sonarr_get_releases")),
-			mcp.WithNumber("indexer_id", mcp.Required(), mcp.Description("Indexer ID from the release")),
-			mcp.WithNumber("series_id", mcp.Required(), mcp.Description("Sonarr series ID")),
-		),
-		handleSonarrDownloadRelease,
-	)
+	if season != nil || includeSpecials {
+		return episodes, nil
+	}
 
-	// Queue
-	s.AddTool(
-		mcp.NewTool("sonarr_queue",
-			mcp.WithDescription("Get current download queue in Sonarr"),
-		),
-		handleSonarrQueue,
-	)
+	var filtered []map[string]interface{}
+	for _, e := range episodes {
+		if int(e["seasonNumber"].(float64)) != 0 {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered, nil
 }
 
-func handleSonarrListSeries(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	data, err := sonarrRequest("GET", "/series", nil)
+// episode is one entry of sonarr_list_episodes or sonarr_calendar. Status is
+// "downloaded", "missing", or "unaired".
+type episode struct {
+	Series    string     `json:"series,omitempty"`
+	Season    int        `json:"season"`
+	Episode   int        `json:"episode"`
+	Title     string     `json:"title"`
+	Status    string     `json:"status"`
+	Monitored bool       `json:"monitored"`
+	AirDate   *time.Time `json:"airDate,omitempty"`
+	Runtime   int        `json:"runtime,omitempty"` // minutes
+	Overview  string     `json:"overview,omitempty"`
+}
+
+type episodeList struct {
+	Episodes []episode `json:"episodes"`
+}
+
+func handleSonarrListEpisodes(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	seriesID := int(args["series_id"].(float64))
+	includeSpecials, _ := args["include_specials"].(bool)
+	missingOnly, _ := args["missing_only"].(bool)
+
+	var season *int
+	if v, ok := args["season"].(float64); ok {
+		n := int(v)
+		season = &n
+	}
+
+	episodes, err := sonarrEpisodes(ctx, seriesID, season, includeSpecials)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	var series []map[string]interface{}
-	json.Unmarshal(data, &series)
-
-	var lines []string
-	lines = append(lines, fmt.Sprintf("Series in Sonarr (%d):\n", len(series)))
+	overviewLength := 150
+	if v, ok := args["overview_length"].(float64); ok {
+		overviewLength = int(v)
+	}
 
-	for _, s := range series {
-		id := int(s["id"].(float64))
-		title := s["title"].(string)
-		year := 0
-		if y, ok := s["year"].(float64); ok {
-			year = int(y)
+	out := newListOutput(req, "Season", "Episode", "Status", "Monitored", "Title", "Runtime", "Overview")
+	list := episodeList{Episodes: []episode{}}
+	now := time.Now()
+	for _, e := range episodes {
+		hasFile, _ := e["hasFile"].(bool)
+		monitored, _ := e["monitored"].(bool)
+		title, _ := e["title"].(string)
+		minutes, overview := episodeDetails(e, overviewLength)
+		runtime := formatRuntime(minutes)
+
+		aired := false
+		airDate := episodeAirDate(e)
+		if airDate != nil && airDate.Before(now) {
+			aired = true
 		}
-		status := s["status"].(string)
-		monitored := s["monitored"].(bool)
 
-		monStr := ""
+		status := "missing"
+		if hasFile {
+			status = "downloaded"
+		} else if !aired {
+			status = "unaired"
+		}
+		if missingOnly && status != "missing" {
+			continue
+		}
+		label := status
 		if !monitored {
-			monStr = " [unmonitored]"
+			label += ", unmonitored"
 		}
 
-		lines = append(lines, fmt.Sprintf("  [%d] %s (%d) - %s%s", id, title, year, status, monStr))
+		season := int(e["seasonNumber"].(float64))
+		number := int(e["episodeNumber"].(float64))
+		line := fmt.Sprintf("  S%02dE%02d [%s] %s", season, number, label, title)
+		if runtime != "" {
+			line += " (" + runtime + ")"
+		}
+		if overview != "" {
+			line += "\n      " + overview
+		}
+		out.add(line, strconv.Itoa(season), strconv.Itoa(number), status, strconv.FormatBool(monitored), title, runtime, overview)
+		list.Episodes = append(list.Episodes, episode{
+			Season: season, Episode: number, Title: title, Status: status, Monitored: monitored,
+			AirDate: airDate, Runtime: minutes, Overview: overview,
+		})
+	}
+
+	header := fmt.Sprintf("Episodes (%d):", len(out.rows))
+	if missingOnly {
+		header = fmt.Sprintf("Missing episodes (%d):", len(out.rows))
 	}
 
-	return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+	return mcp.NewToolResultStructured(list, out.render(header, "  (none)")), nil
 }
 
-func handleSonarrGetSeries(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// episodeDetails returns an episode's runtime in minutes (0 if unknown) and
+// its overview truncated to maxOverview characters (omitted when 0).
+func episodeDetails(e map[string]interface{}, maxOverview int) (int, string) {
+	runtime := 0
+	if r, ok := e["runtime"].(float64); ok && r > 0 {
+		runtime = int(r)
+	} else if series, ok := e["series"].(map[string]interface{}); ok {
+		if r, ok := series["runtime"].(float64); ok && r > 0 {
+			runtime = int(r)
+		}
+	}
+
+	overview := ""
+	if maxOverview > 0 {
+		if o, ok := e["overview"].(string); ok {
+			overview = truncate(o, maxOverview)
+		}
+	}
+	return runtime, overview
+}
+
+// formatRuntime renders a runtime in minutes as e.g. "45 min".
+func formatRuntime(minutes int) string {
+	if minutes <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d min", minutes)
+}
+
+// episodeAirDate parses an episode's airDateUtc, returning nil if it has none.
+func episodeAirDate(e map[string]interface{}) *time.Time {
+	a, ok := e["airDateUtc"].(string)
+	if !ok {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, a)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func handleSonarrCalendar(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args := req.GetArguments()
-	seriesID := int(args["series_id"].(float64))
+	days := 7
+	if v, ok := args["days"].(float64); ok {
+		days = int(v)
+	}
+	includeSpecials, _ := args["include_specials"].(bool)
+	overviewLength := 150
+	if v, ok := args["overview_length"].(float64); ok {
+		overviewLength = int(v)
+	}
+
+	start, end := time.Now(), time.Now().AddDate(0, 0, days)
+	if days < 0 {
+		start, end = end, start
+	}
 
-	data, err := sonarrRequest("GET", fmt.Sprintf("/series/%d", seriesID), nil)
+	data, err := sonarrRequest(ctx, "GET", fmt.Sprintf("/calendar?start=%s&end=%s&includeSeries=true",
+		start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339)), nil)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	var s map[string]interface{}
-	json.Unmarshal(data, &s)
+	var episodes []map[string]interface{}
+	json.Unmarshal(data, &episodes)
 
-	title := s["title"].(string)
-	year := int(s["year"].(float64))
-	status := s["status"].(string)
-	path := s["path"].(string)
-	monitored := s["monitored"].(bool)
+	out := newListOutput(req, "Air Date", "Series", "Season", "Episode", "Title", "Downloaded", "Runtime", "Overview")
+	list := episodeList{Episodes: []episode{}}
+	now := time.Now()
+	for _, e := range episodes {
+		season := int(e["seasonNumber"].(float64))
+		if season == 0 && !includeSpecials {
+			continue
+		}
+		number := int(e["episodeNumber"].(float64))
+		title, _ := e["title"].(string)
+		hasFile, _ := e["hasFile"].(bool)
+		monitored, _ := e["monitored"].(bool)
+		seriesTitle := ""
+		if series, ok := e["series"].(map[string]interface{}); ok {
+			seriesTitle, _ = series["title"].(string)
+		}
+		airDate := ""
+		airTime := episodeAirDate(e)
+		if airTime != nil {
+			airDate = airTime.Local().Format("Mon 2006-01-02 15:04")
+		}
+		minutes, overview := episodeDetails(e, overviewLength)
+		runtime := formatRuntime(minutes)
+
+		status := "missing"
+		if hasFile {
+			status = "downloaded"
+		} else if airTime == nil || airTime.After(now) {
+			status = "unaired"
+		}
 
-	episodeCount := 0
-	episodeFileCount := 0
-	if stats, ok := s["statistics"].(map[string]interface{}); ok {
-		if ec, ok := stats["episodeCount"].(float64); ok {
-			episodeCount = int(ec)
+		line := fmt.Sprintf("  %s  %s S%02dE%02d - %s", airDate, seriesTitle, season, number, title)
+		if runtime != "" {
+			line += " (" + runtime + ")"
 		}
-		if efc, ok := stats["episodeFileCount"].(float64); ok {
-			episodeFileCount = int(efc)
+		if hasFile {
+			line += " [downloaded]"
+		}
+		if overview != "" {
+			line += "\n      " + overview
 		}
+		out.add(line, airDate, seriesTitle, strconv.Itoa(season), strconv.Itoa(number), title, strconv.FormatBool(hasFile), runtime, overview)
+		list.Episodes = append(list.Episodes, episode{
+			Series: seriesTitle, Season: season, Episode: number, Title: title, Status: status, Monitored: monitored,
+			AirDate: airTime, Runtime: minutes, Overview: overview,
+		})
 	}
 
-	info := fmt.Sprintf(`**%s** (%d)
-ID: %d
-Status: %s
-Monitored: %v
-Path: %s
-Episodes: %d/%d downloaded`, title, year, seriesID, status, monitored, path, episodeFileCount, episodeCount)
+	header := fmt.Sprintf("Upcoming episodes, next %d days (%d):", days, len(out.rows))
+	if days < 0 {
+		header = fmt.Sprintf("Episodes aired in the last %d days (%d):", -days, len(out.rows))
+	}
+	return mcp.NewToolResultStructured(list, out.render(header, "  (none)")), nil
+}
 
-	return mcp.NewToolResultText(info), nil
+// monitorResult is the structured result of sonarr_monitor_episodes.
+type monitorResult struct {
+	SeriesID  int  `json:"seriesId"`
+	Monitored bool `json:"monitored"`
+	Episodes  int  `json:"episodes"` // episodes changed
 }
 
-func handleSonarrSearchSeries(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func handleSonarrMonitorEpisodes(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args := req.GetArguments()
 	seriesID := int(args["series_id"].(float64))
+	monitored := args["monitored"].(bool)
+	includeSpecials, _ := args["include_specials"].(bool)
 
-	payload := map[string]interface{}{
-		"name":     "SeriesSearch",
-		"seriesId": seriesID,
+	var season *int
+	if v, ok := args["season"].(float64); ok {
+		n := int(v)
+		season = &n
 	}
-	body, _ := json.Marshal(payload)
 
-	data, err := sonarrRequest("POST", "/command", strings.NewReader(string(body)))
+	episodes, err := sonarrEpisodes(ctx, seriesID, season, includeSpecials)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	var result map[string]interface{}
-	json.Unmarshal(data, &result)
+	var ids []int
+	for _, e := range episodes {
+		ids = append(ids, int(e["id"].(float64)))
+	}
+	result := monitorResult{SeriesID: seriesID, Monitored: monitored, Episodes: len(ids)}
+	if len(ids) == 0 {
+		return mcp.NewToolResultStructured(result, "No matching episodes."), nil
+	}
+
+	payload := map[string]interface{}{
+		"episodeIds": ids,
+		"monitored":  monitored,
+	}
+	body, _ := json.Marshal(payload)
+
+	if _, err := sonarrRequest(ctx, "PUT", "/episode/monitor", strings.NewReader(string(body))); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Search triggered. Command ID: %v", result["id"])), nil
+	state := "monitored"
+	if !monitored {
+		state = "unmonitored"
+	}
+	return mcp.NewToolResultStructured(result, fmt.Sprintf("%d episode(s) now %s.", len(ids), state)), nil
 }
 
 func handleSonarrGetReleases(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -442,37 +2840,39 @@ func handleSonarrGetReleases(ctx context.Context, req mcp.CallToolRequest) (*mcp
 		endpoint += fmt.Sprintf("&seasonNumber=%d", int(season))
 	}
 
-	data, err := sonarrRequest("GET", endpoint, nil)
+	data, done, err := searchReleases(ctx, "Sonarr"+endpoint, maxWait(args), func(ctx context.Context) ([]byte, error) {
+		return doAPIKeyRequestTimeout(ctx, "GET", sonarr.baseURL+"/api/v3"+endpoint, sonarr.keys, nil, releaseSearchTimeout)
+	})
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	if !done && data == nil {
+		return releaseSearchPending(maxWait(args)), nil
+	}
 
 	var releases []map[string]interface{}
 	json.Unmarshal(data, &releases)
 
-	var lines []string
-	lines = append(lines, fmt.Sprintf("Available releases (%d):\n", len(releases)))
-
-	for i, r := range releases {
-		if i >= 20 {
-			lines = append(lines, fmt.Sprintf("\n  ... and %d more", len(releases)-20))
-			break
+	bad := knownBadReleases(ctx, "Sonarr", sonarrRequest, fmt.Sprintf("/history/series?seriesId=%d", seriesID), "seriesId", seriesID)
+	list := parseReleases(releases, bad)
+
+	if len(config.ClientProfiles) > 0 {
+		user, _ := args["user"].(string)
+		if data, err := sonarrRequest(ctx, "GET", fmt.Sprintf("/series/%d", seriesID), nil); err == nil {
+			var series map[string]interface{}
+			json.Unmarshal(data, &series)
+			runtime, _ := series["runtime"].(float64)
+			checkPlayback(ctx, &list, releases, user, func(r map[string]interface{}) int {
+				return releaseEpisodes(series, r) * int(runtime)
+			})
 		}
-		title := r["title"].(string)
-		size := int64(r["size"].(float64))
-		sizeMB := size / 1024 / 1024
-		seeders := 0
-		if s, ok := r["seeders"].(float64); ok {
-			seeders = int(s)
-		}
-		guid := r["guid"].(string)
-		indexerID := int(r["indexerId"].(float64))
-		indexer := r["indexer"].(string)
-
-		lines = append(lines, fmt.Sprintf("  [%d seeders] %s (%dMB) - %s\n    GUID: %s | Indexer: %d", seeders, title[:min(60, len(title))], sizeMB, indexer, guid, indexerID))
 	}
 
-	return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+	lines := formatReleases(list)
+	if !done {
+		lines = markReleasesPending(&list, lines, maxWait(args))
+	}
+	return mcp.NewToolResultStructured(list, strings.Join(lines, "\n")), nil
 }
 
 func handleSonarrDownloadRelease(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -488,16 +2888,21 @@ func handleSonarrDownloadRelease(ctx context.Context, req mcp.CallToolRequest) (
 	}
 	body, _ := json.Marshal(payload)
 
-	_, err := sonarrRequest("POST", "/release", strings.NewReader(string(body)))
+	data, err := sonarrRequest(ctx, "POST", "/release", strings.NewReader(string(body)))
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	return mcp.NewToolResultText("Download started successfully"), nil
+	text := "Download started successfully"
+	rec := grabRecord{Service: "Sonarr", SeriesID: seriesID, GUID: guid, IndexerID: indexerID, Release: releaseTitle(data)}
+	if note := recordGrab(ctx, args, rec, sonarrRequest, fmt.Sprintf("/series/%d", seriesID)); note != "" {
+		text += ". " + note
+	}
+	return mcp.NewToolResultStructured(releaseGrab{GUID: guid, IndexerID: indexerID, SeriesID: seriesID}, text), nil
 }
 
 func handleSonarrQueue(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	data, err := sonarrRequest("GET", "/queue", nil)
+	data, err := sonarrRequest(ctx, "GET", "/queue", nil)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -507,8 +2912,8 @@ func handleSonarrQueue(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 
 	records, _ := result["records"].([]interface{})
 
-	var lines []string
-	lines = append(lines, fmt.Sprintf("Download Queue (%d items):\n", len(records)))
+	out := newListOutput(req, "Title", "Status", "MB Left")
+	list := queueList{Items: []queueItem{}}
 
 	for _, r := range records {
 		item := r.(map[string]interface{})
@@ -516,29 +2921,152 @@ func handleSonarrQueue(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		status := item["status"].(string)
 		sizeleft := int64(0)
 		if sl, ok := item["sizeleft"].(float64); ok {
-			sizeleft = int64(sl) / 1024 / 1024
+			sizeleft = int64(sl)
+		}
+
+		out.add(fmt.Sprintf("  %s - %s (%dMB left)", title, status, sizeleft/1024/1024),
+			title, status, strconv.FormatInt(sizeleft/1024/1024, 10))
+		list.Items = append(list.Items, queueItem{Title: title, Status: status, SizeLeft: sizeleft})
+	}
+
+	return mcp.NewToolResultStructured(list, out.render(fmt.Sprintf("Download Queue (%d items):", len(records)), "  (empty)")), nil
+}
+
+func handleSonarrRefreshAndVerify(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	seriesID := int(args["series_id"].(float64))
+
+	// RefreshSeries also rescans the series folder once metadata is updated
+	status, err := runCommand(ctx, sonarrRequest, map[string]interface{}{
+		"name":     "RefreshSeries",
+		"seriesId": seriesID,
+	}, 2*time.Minute)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	data, err := sonarrRequest(ctx, "GET", fmt.Sprintf("/series/%d", seriesID), nil)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var series map[string]interface{}
+	json.Unmarshal(data, &series)
+
+	data, err = sonarrRequest(ctx, "GET", fmt.Sprintf("/episode?seriesId=%d", seriesID), nil)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var episodes []map[string]interface{}
+	json.Unmarshal(data, &episodes)
+
+	data, err = sonarrRequest(ctx, "GET", fmt.Sprintf("/episodefile?seriesId=%d", seriesID), nil)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var files []map[string]interface{}
+	json.Unmarshal(data, &files)
+
+	fileIDs := map[int]bool{}
+	for _, f := range files {
+		fileIDs[int(f["id"].(float64))] = true
+	}
+
+	issues := []string{}
+	mappedFiles := map[int]bool{}
+	now := time.Now()
+	for _, e := range episodes {
+		season := int(e["seasonNumber"].(float64))
+		number := int(e["episodeNumber"].(float64))
+		label := fmt.Sprintf("S%02dE%02d", season, number)
+		title, _ := e["title"].(string)
+		hasFile, _ := e["hasFile"].(bool)
+		monitored, _ := e["monitored"].(bool)
+		fileID := 0
+		if f, ok := e["episodeFileId"].(float64); ok {
+			fileID = int(f)
 		}
 
-		lines = append(lines, fmt.Sprintf("  %s - %s (%dMB left)", title, status, sizeleft))
+		if fileID > 0 {
+			mappedFiles[fileID] = true
+		}
+		if hasFile && !fileIDs[fileID] {
+			issues = append(issues, fmt.Sprintf("%s marked as downloaded but its file (ID %d) is missing on disk", label, fileID))
+		}
+		if title == "" || title == "TBA" {
+			issues = append(issues, fmt.Sprintf("%s has no episode title yet (TBA)", label))
+		}
+		if !hasFile && monitored && season > 0 {
+			if aired, ok := e["airDateUtc"].(string); ok {
+				if t, err := time.Parse(time.RFC3339, aired); err == nil && t.Before(now) {
+					issues = append(issues, fmt.Sprintf("%s has aired but has no file", label))
+				}
+			}
+		}
 	}
 
-	if len(records) == 0 {
-		lines = append(lines, "  (empty)")
+	for _, f := range files {
+		id := int(f["id"].(float64))
+		if !mappedFiles[id] {
+			path, _ := f["relativePath"].(string)
+			issues = append(issues, fmt.Sprintf("File %s (ID %d) is not mapped to any episode", path, id))
+		}
 	}
 
-	return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+	issues = append(issues, missingArtwork(series)...)
+
+	title, _ := series["title"].(string)
+	result := verifyResult{Title: title, Refresh: status, Episodes: len(episodes), Files: len(files), Issues: issues}
+	summary := fmt.Sprintf("%d episodes, %d files on disk", len(episodes), len(files))
+
+	return mcp.NewToolResultStructured(result, formatVerifyResult(result, summary)), nil
 }
 
 // ============================================================================
 // Radarr
 // ============================================================================
 
-func radarrRequest(method, endpoint string, body io.Reader) ([]byte, error) {
-	headers := map[string]string{
-		"X-Api-Key":    config.RadarrAPIKey,
-		"Content-Type": "application/json",
+func radarrRequest(ctx context.Context, method, endpoint string, body io.Reader) ([]byte, error) {
+	fetch := func(ctx context.Context) ([]byte, error) {
+		return doAPIKeyRequest(ctx, method, radarr.baseURL+"/api/v3"+endpoint, radarr.keys, body)
 	}
-	return doRequest(method, config.RadarrURL+"/api/v3"+endpoint, headers, body)
+	if data, ok := cachedLibrary("Radarr", radarr.baseURL, method, endpoint, fetch); ok {
+		return data, nil
+	}
+	data, err := fetch(ctx)
+	if err == nil {
+		updateLibraryCache("Radarr", radarr.baseURL, method, endpoint, data)
+	}
+	return data, err
+}
+
+type radarrService struct {
+	baseURL string
+	apiKey  string
+	keys    *apiKeyPair
+}
+
+var radarr = &radarrService{}
+
+func (*radarrService) Name() string  { return "Radarr" }
+func (r *radarrService) URL() string { return r.baseURL }
+
+func (r *radarrService) Configure() bool {
+	r.baseURL = getEnv("RADARR_URL", "http://localhost:7878")
+	r.apiKey = os.Getenv("RADARR_API_KEY")
+	r.keys = newAPIKeyPair("Radarr", r.apiKey, os.Getenv("RADARR_API_KEY_ALT"))
+	return r.apiKey != ""
+}
+
+func (*radarrService) RegisterTools(s *server.MCPServer) { registerRadarrTools(s) }
+
+func (*radarrService) arr() arrService {
+	return arrService{Name: "Radarr", Request: radarrRequest, Category: "movieCategory"}
+}
+
+func (r *radarrService) Status(ctx context.Context, st *serviceStatus) { arrStatus(ctx, r.arr(), st) }
+
+func (*radarrService) HealthCheck(ctx context.Context) (string, error) {
+	return versionField(radarrRequest(ctx, "GET", "/system/status", nil))
 }
 
 func registerRadarrTools(s *server.MCPServer) {
@@ -546,6 +3074,9 @@ func registerRadarrTools(s *server.MCPServer) {
 	s.AddTool(
 		mcp.NewTool("radarr_list_movies",
 			mcp.WithDescription("List all movies in Radarr"),
+			formatOption(),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[movieList](),
 		),
 		handleRadarrListMovies,
 	)
@@ -553,8 +3084,11 @@ func registerRadarrTools(s *server.MCPServer) {
 	// Get Movie
 	s.AddTool(
 		mcp.NewTool("radarr_get_movie",
-			mcp.WithDescription("Get details for a specific movie in Radarr"),
+			mcp.WithDescription("Get details for a specific movie in Radarr, with its poster and fanart so the right movie can be confirmed visually"),
 			mcp.WithNumber("movie_id", mcp.Required(), mcp.Description("Radarr movie ID")),
+			mcp.WithBoolean("include_images", mcp.Description("Attach the poster and fanart images (default true)")),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[movieDetails](),
 		),
 		handleRadarrGetMovie,
 	)
@@ -564,41 +3098,109 @@ func registerRadarrTools(s *server.MCPServer) {
 		mcp.NewTool("radarr_search_movie",
 			mcp.WithDescription("Trigger a search for releases for a movie in Radarr"),
 			mcp.WithNumber("movie_id", mcp.Required(), mcp.Description("Radarr movie ID")),
+			mcp.WithOutputSchema[searchCommand](),
 		),
-		handleRadarrSearchMovie,
+		withQuietHours(searchAutomatic, handleRadarrSearchMovie),
 	)
 
 	// Get Releases
 	s.AddTool(
-		mcp.NewTool("radarr_get_releases",
-			mcp.WithDescription("Get available releases for a movie (interactive search)"),
+		mcp.NewTool("radarr_get_releases", append([]mcp.ToolOption{
+			mcp.WithDescription("Get available releases for a movie (interactive search), with release age and warnings for releases that previously failed, were blocklisted, or would need heavy transcoding"),
 			mcp.WithNumber("movie_id", mcp.Required(), mcp.Description("Radarr movie ID")),
-		),
-		handleRadarrGetReleases,
+			mcp.WithNumber("max_wait_seconds", mcp.Description("Return after this many seconds even if indexers are still being searched; the search keeps running and calling again with the same arguments collects its results")),
+			mcp.WithOutputSchema[releaseList](),
+		}, playbackOptions()...)...),
+		withQuietHours(searchInteractive, handleRadarrGetReleases),
 	)
 
 	// Download Release
 	s.AddTool(
-		mcp.NewTool("radarr_download_release",
-			mcp.WithDescription("Download a specific release by GUID"),
+		mcp.NewTool("radarr_download_release", append([]mcp.ToolOption{
+			mcp.WithDescription("Download a specific release by GUID. Say why it was chosen with reason and comment; grabs are recorded for ultimarr_grab_log."),
 			mcp.WithString("guid", mcp.Required(), mcp.Description("Release GUID from radarr_get_releases")),
 			mcp.WithNumber("indexer_id", mcp.Required(), mcp.Description("Indexer ID from the release")),
 			mcp.WithNumber("movie_id", mcp.Required(), mcp.Description("Radarr movie ID")),
-		),
+			mcp.WithOutputSchema[releaseGrab](),
+		}, grabOptions()...)...),
 		handleRadarrDownloadRelease,
 	)
 
+	// Mark Failed
+	s.AddTool(
+		mcp.NewTool("radarr_mark_failed",
+			mcp.WithDescription("Mark a grabbed download as failed, the proper way to redo a bad grab: Radarr blocklists the release, removes the download, and searches for another. Identify the grab by history_id, download_id, or movie_id (its most recent grab)."),
+			mcp.WithNumber("history_id", mcp.Description("Radarr history record ID of the grab")),
+			mcp.WithString("download_id", mcp.Description("Download client ID of the grab (the torrent hash or SABnzbd nzo_id)")),
+			mcp.WithNumber("movie_id", mcp.Description("Radarr movie ID; its most recent grab is marked failed")),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithOutputSchema[markFailedResult](),
+		),
+		withQuietHours(searchAutomatic, markFailedHandler("Radarr", radarrRequest, "movie_id", "/history/movie?movieId=%d")),
+	)
+
 	// Queue
 	s.AddTool(
 		mcp.NewTool("radarr_queue",
 			mcp.WithDescription("Get current download queue in Radarr"),
+			formatOption(),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[queueList](),
 		),
 		handleRadarrQueue,
 	)
+
+	// Refresh and Verify
+	s.AddTool(
+		mcp.NewTool("radarr_refresh_and_verify",
+			mcp.WithDescription("Refresh movie metadata, rescan files, and report mismatches between the expected and on-disk movie file (use when a movie shows as missing, has the wrong file, or is missing artwork)"),
+			mcp.WithNumber("movie_id", mcp.Required(), mcp.Description("Radarr movie ID")),
+			mcp.WithOutputSchema[verifyResult](),
+		),
+		handleRadarrRefreshAndVerify,
+	)
+
+	// Add Existing
+	s.AddTool(
+		mcp.NewTool("radarr_add_existing",
+			mcp.WithDescription("Add a movie whose files are already on disk (e.g. after copying an old library to a new server): adds it at that folder without searching, then rescans and imports the files. The movie is identified by its TMDB ID, an ID tag in the folder name like {tmdb-123}, or the folder name."),
+			mcp.WithString("folder", mcp.Required(), mcp.Description("Full path of the movie folder as Radarr sees it")),
+			mcp.WithNumber("tmdb_id", mcp.Description("TMDB ID (default: from the folder name)")),
+			mcp.WithNumber("profile_id", mcp.Description("Quality profile ID (default: from ULTIMARR_DEFAULTS)")),
+			mcp.WithString("monitor", mcp.Enum("movieOnly", "none"), mcp.Description("Whether to monitor the movie (default movieOnly)")),
+			mcp.WithOutputSchema[existingResult](),
+		),
+		radarrExisting.handler(),
+	)
+
+	// Bulk Delete
+	s.AddTool(
+		mcp.NewTool("radarr_bulk_delete", append([]mcp.ToolOption{
+			mcp.WithDescription("Delete movies matching filter criteria. Without confirm_token this is a dry run that lists matches, reclaimable space, and a confirmation token; pass that token to delete exactly those movies."),
+		}, bulkDeleteOptions("Radarr")...)...),
+		handleRadarrBulkDelete,
+	)
+
+	if config.AdminTools {
+		registerSystemTools(s, "radarr", "Radarr", radarrRequest)
+	}
+}
+
+// movieSummary is one entry of radarr_list_movies.
+type movieSummary struct {
+	ID         int    `json:"id"`
+	Title      string `json:"title"`
+	Year       int    `json:"year"`
+	Downloaded bool   `json:"downloaded"`
+	Monitored  bool   `json:"monitored"`
+}
+
+type movieList struct {
+	Movies []movieSummary `json:"movies"`
 }
 
 func handleRadarrListMovies(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	data, err := radarrRequest("GET", "/movie", nil)
+	data, err := radarrRequest(ctx, "GET", "/movie", nil)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -606,8 +3208,8 @@ func handleRadarrListMovies(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 	var movies []map[string]interface{}
 	json.Unmarshal(data, &movies)
 
-	var lines []string
-	lines = append(lines, fmt.Sprintf("Movies in Radarr (%d):\n", len(movies)))
+	out := newListOutput(req, "ID", "Title", "Year", "Downloaded", "Monitored")
+	list := movieList{Movies: []movieSummary{}}
 
 	for _, m := range movies {
 		id := int(m["id"].(float64))
@@ -627,17 +3229,32 @@ func handleRadarrListMovies(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 			status += " [unmonitored]"
 		}
 
-		lines = append(lines, fmt.Sprintf("  [%d] %s (%d) - %s", id, title, year, status))
+		out.add(fmt.Sprintf("  [%d] %s (%d) - %s", id, title, year, status),
+			strconv.Itoa(id), title, strconv.Itoa(year), strconv.FormatBool(hasFile), strconv.FormatBool(monitored))
+		list.Movies = append(list.Movies, movieSummary{ID: id, Title: title, Year: year, Downloaded: hasFile, Monitored: monitored})
 	}
 
-	return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+	return mcp.NewToolResultStructured(list, out.render(fmt.Sprintf("Movies in Radarr (%d):", len(movies)), "")), nil
+}
+
+// movieDetails is the structured result of radarr_get_movie.
+type movieDetails struct {
+	ID         int    `json:"id"`
+	Title      string `json:"title"`
+	Year       int    `json:"year"`
+	Downloaded bool   `json:"downloaded"`
+	Monitored  bool   `json:"monitored"`
+	Path       string `json:"path"`
+	Rating     string `json:"rating,omitempty"`
+	PosterURL  string `json:"posterUrl,omitempty"`
+	FanartURL  string `json:"fanartUrl,omitempty"`
 }
 
 func handleRadarrGetMovie(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args := req.GetArguments()
 	movieID := int(args["movie_id"].(float64))
 
-	data, err := radarrRequest("GET", fmt.Sprintf("/movie/%d", movieID), nil)
+	data, err := radarrRequest(ctx, "GET", fmt.Sprintf("/movie/%d", movieID), nil)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -664,8 +3281,25 @@ ID: %d
 Status: %s
 Monitored: %v
 Path: %s`, title, year, movieID, status, monitored, path)
+	rating, _ := m["certification"].(string)
+	if rating != "" {
+		info += "\nRated: " + rating
+		if aboveMaxRating(rating) {
+			info += fmt.Sprintf(" (above the %s limit)", config.MaxRating)
+		}
+	}
+
+	details := movieDetails{ID: movieID, Title: title, Year: year, Downloaded: hasFile, Monitored: monitored, Path: path, Rating: rating}
+	fetchImages := true
+	if v, ok := args["include_images"].(bool); ok {
+		fetchImages = v
+	}
+	art := itemArtwork(ctx, m, radarr.baseURL, radarr.keys, fetchImages)
+	details.PosterURL, details.FanartURL = art.PosterURL, art.FanartURL
 
-	return mcp.NewToolResultText(info), nil
+	result := mcp.NewToolResultStructured(details, info)
+	result.Content = append(result.Content, art.Images...)
+	return result, nil
 }
 
 func handleRadarrSearchMovie(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -678,7 +3312,7 @@ func handleRadarrSearchMovie(ctx context.Context, req mcp.CallToolRequest) (*mcp
 	}
 	body, _ := json.Marshal(payload)
 
-	data, err := radarrRequest("POST", "/command", strings.NewReader(string(body)))
+	data, err := radarrRequest(ctx, "POST", "/command", strings.NewReader(string(body)))
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -686,44 +3320,48 @@ func handleRadarrSearchMovie(ctx context.Context, req mcp.CallToolRequest) (*mcp
 	var result map[string]interface{}
 	json.Unmarshal(data, &result)
 
-	return mcp.NewToolResultText(fmt.Sprintf("Search triggered. Command ID: %v", result["id"])), nil
+	id, _ := result["id"].(float64)
+	return mcp.NewToolResultStructured(searchCommand{CommandIDs: []int{int(id)}}, fmt.Sprintf("Search triggered. Command ID: %d", int(id))), nil
 }
 
 func handleRadarrGetReleases(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args := req.GetArguments()
 	movieID := int(args["movie_id"].(float64))
 
-	data, err := radarrRequest("GET", fmt.Sprintf("/release?movieId=%d", movieID), nil)
+	endpoint := fmt.Sprintf("/release?movieId=%d", movieID)
+	data, done, err := searchReleases(ctx, "Radarr"+endpoint, maxWait(args), func(ctx context.Context) ([]byte, error) {
+		return doAPIKeyRequestTimeout(ctx, "GET", radarr.baseURL+"/api/v3"+endpoint, radarr.keys, nil, releaseSearchTimeout)
+	})
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	if !done && data == nil {
+		return releaseSearchPending(maxWait(args)), nil
+	}
 
 	var releases []map[string]interface{}
 	json.Unmarshal(data, &releases)
 
-	var lines []string
-	lines = append(lines, fmt.Sprintf("Available releases (%d):\n", len(releases)))
-
-	for i, r := range releases {
-		if i >= 20 {
-			lines = append(lines, fmt.Sprintf("\n  ... and %d more", len(releases)-20))
-			break
-		}
-		title := r["title"].(string)
-		size := int64(r["size"].(float64))
-		sizeMB := size / 1024 / 1024
-		seeders := 0
-		if s, ok := r["seeders"].(float64); ok {
-			seeders = int(s)
+	bad := knownBadReleases(ctx, "Radarr", radarrRequest, fmt.Sprintf("/history/movie?movieId=%d", movieID), "movieId", movieID)
+	list := parseReleases(releases, bad)
+
+	if len(config.ClientProfiles) > 0 {
+		user, _ := args["user"].(string)
+		if data, err := radarrRequest(ctx, "GET", fmt.Sprintf("/movie/%d", movieID), nil); err == nil {
+			var movie map[string]interface{}
+			json.Unmarshal(data, &movie)
+			runtime, _ := movie["runtime"].(float64)
+			checkPlayback(ctx, &list, releases, user, func(map[string]interface{}) int {
+				return int(runtime)
+			})
 		}
-		guid := r["guid"].(string)
-		indexerID := int(r["indexerId"].(float64))
-		indexer := r["indexer"].(string)
-
-		lines = append(lines, fmt.Sprintf("  [%d seeders] %s (%dMB) - %s\n    GUID: %s | Indexer: %d", seeders, title[:min(60, len(title))], sizeMB, indexer, guid, indexerID))
 	}
 
-	return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+	lines := formatReleases(list)
+	if !done {
+		lines = markReleasesPending(&list, lines, maxWait(args))
+	}
+	return mcp.NewToolResultStructured(list, strings.Join(lines, "\n")), nil
 }
 
 func handleRadarrDownloadRelease(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -739,16 +3377,21 @@ func handleRadarrDownloadRelease(ctx context.Context, req mcp.CallToolRequest) (
 	}
 	body, _ := json.Marshal(payload)
 
-	_, err := radarrRequest("POST", "/release", strings.NewReader(string(body)))
+	data, err := radarrRequest(ctx, "POST", "/release", strings.NewReader(string(body)))
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	return mcp.NewToolResultText("Download started successfully"), nil
+	text := "Download started successfully"
+	rec := grabRecord{Service: "Radarr", MovieID: movieID, GUID: guid, IndexerID: indexerID, Release: releaseTitle(data)}
+	if note := recordGrab(ctx, args, rec, radarrRequest, fmt.Sprintf("/movie/%d", movieID)); note != "" {
+		text += ". " + note
+	}
+	return mcp.NewToolResultStructured(releaseGrab{GUID: guid, IndexerID: indexerID, MovieID: movieID}, text), nil
 }
 
 func handleRadarrQueue(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	data, err := radarrRequest("GET", "/queue", nil)
+	data, err := radarrRequest(ctx, "GET", "/queue", nil)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -758,8 +3401,8 @@ func handleRadarrQueue(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 
 	records, _ := result["records"].([]interface{})
 
-	var lines []string
-	lines = append(lines, fmt.Sprintf("Download Queue (%d items):\n", len(records)))
+	out := newListOutput(req, "Title", "Status", "MB Left")
+	list := queueList{Items: []queueItem{}}
 
 	for _, r := range records {
 		item := r.(map[string]interface{})
@@ -767,15 +3410,67 @@ func handleRadarrQueue(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		status := item["status"].(string)
 		sizeleft := int64(0)
 		if sl, ok := item["sizeleft"].(float64); ok {
-			sizeleft = int64(sl) / 1024 / 1024
+			sizeleft = int64(sl)
 		}
 
-		lines = append(lines, fmt.Sprintf("  %s - %s (%dMB left)", title, status, sizeleft))
+		out.add(fmt.Sprintf("  %s - %s (%dMB left)", title, status, sizeleft/1024/1024),
+			title, status, strconv.FormatInt(sizeleft/1024/1024, 10))
+		list.Items = append(list.Items, queueItem{Title: title, Status: status, SizeLeft: sizeleft})
+	}
+
+	return mcp.NewToolResultStructured(list, out.render(fmt.Sprintf("Download Queue (%d items):", len(records)), "  (empty)")), nil
+}
+
+func handleRadarrRefreshAndVerify(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	movieID := int(args["movie_id"].(float64))
+
+	// RefreshMovie also rescans the movie folder once metadata is updated
+	status, err := runCommand(ctx, radarrRequest, map[string]interface{}{
+		"name":     "RefreshMovie",
+		"movieIds": []int{movieID},
+	}, 2*time.Minute)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	data, err := radarrRequest(ctx, "GET", fmt.Sprintf("/movie/%d", movieID), nil)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var m map[string]interface{}
+	json.Unmarshal(data, &m)
+
+	data, err = radarrRequest(ctx, "GET", fmt.Sprintf("/moviefile?movieId=%d", movieID), nil)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
+	var files []map[string]interface{}
+	json.Unmarshal(data, &files)
 
-	if len(records) == 0 {
-		lines = append(lines, "  (empty)")
+	title, _ := m["title"].(string)
+	hasFile, _ := m["hasFile"].(bool)
+	moviePath, _ := m["path"].(string)
+
+	issues := []string{}
+	if hasFile && len(files) == 0 {
+		issues = append(issues, "Marked as downloaded but no movie file exists on disk")
+	}
+	if !hasFile && len(files) > 0 {
+		issues = append(issues, fmt.Sprintf("%d file(s) on disk but the movie is not marked as downloaded", len(files)))
 	}
+	if len(files) > 1 {
+		issues = append(issues, fmt.Sprintf("%d files mapped to one movie", len(files)))
+	}
+	for _, f := range files {
+		path, _ := f["path"].(string)
+		if moviePath != "" && path != "" && !strings.HasPrefix(path, moviePath) {
+			issues = append(issues, fmt.Sprintf("File %s is outside the movie folder %s", path, moviePath))
+		}
+	}
+	issues = append(issues, missingArtwork(m)...)
+
+	result := verifyResult{Title: title, Refresh: status, Files: len(files), Issues: issues}
 
-	return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+	return mcp.NewToolResultStructured(result, formatVerifyResult(result, fmt.Sprintf("%d file(s) on disk", len(files)))), nil
 }
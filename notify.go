@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ============================================================================
+// Completion Notifications
+// ============================================================================
+
+// With ULTIMARR_NOTIFY, imports finished by Sonarr and Radarr are pushed to
+// connected clients as MCP log messages, so the assistant can say a download
+// is ready without being asked. Only titles someone asked for through
+// ultimarr are announced: those requested with jellyseerr_request and those
+// in the grab log.
+
+// Requested titles are forgotten after this long, so a show requested once
+// doesn't announce every episode for years
+const maxRequestedAge = 90 * 24 * time.Hour
+
+var (
+	importsMu    sync.Mutex
+	importsSince = map[string]time.Time{} // per service, the newest import seen
+
+	requestedMu     sync.Mutex
+	requestedTitles = map[string]time.Time{} // "movie:603" -> when it was requested
+)
+
+func requestedPath() string {
+	return filepath.Join(config.DataDir, "requested.json")
+}
+
+// trackRequest records a title requested through ultimarr so its imports are
+// announced. It returns an error if that couldn't be saved.
+func trackRequest(mediaType string, tmdbID int) error {
+	if !config.Notify {
+		return nil
+	}
+	requestedMu.Lock()
+	defer requestedMu.Unlock()
+	requestedTitles[fmt.Sprintf("%s:%d", mediaType, tmdbID)] = time.Now()
+	for k, t := range requestedTitles {
+		if time.Since(t) > maxRequestedAge {
+			delete(requestedTitles, k)
+		}
+	}
+	if err := os.MkdirAll(config.DataDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(requestedTitles, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := requestedPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, requestedPath())
+}
+
+// importTracked reports whether an import is of a title requested or grabbed
+// through ultimarr.
+func importTracked(service string, h map[string]interface{}, e *Event) bool {
+	requestedMu.Lock()
+	t, ok := requestedTitles[fmt.Sprintf("%s:%d", e.MediaType, e.TmdbID)]
+	requestedMu.Unlock()
+	if ok && time.Since(t) <= maxRequestedAge {
+		return true
+	}
+
+	seriesID, _ := h["seriesId"].(float64)
+	movieID, _ := h["movieId"].(float64)
+	grabMu.Lock()
+	defer grabMu.Unlock()
+	loadGrabsLocked()
+	for _, g := range grabLog {
+		if g.Service == service && ((g.SeriesID != 0 && g.SeriesID == int(seriesID)) || (g.MovieID != 0 && g.MovieID == int(movieID))) {
+			return true
+		}
+	}
+	return false
+}
+
+// startCompletionNotifications registers the poller that watches for imports
+// and forwards them to every client session on s.
+func startCompletionNotifications(s *server.MCPServer) {
+	if config.PollInterval <= 0 {
+		log.Printf("ULTIMARR_NOTIFY is set but ULTIMARR_POLL_INTERVAL is 0, so imports won't be announced")
+		return
+	}
+	if data, err := os.ReadFile(requestedPath()); err == nil {
+		requestedMu.Lock()
+		json.Unmarshal(data, &requestedTitles)
+		requestedMu.Unlock()
+	}
+
+	now := time.Now()
+	checkpoints := loadImportCheckpoints()
+	for _, svc := range arrServices() {
+		importsSince[svc.Name] = now
+		if t, ok := checkpoints[svc.Name]; ok {
+			importsSince[svc.Name] = t
+		}
+	}
+	addPoller(pollImports)
+	subscribe(func(e Event) {
+		if e.Type != EventImported {
+			return
+		}
+		message := e.Title + " has been imported and is ready to watch"
+		log.Printf("Notify: %s", message)
+		s.SendNotificationToAllClients("notifications/message", map[string]any{
+			"level":  mcp.LoggingLevelNotice,
+			"logger": "ultimarr",
+			"data": map[string]any{
+				"event":     e.Type,
+				"message":   message,
+				"title":     e.Title,
+				"mediaType": e.MediaType,
+				"tmdbId":    e.TmdbID,
+			},
+		})
+	})
+}
+
+// pollImports publishes an event for each download of a tracked title
+// imported since the last poll. Episodes imported from the same download (a
+// season pack) are reported together.
+func pollImports(ctx context.Context) {
+	for _, svc := range arrServices() {
+		importsMu.Lock()
+		since := importsSince[svc.Name]
+		importsMu.Unlock()
+
+		include := "includeSeries=true&includeEpisode=true"
+		if svc.Name == "Radarr" {
+			include = "includeMovie=true"
+		}
+		params := url.Values{"date": {since.UTC().Format(time.RFC3339)}, "eventType": {"3"}}
+		data, err := svc.Request(ctx, "GET", "/history/since?"+params.Encode()+"&"+include, nil)
+		if err != nil {
+			continue
+		}
+		var history []map[string]interface{}
+		json.Unmarshal(data, &history)
+
+		var order []string
+		downloads := map[string]*Event{}
+		episodes := map[string][]string{}
+		newest := since
+		for _, h := range history {
+			date, _ := h["date"].(string)
+			imported, err := time.Parse(time.RFC3339, date)
+			if err != nil || !imported.After(since) {
+				continue
+			}
+			if imported.After(newest) {
+				newest = imported
+			}
+
+			key, _ := h["downloadId"].(string)
+			if key == "" {
+				key = fmt.Sprint(h["id"])
+			}
+			e, ok := downloads[key]
+			if !ok {
+				e = &Event{Type: EventImported, Time: imported}
+				if movie, ok := h["movie"].(map[string]interface{}); ok {
+					e.MediaType = "movie"
+					e.Title, _ = movie["title"].(string)
+					id, _ := movie["tmdbId"].(float64)
+					e.TmdbID = int(id)
+				} else if series, ok := h["series"].(map[string]interface{}); ok {
+					e.MediaType = "tv"
+					e.Title, _ = series["title"].(string)
+					id, _ := series["tmdbId"].(float64)
+					e.TmdbID = int(id)
+				}
+				if e.Title == "" {
+					e.Title, _ = h["sourceTitle"].(string)
+				}
+				downloads[key] = e
+				if importTracked(svc.Name, h, e) {
+					order = append(order, key)
+				}
+			}
+			if ep, ok := h["episode"].(map[string]interface{}); ok {
+				season, _ := ep["seasonNumber"].(float64)
+				number, _ := ep["episodeNumber"].(float64)
+				episodes[key] = append(episodes[key], fmt.Sprintf("S%02dE%02d", int(season), int(number)))
+			}
+		}
+
+		importsMu.Lock()
+		importsSince[svc.Name] = newest
+		saveImportCheckpoints()
+		importsMu.Unlock()
+
+		for _, key := range order {
+			e := downloads[key]
+			switch eps := episodes[key]; {
+			case len(eps) == 0:
+			case len(eps) <= 4:
+				sort.Strings(eps)
+				e.Title += " " + strings.Join(eps, ", ")
+			default:
+				e.Title += fmt.Sprintf(" (%d episodes)", len(eps))
+			}
+			publish(*e)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// Output Formatting
+// ============================================================================
+
+// formatOption adds the "format" parameter shared by list tools.
+func formatOption() mcp.ToolOption {
+	return mcp.WithString("format",
+		mcp.Enum("text", "markdown", "csv"),
+		mcp.Description("Output style: 'text' (default) for indented lines, 'markdown' for a table, or 'csv' for machine parsing"))
+}
+
+// listOutput collects rows for a list tool and renders them in the format
+// the caller asked for. Each row keeps both its text-mode line and its cells.
+type listOutput struct {
+	format  string
+	headers []string
+	lines   []string
+	rows    [][]string
+}
+
+func newListOutput(req mcp.CallToolRequest, headers ...string) *listOutput {
+	format, _ := req.GetArguments()["format"].(string)
+	return &listOutput{format: format, headers: headers}
+}
+
+// add appends a row, given its text-mode line and its table cells.
+func (o *listOutput) add(line string, cells ...string) {
+	o.lines = append(o.lines, line)
+	o.rows = append(o.rows, cells)
+}
+
+// render returns the list with a title line (omitted for CSV). empty is shown
+// in text and Markdown output when there are no rows.
+func (o *listOutput) render(title, empty string) string {
+	switch o.format {
+	case "csv":
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Write(o.headers)
+		w.WriteAll(o.rows)
+		return buf.String()
+
+	case "markdown":
+		if len(o.rows) == 0 {
+			return title + "\n\n" + strings.TrimSpace(empty)
+		}
+		var b strings.Builder
+		b.WriteString(title + "\n\n")
+		b.WriteString("| " + strings.Join(o.headers, " | ") + " |\n")
+		b.WriteString("|" + strings.Repeat("---|", len(o.headers)) + "\n")
+		for _, row := range o.rows {
+			cells := make([]string, len(row))
+			for i, c := range row {
+				cells[i] = strings.ReplaceAll(strings.ReplaceAll(c, "|", "\\|"), "\n", " ")
+			}
+			b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+		return strings.TrimRight(b.String(), "\n")
+
+	default:
+		lines := o.lines
+		if len(lines) == 0 && empty != "" {
+			lines = []string{empty}
+		}
+		return fmt.Sprintf("%s\n\n%s", title, strings.Join(lines, "\n"))
+	}
+}
+
+// truncate shortens s to at most n characters, ending with "..." if cut.
+func truncate(s string, n int) string {
+	r := []rune(strings.TrimSpace(s))
+	if len(r) <= n {
+		return string(r)
+	}
+	if n <= 3 {
+		return string(r[:n])
+	}
+	return strings.TrimSpace(string(r[:n-3])) + "..."
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ============================================================================
+// Remote Path Mappings
+// ============================================================================
+
+// When the download client runs in a different container than Sonarr/Radarr,
+// it reports completed downloads at a path the *arr can't see. A remote path
+// mapping translates the client's path prefix to the *arr's.
+
+func registerPathMappingTools(s *server.MCPServer) {
+	services := []string{}
+	for _, svc := range arrServices() {
+		services = append(services, strings.ToLower(svc.Name))
+	}
+	if len(services) == 0 {
+		return
+	}
+
+	s.AddTool(
+		mcp.NewTool("ultimarr_path_mappings",
+			mcp.WithDescription("List Sonarr/Radarr remote path mappings, the hosts of their download clients, and completed downloads that can't be imported because of a path mismatch, with the path the service looked in. Use after ultimarr_verify_download_clients finds path mismatches."),
+			mcp.WithString("service", mcp.Enum(services...), mcp.Description("Only this service (default all)")),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[pathMappingList](),
+		),
+		handleUltimarrPathMappings,
+	)
+
+	s.AddTool(
+		mcp.NewTool("ultimarr_add_path_mapping",
+			mcp.WithDescription("Add a remote path mapping so Sonarr/Radarr can find completed downloads: paths the download client on host reports under remote_path are looked for under local_path. Blocked imports are retried automatically afterwards."),
+			mcp.WithString("service", mcp.Required(), mcp.Enum(services...), mcp.Description("Service to add the mapping to")),
+			mcp.WithString("host", mcp.Required(), mcp.Description("Host of the download client, exactly as set in the download client's settings")),
+			mcp.WithString("remote_path", mcp.Required(), mcp.Description("Folder as the download client sees it, e.g. /downloads/")),
+			mcp.WithString("local_path", mcp.Required(), mcp.Description("The same folder as the service sees it, e.g. /data/downloads/")),
+			mcp.WithOutputSchema[pathMappingResult](),
+		),
+		handleUltimarrAddPathMapping,
+	)
+
+	s.AddTool(
+		mcp.NewTool("ultimarr_remove_path_mapping",
+			mcp.WithDescription("Remove a Sonarr/Radarr remote path mapping by ID (from ultimarr_path_mappings)."),
+			mcp.WithString("service", mcp.Required(), mcp.Enum(services...), mcp.Description("Service the mapping belongs to")),
+			mcp.WithNumber("id", mcp.Required(), mcp.Description("Mapping ID")),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithOutputSchema[pathMappingResult](),
+		),
+		handleUltimarrRemovePathMapping,
+	)
+}
+
+// pathMappingList is the structured result of ultimarr_path_mappings.
+type pathMappingList struct {
+	Services []servicePathMappings `json:"services"`
+}
+
+type servicePathMappings struct {
+	Service    string             `json:"service"`
+	Mappings   []pathMapping      `json:"mappings"`
+	Hosts      []string           `json:"hosts"` // download client hosts a mapping can apply to
+	Mismatches []unmappedDownload `json:"mismatches"`
+	Error      string             `json:"error,omitempty"`
+}
+
+type pathMapping struct {
+	ID         int    `json:"id"`
+	Host       string `json:"host"`
+	RemotePath string `json:"remotePath"`
+	LocalPath  string `json:"localPath"`
+}
+
+// unmappedDownload is a completed download stuck on a path mismatch. Path is
+// where the service looked for it: the client's own path, or a translated one
+// if Mapping (the ID of the mapping that applied) is set, in which case that
+// mapping's local path is likely wrong.
+type unmappedDownload struct {
+	Title   string `json:"title"`
+	Path    string `json:"path"`
+	Client  string `json:"client"`
+	Host    string `json:"host,omitempty"`
+	Mapping int    `json:"mapping,omitempty"`
+	Message string `json:"message"`
+}
+
+// pathMappingResult is the structured result of adding or removing a mapping.
+type pathMappingResult struct {
+	Service string      `json:"service"`
+	Action  string      `json:"action"`
+	Mapping pathMapping `json:"mapping"`
+}
+
+func handleUltimarrPathMappings(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	only, _ := args["service"].(string)
+
+	result := pathMappingList{Services: []servicePathMappings{}}
+	var lines []string
+	for _, svc := range arrServices() {
+		if only != "" && !strings.EqualFold(only, svc.Name) {
+			continue
+		}
+		sm := servicePathMappings{Service: svc.Name, Mappings: []pathMapping{}, Hosts: []string{}, Mismatches: []unmappedDownload{}}
+		lines = append(lines, svc.Name+":")
+
+		mappings, err := listPathMappings(ctx, svc)
+		if err != nil {
+			sm.Error = err.Error()
+			result.Services = append(result.Services, sm)
+			lines = append(lines, "  ERROR: "+err.Error(), "")
+			continue
+		}
+		sm.Mappings = append(sm.Mappings, mappings...)
+		if len(mappings) == 0 {
+			lines = append(lines, "  No remote path mappings")
+		}
+		for _, m := range mappings {
+			lines = append(lines, fmt.Sprintf("  [%d] %s: %s -> %s", m.ID, m.Host, m.RemotePath, m.LocalPath))
+		}
+
+		clients := downloadClientHosts(ctx, svc)
+		for _, host := range clients {
+			if !containsFold(sm.Hosts, host) {
+				sm.Hosts = append(sm.Hosts, host)
+			}
+		}
+		sort.Strings(sm.Hosts)
+		if len(sm.Hosts) > 0 {
+			lines = append(lines, "  Download client hosts: "+strings.Join(sm.Hosts, ", "))
+		}
+
+		if data, err := svc.Request(ctx, "GET", "/queue?pageSize=200", nil); err == nil {
+			var queue struct {
+				Records []map[string]interface{} `json:"records"`
+			}
+			json.Unmarshal(data, &queue)
+			for _, item := range queue.Records {
+				state, _ := item["trackedDownloadState"].(string)
+				if state != "importPending" && state != "importBlocked" && state != "importFailed" {
+					continue
+				}
+				messages := queueStatusMessages(item)
+				if importProblemKind(messages) != "path mismatch" {
+					continue
+				}
+
+				d := unmappedDownload{Message: strings.Join(messages, "; ")}
+				d.Title, _ = item["title"].(string)
+				d.Path, _ = item["outputPath"].(string)
+				d.Client, _ = item["downloadClient"].(string)
+				d.Host = clients[d.Client]
+				for _, m := range mappings {
+					if strings.EqualFold(m.Host, d.Host) && strings.HasPrefix(d.Path, m.LocalPath) {
+						d.Mapping = m.ID
+					}
+				}
+				sm.Mismatches = append(sm.Mismatches, d)
+
+				lines = append(lines, fmt.Sprintf("  Can't import %s from %s: looked in %s", d.Title, d.Client, d.Path))
+				if d.Mapping > 0 {
+					lines = append(lines, fmt.Sprintf("    Mapping %d translated this path, so its local path is probably wrong", d.Mapping))
+				} else {
+					lines = append(lines, fmt.Sprintf("    This is the path %s reports; no mapping for host %s applies", d.Client, d.Host))
+				}
+			}
+		}
+
+		result.Services = append(result.Services, sm)
+		lines = append(lines, "")
+	}
+	if len(result.Services) == 0 {
+		return mcp.NewToolResultError("Neither Sonarr nor Radarr is configured"), nil
+	}
+
+	lines = append(lines, "To fix a mismatch, map the folder the client reports to where the service sees the same folder with ultimarr_add_path_mapping.")
+	return mcp.NewToolResultStructured(result, strings.Join(lines, "\n")), nil
+}
+
+func handleUltimarrAddPathMapping(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	svc, ok := pathMappingService(args)
+	if !ok {
+		return mcp.NewToolResultError("Unknown or unconfigured service"), nil
+	}
+	host, _ := args["host"].(string)
+	remotePath, _ := args["remote_path"].(string)
+	localPath, _ := args["local_path"].(string)
+	if host == "" || remotePath == "" || localPath == "" {
+		return mcp.NewToolResultError("host, remote_path, and local_path are required"), nil
+	}
+
+	// A mapping only applies to a download client with exactly this host
+	var hosts []string
+	for _, h := range downloadClientHosts(ctx, svc) {
+		hosts = append(hosts, h)
+	}
+	if len(hosts) > 0 && !containsFold(hosts, host) {
+		sort.Strings(hosts)
+		return mcp.NewToolResultError(fmt.Sprintf("No %s download client uses host %q; use one of: %s", svc.Name, host, strings.Join(hosts, ", "))), nil
+	}
+
+	body, _ := json.Marshal(map[string]string{"host": host, "remotePath": remotePath, "localPath": localPath})
+	data, err := svc.Request(ctx, "POST", "/remotepathmapping", strings.NewReader(string(body)))
+	if err != nil {
+		return mcp.NewToolResultError(describeValidationError(err)), nil
+	}
+	var added map[string]interface{}
+	json.Unmarshal(data, &added)
+
+	m := pathMappingFromJSON(added)
+	if m.Host == "" {
+		m = pathMapping{ID: m.ID, Host: host, RemotePath: remotePath, LocalPath: localPath}
+	}
+	result := pathMappingResult{Service: svc.Name, Action: "added", Mapping: m}
+	return mcp.NewToolResultStructured(result, fmt.Sprintf("Added %s mapping %d: %s on %s -> %s. Blocked imports are retried within a few minutes; run ultimarr_verify_download_clients to confirm.", svc.Name, m.ID, m.RemotePath, m.Host, m.LocalPath)), nil
+}
+
+func handleUltimarrRemovePathMapping(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	svc, ok := pathMappingService(args)
+	if !ok {
+		return mcp.NewToolResultError("Unknown or unconfigured service"), nil
+	}
+	id, _ := args["id"].(float64)
+
+	mappings, err := listPathMappings(ctx, svc)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result := pathMappingResult{Service: svc.Name, Action: "removed"}
+	for _, m := range mappings {
+		if m.ID == int(id) {
+			result.Mapping = m
+		}
+	}
+	if result.Mapping.ID == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("%s has no remote path mapping %d", svc.Name, int(id))), nil
+	}
+
+	if _, err := svc.Request(ctx, "DELETE", fmt.Sprintf("/remotepathmapping/%d", int(id)), nil); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	m := result.Mapping
+	return mcp.NewToolResultStructured(result, fmt.Sprintf("Removed %s mapping %d: %s on %s -> %s", svc.Name, m.ID, m.RemotePath, m.Host, m.LocalPath)), nil
+}
+
+// pathMappingService returns the configured service named by the "service"
+// argument.
+func pathMappingService(args map[string]interface{}) (arrService, bool) {
+	name, _ := args["service"].(string)
+	for _, svc := range arrServices() {
+		if strings.EqualFold(name, svc.Name) {
+			return svc, true
+		}
+	}
+	return arrService{}, false
+}
+
+func listPathMappings(ctx context.Context, svc arrService) ([]pathMapping, error) {
+	data, err := svc.Request(ctx, "GET", "/remotepathmapping", nil)
+	if err != nil {
+		return nil, err
+	}
+	var raw []map[string]interface{}
+	json.Unmarshal(data, &raw)
+	mappings := []pathMapping{}
+	for _, r := range raw {
+		mappings = append(mappings, pathMappingFromJSON(r))
+	}
+	return mappings, nil
+}
+
+func pathMappingFromJSON(r map[string]interface{}) pathMapping {
+	id, _ := r["id"].(float64)
+	m := pathMapping{ID: int(id)}
+	m.Host, _ = r["host"].(string)
+	m.RemotePath, _ = r["remotePath"].(string)
+	m.LocalPath, _ = r["localPath"].(string)
+	return m
+}
+
+// downloadClientHosts maps each of a service's download clients to its host.
+func downloadClientHosts(ctx context.Context, svc arrService) map[string]string {
+	hosts := map[string]string{}
+	data, err := svc.Request(ctx, "GET", "/downloadclient", nil)
+	if err != nil {
+		return hosts
+	}
+	var clients []map[string]interface{}
+	json.Unmarshal(data, &clients)
+	for _, c := range clients {
+		name, _ := c["name"].(string)
+		if v, ok := providerField(c, "host"); ok {
+			if host, _ := v.(string); host != "" {
+				hosts[name] = host
+			}
+		}
+	}
+	return hosts
+}
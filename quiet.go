@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ============================================================================
+// Quiet Hours
+// ============================================================================
+
+// quietWindow is a daily local-time window, which may wrap past midnight.
+type quietWindow struct {
+	start, end time.Duration // offsets from midnight
+}
+
+// Kinds of search-triggering tools affected by quiet hours
+const (
+	searchAutomatic   = iota // triggers an *arr search command; can be deferred
+	searchInteractive        // returns releases to the caller; can only be refused
+)
+
+// When the queue of deferred searches runs at 0 searches per hour
+const quietReleaseInterval = time.Minute
+
+// deferredSearch is an automatic search waiting for quiet hours to end.
+type deferredSearch struct {
+	key     string // tool name and arguments, to spot repeats
+	req     mcp.CallToolRequest
+	handler server.ToolHandlerFunc
+	runAt   time.Time
+}
+
+var (
+	quietMu       sync.Mutex
+	quietSearches []time.Time
+
+	// Deferred searches in the order they run, and the same by key
+	quietQueue     []*deferredSearch
+	quietQueued    = map[string]*deferredSearch{}
+	quietQueueWake = make(chan struct{}, 1)
+	quietQueueOnce sync.Once
+)
+
+// parseQuietHours parses a window like "23:00-07:00".
+func parseQuietHours(v string) (*quietWindow, error) {
+	parts := strings.Split(v, "-")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("expected HH:MM-HH:MM, got %q", v)
+	}
+
+	var w quietWindow
+	for i, p := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(p))
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q", p)
+		}
+		d := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		if i == 0 {
+			w.start = d
+		} else {
+			w.end = d
+		}
+	}
+	return &w, nil
+}
+
+// active reports whether now falls in the window, and if so when it ends.
+func (w *quietWindow) active(now time.Time) (bool, time.Time) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	offset := now.Sub(midnight)
+
+	if w.start <= w.end {
+		if offset >= w.start && offset < w.end {
+			return true, midnight.Add(w.end)
+		}
+		return false, time.Time{}
+	}
+
+	// Window wraps past midnight
+	if offset >= w.start {
+		return true, midnight.AddDate(0, 0, 1).Add(w.end)
+	}
+	if offset < w.end {
+		return true, midnight.Add(w.end)
+	}
+	return false, time.Time{}
+}
+
+// allowQuietSearch records a search and reports whether it fits in the
+// hourly quiet-hours allowance.
+func allowQuietSearch(now time.Time) bool {
+	quietMu.Lock()
+	defer quietMu.Unlock()
+
+	cutoff := now.Add(-time.Hour)
+	recent := quietSearches[:0]
+	for _, t := range quietSearches {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	quietSearches = recent
+
+	if len(quietSearches) >= config.QuietHoursLimit {
+		return false
+	}
+	quietSearches = append(quietSearches, now)
+	return true
+}
+
+// withQuietHours wraps a search-triggering tool handler so that, during quiet
+// hours, calls beyond the hourly allowance are deferred until the window ends
+// (automatic searches, see deferSearch) or refused (interactive searches).
+func withQuietHours(kind int, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if config.QuietHours == nil {
+			return handler(ctx, req)
+		}
+
+		now := time.Now()
+		active, until := config.QuietHours.active(now)
+		if !active || allowQuietSearch(now) {
+			return handler(ctx, req)
+		}
+
+		if kind == searchInteractive {
+			return mcp.NewToolResultError(fmt.Sprintf(
+				"Quiet hours are in effect until %s and the search allowance (%d/hour) is used up. Interactive searches are paused to protect indexer limits; try again after %s.",
+				until.Format("15:04"), config.QuietHoursLimit, until.Format("15:04"))), nil
+		}
+
+		runAt, repeat := deferSearch(req, handler, until)
+		deferred := searchCommand{CommandIDs: []int{}, Deferred: true, RunAt: &runAt}
+		if repeat {
+			return mcp.NewToolResultStructured(deferred, fmt.Sprintf(
+				"Quiet hours are in effect until %s, and the same search is already deferred; it will run at %s (as long as the server stays running).",
+				until.Format("15:04"), runAt.Format("15:04"))), nil
+		}
+		return mcp.NewToolResultStructured(deferred, fmt.Sprintf(
+			"Quiet hours are in effect until %s, so this search has been deferred and will run automatically at %s (as long as the server stays running).",
+			until.Format("15:04"), runAt.Format("15:04"))), nil
+	}
+}
+
+// deferSearch queues an automatic search to run once quiet hours end and
+// returns when it will. Queued searches run one at a time, spaced out to the
+// quiet-hours allowance so they don't all hit the indexers at once. A search
+// already queued with the same arguments isn't queued again; repeat is set
+// and its existing time returned.
+func deferSearch(req mcp.CallToolRequest, handler server.ToolHandlerFunc, until time.Time) (runAt time.Time, repeat bool) {
+	args, _ := json.Marshal(req.GetArguments())
+	key := req.Params.Name + " " + string(args)
+
+	quietMu.Lock()
+	defer quietMu.Unlock()
+	if d, ok := quietQueued[key]; ok {
+		return d.runAt, true
+	}
+
+	interval := quietReleaseInterval
+	if config.QuietHoursLimit > 0 {
+		interval = time.Hour / time.Duration(config.QuietHoursLimit)
+	}
+	runAt = until
+	if n := len(quietQueue); n > 0 {
+		if next := quietQueue[n-1].runAt.Add(interval); next.After(runAt) {
+			runAt = next
+		}
+	}
+	d := &deferredSearch{key: key, req: req, handler: handler, runAt: runAt}
+	quietQueue = append(quietQueue, d)
+	quietQueued[key] = d
+
+	quietQueueOnce.Do(func() { go runQuietQueue() })
+	select {
+	case quietQueueWake <- struct{}{}:
+	default:
+	}
+	return runAt, false
+}
+
+// runQuietQueue runs deferred searches as they come due. Searches are only
+// ever appended with a later time, so it waits on the head of the queue.
+func runQuietQueue() {
+	for {
+		quietMu.Lock()
+		if len(quietQueue) == 0 {
+			quietMu.Unlock()
+			<-quietQueueWake
+			continue
+		}
+		d := quietQueue[0]
+		quietMu.Unlock()
+
+		time.Sleep(time.Until(d.runAt))
+
+		quietMu.Lock()
+		quietQueue = quietQueue[1:]
+		delete(quietQueued, d.key)
+		quietMu.Unlock()
+
+		name := d.req.Params.Name
+		result, err := d.handler(context.Background(), d.req)
+		if err != nil {
+			log.Printf("Deferred %s failed: %v", name, err)
+		} else if result != nil && result.IsError {
+			log.Printf("Deferred %s returned an error", name)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ============================================================================
+// Quotas
+// ============================================================================
+
+// ULTIMARR_QUOTAS limits how many changes each user of the HTTP transport can
+// make, by role, so an automated agent stuck in a loop can't hammer indexers
+// or fill the disks. Usage is kept in memory and starts over on restart.
+
+// quota is how many counted tool calls one user may make. Zero is unlimited.
+type quota struct {
+	PerHour int `json:"perHour"`
+	PerDay  int `json:"perDay"`
+}
+
+// Role whose quota applies to users whose own role has none
+const defaultRole = "default"
+
+// Every call to a tool that isn't annotated read-only counts against quotas:
+// those change something or search indexers. quotaDryRuns lists the tools
+// whose calls only count when the check returns true, so dry runs are free.
+var quotaDryRuns = map[string]func(args map[string]interface{}) bool{
+	"sonarr_bulk_delete":            argSet("confirm_token"),
+	"radarr_bulk_delete":            argSet("confirm_token"),
+	"ultimarr_duplicate_cleanup":    argSet("confirm_token"),
+	"ultimarr_replace_unregistered": argSet("confirm"),
+	"ultimarr_reconcile":            argSet("confirm"),
+	"ultimarr_test_indexers":        argSet("series_id", "movie_id"),
+	"jellyseerr_my_reminders":       argSet("clear_ready"),
+}
+
+// readOnlyTool reports whether the named tool is annotated read-only.
+func readOnlyTool(ctx context.Context, name string) bool {
+	if s := server.ServerFromContext(ctx); s != nil {
+		if t := s.GetTool(name); t != nil {
+			ro := t.Tool.Annotations.ReadOnlyHint
+			return ro != nil && *ro
+		}
+	}
+	return false
+}
+
+// countsAgainstQuota reports whether a tool call is counted.
+func countsAgainstQuota(ctx context.Context, req mcp.CallToolRequest) bool {
+	if readOnlyTool(ctx, req.Params.Name) {
+		return false
+	}
+	if check, ok := quotaDryRuns[req.Params.Name]; ok {
+		return check(req.GetArguments())
+	}
+	return true
+}
+
+// argSet returns a check for calls that pass any of the named arguments
+// with a value other than false or "".
+func argSet(names ...string) func(args map[string]interface{}) bool {
+	return func(args map[string]interface{}) bool {
+		for _, name := range names {
+			switch v := args[name].(type) {
+			case nil:
+			case bool:
+				if v {
+					return true
+				}
+			case string:
+				if v != "" {
+					return true
+				}
+			default:
+				return true
+			}
+		}
+		return false
+	}
+}
+
+var (
+	quotaMu    sync.Mutex
+	quotaCalls = map[string][]time.Time{} // per user, counted calls in the last day
+)
+
+// userQuota returns the quota for a user's role.
+func userQuota(u apiUser) (quota, bool) {
+	if q, ok := config.Quotas[u.Role]; ok {
+		return q, true
+	}
+	q, ok := config.Quotas[defaultRole]
+	return q, ok
+}
+
+// withToolQuota is tool handler middleware enforcing ULTIMARR_QUOTAS.
+func withToolQuota(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		user, ok := userFromContext(ctx)
+		if !ok {
+			return next(ctx, req)
+		}
+		if !countsAgainstQuota(ctx, req) {
+			return next(ctx, req)
+		}
+		q, ok := userQuota(user)
+		if !ok {
+			return next(ctx, req)
+		}
+		if msg := takeQuota(user, q, time.Now()); msg != "" {
+			return mcp.NewToolResultError(msg), nil
+		}
+		return next(ctx, req)
+	}
+}
+
+// takeQuota records a counted call for user, or returns why it's refused.
+// Refused calls aren't recorded.
+func takeQuota(user apiUser, q quota, now time.Time) string {
+	quotaMu.Lock()
+	defer quotaMu.Unlock()
+
+	calls := quotaCalls[user.Name]
+	for len(calls) > 0 && now.Sub(calls[0]) >= 24*time.Hour {
+		calls = calls[1:]
+	}
+	hour := 0
+	for _, t := range calls {
+		if now.Sub(t) < time.Hour {
+			hour++
+		}
+	}
+
+	limits := []struct {
+		n      int
+		used   int
+		window time.Duration
+		name   string
+	}{
+		{q.PerHour, hour, time.Hour, "hour"},
+		{q.PerDay, len(calls), 24 * time.Hour, "day"},
+	}
+	for _, l := range limits {
+		if l.n > 0 && l.used >= l.n {
+			// The oldest call in the window frees the next slot
+			next := calls[len(calls)-l.used].Add(l.window)
+			quotaCalls[user.Name] = calls
+			return fmt.Sprintf("Quota reached: %s (role %s) may make %d changes or indexer searches per %s. The next one is allowed at %s.",
+				user.Name, user.Role, l.n, l.name, next.Local().Format("15:04"))
+		}
+	}
+	quotaCalls[user.Name] = append(calls, now)
+	return ""
+}
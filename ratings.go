@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ============================================================================
+// Content Ratings
+// ============================================================================
+
+// Ratings come from TMDB through Jellyseerr: a movie's certification for
+// its release in ULTIMARR_RATING_COUNTRY, or a show's content rating there.
+// ULTIMARR_MAX_RATING compares them by the minimum age they suggest, so a
+// limit given as a movie rating also applies to TV ratings and to countries
+// with numeric ratings.
+
+// Rating actions for titles above ULTIMARR_MAX_RATING
+const (
+	ratingFlag  = "flag"
+	ratingBlock = "block"
+)
+
+// Minimum ages for ratings that don't carry one in their name
+var ratingAges = map[string]int{
+	"G": 0, "TV-Y": 0, "TV-G": 0, "U": 0,
+	"TV-Y7": 7, "TV-Y7-FV": 7,
+	"PG": 10, "TV-PG": 10,
+	"PG-13": 13, "TV-14": 13,
+	"R": 17, "TV-MA": 17,
+	"NC-17": 18,
+}
+
+var ratingDigits = regexp.MustCompile(`\d+`)
+
+// contentRating is a title's rating and the content descriptors that came
+// with it, if any. Country is set when the rating is from the US because
+// ULTIMARR_RATING_COUNTRY had none.
+type contentRating struct {
+	Rating   string   `json:"rating"`
+	Country  string   `json:"country,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+var (
+	ratingMu    sync.Mutex
+	ratingCache = map[titleKey]contentRating{}
+)
+
+// ratingAge returns the minimum age a rating suggests.
+func ratingAge(rating string) (int, bool) {
+	rating = strings.ToUpper(strings.TrimSpace(rating))
+	if age, ok := ratingAges[rating]; ok {
+		return age, true
+	}
+	// Numeric ratings such as "12", "12A", "FSK 16", or "MA15+"
+	if d := ratingDigits.FindString(rating); d != "" {
+		age, _ := strconv.Atoi(d)
+		return age, true
+	}
+	return 0, false
+}
+
+// validateMaxRating checks that ULTIMARR_MAX_RATING and
+// ULTIMARR_RATING_ACTION can be applied.
+func validateMaxRating() error {
+	if _, ok := ratingAge(config.MaxRating); !ok {
+		return fmt.Errorf("unknown rating %q (use e.g. PG-13, TV-14, or an age such as 12)", config.MaxRating)
+	}
+	if config.RatingAction != ratingFlag && config.RatingAction != ratingBlock {
+		return fmt.Errorf("ULTIMARR_RATING_ACTION must be %q or %q, not %q", ratingFlag, ratingBlock, config.RatingAction)
+	}
+	return nil
+}
+
+// aboveMaxRating reports whether a rating is above ULTIMARR_MAX_RATING.
+// Unrated titles and ratings that can't be compared never are; with
+// ULTIMARR_BLOCK_UNRATED, jellyseerr_request refuses those separately.
+func aboveMaxRating(rating string) bool {
+	if config.MaxRating == "" {
+		return false
+	}
+	limit, _ := ratingAge(config.MaxRating)
+	age, ok := ratingAge(rating)
+	return ok && age > limit
+}
+
+// comparableRating reports whether a rating gives an age to compare with
+// ULTIMARR_MAX_RATING.
+func comparableRating(rating string) bool {
+	_, ok := ratingAge(rating)
+	return ok
+}
+
+// titleRating looks up a title's rating in Jellyseerr. Ratings are cached.
+func titleRating(ctx context.Context, mediaType string, tmdbID int) (contentRating, error) {
+	k := titleKey{mediaType, tmdbID}
+	ratingMu.Lock()
+	r, ok := ratingCache[k]
+	ratingMu.Unlock()
+	if ok {
+		return r, nil
+	}
+
+	data, err := jellyseerrRequest(ctx, "GET", fmt.Sprintf("/%s/%d", mediaType, tmdbID), nil)
+	if err != nil {
+		return contentRating{}, err
+	}
+	var details map[string]interface{}
+	json.Unmarshal(data, &details)
+	r = ratingFromDetails(details)
+
+	ratingMu.Lock()
+	if len(ratingCache) >= maxCachedTitles {
+		ratingCache = map[titleKey]contentRating{}
+	}
+	ratingCache[k] = r
+	ratingMu.Unlock()
+	return r, nil
+}
+
+// ratingFromDetails picks the rating for ULTIMARR_RATING_COUNTRY out of
+// Jellyseerr movie or TV details, falling back to the US rating.
+func ratingFromDetails(details map[string]interface{}) contentRating {
+	byCountry := map[string]contentRating{}
+
+	// Movies: releases.results[].release_dates[].certification
+	releases, _ := details["releases"].(map[string]interface{})
+	results, _ := releases["results"].([]interface{})
+	for _, raw := range results {
+		country, _ := raw.(map[string]interface{})
+		code, _ := country["iso_3166_1"].(string)
+		dates, _ := country["release_dates"].([]interface{})
+		for _, d := range dates {
+			date, _ := d.(map[string]interface{})
+			if cert, _ := date["certification"].(string); cert != "" {
+				byCountry[code] = contentRating{Rating: cert, Warnings: stringList(date["descriptors"])}
+				break
+			}
+		}
+	}
+
+	// TV: contentRatings.results[].rating
+	ratings, _ := details["contentRatings"].(map[string]interface{})
+	results, _ = ratings["results"].([]interface{})
+	for _, raw := range results {
+		country, _ := raw.(map[string]interface{})
+		code, _ := country["iso_3166_1"].(string)
+		if rating, _ := country["rating"].(string); rating != "" {
+			byCountry[code] = contentRating{Rating: rating, Warnings: stringList(country["descriptors"])}
+		}
+	}
+
+	if r, ok := byCountry[config.RatingCountry]; ok {
+		return r
+	}
+	if r, ok := byCountry["US"]; ok {
+		r.Country = "US"
+		return r
+	}
+	return contentRating{}
+}
+
+func stringList(raw interface{}) []string {
+	items, _ := raw.([]interface{})
+	var list []string
+	for _, item := range items {
+		if s, ok := item.(string); ok && s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// describeRating renders a rating for text results, e.g. "Rated TV-MA (US)".
+func describeRating(r contentRating) string {
+	if r.Rating == "" {
+		return "Not rated"
+	}
+	text := "Rated " + r.Rating
+	if r.Country != "" {
+		text += " (" + r.Country + ")"
+	}
+	if len(r.Warnings) > 0 {
+		text += ": " + strings.Join(r.Warnings, ", ")
+	}
+	return text
+}
+
+// addRatings looks up the ratings of search results, several at a time.
+func addRatings(ctx context.Context, results []mediaResult) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < titleLookupWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				m := &results[i]
+				r, err := titleRating(ctx, m.MediaType, m.TmdbID)
+				if err != nil {
+					continue
+				}
+				rating := r
+				m.Rating = &rating
+				m.AboveMaxRating = aboveMaxRating(r.Rating)
+			}
+		}()
+	}
+	for i := range results {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// Status Reconciliation
+// ============================================================================
+
+// Jellyseerr tracks each title's status itself and only catches up with
+// Sonarr/Radarr on its periodic scans, so it can say a title is available
+// after its files were deleted, or keep it processing long after it was
+// imported. Titles are matched like ultimarr_in_flight does: movies by TMDB
+// ID and series by TVDB ID, so series Jellyseerr has no TVDB ID for are
+// skipped. Only the non-4K status is checked.
+//
+// Media can be available without Sonarr/Radarr, such as files added to
+// Jellyfin by hand, so an available title missing from the *arr is only a
+// mismatch when Jellyfin doesn't have it either. Without Jellyfin configured
+// that can't be told, and such titles are left alone.
+
+// Kinds of mismatch between Jellyseerr and Sonarr/Radarr
+const (
+	mismatchAvailableMissing = "available_missing" // available in Jellyseerr, no files in the *arr
+	mismatchProcessingDone   = "processing_done"   // processing in Jellyseerr, every monitored file imported
+	mismatchProcessingAbsent = "processing_absent" // processing in Jellyseerr, not in the *arr at all
+)
+
+// Jellyseerr media and requests listed per page
+const mediaPageSize = 100
+
+// reconcileReport is the structured result of ultimarr_reconcile. Fixes are
+// only applied with confirm=true.
+type reconcileReport struct {
+	DryRun     bool       `json:"dryRun"`
+	Checked    int        `json:"checked"` // Jellyseerr titles compared
+	Mismatches []mismatch `json:"mismatches"`
+	Errors     []string   `json:"errors,omitempty"`
+}
+
+// mismatch is one title whose Jellyseerr status disagrees with Sonarr/Radarr.
+// Fix says what reconciling does: a Jellyseerr status to set, or
+// "retry request" to send a stuck request to the *arr again.
+type mismatch struct {
+	Kind      string `json:"kind"`
+	Title     string `json:"title"`
+	MediaType string `json:"mediaType"`
+	TmdbID    int    `json:"tmdbId"`
+	MediaID   int    `json:"mediaId"` // Jellyseerr media ID
+	RequestID int    `json:"requestId,omitempty"`
+	Status    string `json:"status"` // in Jellyseerr
+	Detail    string `json:"detail"` // what the *arr has
+	Fix       string `json:"fix"`
+	Fixed     bool   `json:"fixed"`
+	Error     string `json:"error,omitempty"`
+}
+
+// arrTitle is what reconciliation needs to know about a series or movie.
+type arrTitle struct {
+	Title     string
+	Monitored bool
+	Files     int
+	Wanted    int  // monitored episodes, or 1 for a monitored movie
+	Partial   bool // a series with unmonitored seasons
+}
+
+func handleUltimarrReconcile(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	confirm, _ := args["confirm"].(bool)
+	only, _ := args["media_type"].(string)
+	if confirm && !jellyseerrCan("manage_requests") {
+		return mcp.NewToolResultError("Fixing statuses needs the Manage Requests permission in Jellyseerr; run without confirm to only list mismatches."), nil
+	}
+
+	report := reconcileReport{DryRun: !confirm, Mismatches: []mismatch{}}
+	library := map[string]map[string]arrTitle{} // by media type, then "tmdb:603" or "tvdb:81189"
+	for _, svc := range arrServices() {
+		mediaType := "tv"
+		if svc.Name == "Radarr" {
+			mediaType = "movie"
+		}
+		if only != "" && only != mediaType {
+			continue
+		}
+		titles, err := arrTitles(ctx, svc)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", svc.Name, err))
+			continue
+		}
+		library[mediaType] = titles
+	}
+	if len(library) == 0 {
+		if len(report.Errors) > 0 {
+			return mcp.NewToolResultError(strings.Join(report.Errors, "\n")), nil
+		}
+		return mcp.NewToolResultError("No Sonarr/Radarr configured for that media type"), nil
+	}
+
+	requests, err := processingRequests(ctx)
+	if err != nil {
+		report.Errors = append(report.Errors, "Jellyseerr requests: "+err.Error())
+	}
+
+	var inJellyfin map[string]bool
+	if isConfigured(jellyfin) {
+		if inJellyfin, err = jellyfinLibrary(ctx); err != nil {
+			report.Errors = append(report.Errors, "Jellyfin: "+err.Error()+"; available titles missing from Sonarr/Radarr weren't checked")
+		}
+	}
+
+	for _, filter := range []string{"allavailable", "processing"} {
+		media, err := jellyseerrMedia(ctx, filter)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for _, m := range media {
+			mediaType, _ := m["mediaType"].(string)
+			titles, ok := library[mediaType]
+			if !ok {
+				continue
+			}
+			if tvdbID, _ := m["tvdbId"].(float64); mediaType == "tv" && tvdbID == 0 {
+				continue
+			}
+			report.Checked++
+			if mm, ok := checkMedia(m, titles, inJellyfin); ok {
+				mm.RequestID = requests[mm.MediaID]
+				if mm.Kind == mismatchProcessingAbsent && mm.RequestID > 0 {
+					mm.Fix = "retry request"
+				}
+				report.Mismatches = append(report.Mismatches, mm)
+			}
+		}
+	}
+
+	var keys []titleKey
+	for _, mm := range report.Mismatches {
+		if mm.Title == "" {
+			keys = append(keys, titleKey{mm.MediaType, mm.TmdbID})
+		}
+	}
+	prefetchTitles(ctx, keys)
+
+	for i := range report.Mismatches {
+		mm := &report.Mismatches[i]
+		if mm.Title == "" {
+			if mm.Title = jellyseerrTitle(ctx, mm.MediaType, mm.TmdbID); mm.Title == "" {
+				mm.Title = fmt.Sprintf("TMDB %d", mm.TmdbID)
+			}
+		}
+		if !confirm {
+			continue
+		}
+		var err error
+		if mm.Fix == "retry request" {
+			_, err = jellyseerrRequest(ctx, "POST", fmt.Sprintf("/request/%d/retry", mm.RequestID), nil)
+		} else {
+			_, err = jellyseerrRequest(ctx, "POST", fmt.Sprintf("/media/%d/%s", mm.MediaID, mm.Fix), nil)
+		}
+		if err != nil {
+			mm.Error = err.Error()
+		} else {
+			mm.Fixed = true
+		}
+	}
+
+	var lines []string
+	if len(report.Mismatches) == 0 {
+		lines = append(lines, fmt.Sprintf("Jellyseerr and Sonarr/Radarr agree on all %d titles checked.", report.Checked))
+	} else {
+		lines = append(lines, fmt.Sprintf("%d of %d titles disagree:", len(report.Mismatches), report.Checked))
+	}
+	for _, mm := range report.Mismatches {
+		lines = append(lines, fmt.Sprintf("  %s (%s): %s in Jellyseerr, but %s", mm.Title, mm.MediaType, mm.Status, mm.Detail))
+		fix := "mark " + mm.Fix
+		if mm.Fix == "retry request" {
+			fix = fmt.Sprintf("retry request #%d", mm.RequestID)
+		}
+		switch {
+		case mm.Error != "":
+			lines = append(lines, fmt.Sprintf("    Couldn't %s: %s", fix, mm.Error))
+		case mm.Fixed:
+			lines = append(lines, "    Fixed: "+fix)
+		default:
+			lines = append(lines, "    Fix: "+fix)
+		}
+	}
+	for _, e := range report.Errors {
+		lines = append(lines, "Warning: "+e)
+	}
+	if !confirm && len(report.Mismatches) > 0 {
+		lines = append(lines, "", "Nothing has been changed. Call again with confirm=true to apply these fixes.")
+	}
+	return mcp.NewToolResultStructured(report, strings.Join(lines, "\n")), nil
+}
+
+// checkMedia compares a Jellyseerr media entry with the *arr's library and
+// returns the mismatch, if any, with the fix that resolves it. inJellyfin is
+// the Jellyfin library (see jellyfinLibrary), or nil if it isn't known.
+func checkMedia(m map[string]interface{}, titles map[string]arrTitle, inJellyfin map[string]bool) (mismatch, bool) {
+	mediaType, _ := m["mediaType"].(string)
+	id, _ := m["id"].(float64)
+	tmdbID, _ := m["tmdbId"].(float64)
+	tvdbID, _ := m["tvdbId"].(float64)
+	code, _ := m["status"].(float64)
+	status := mediaStatusNames[int(code)]
+
+	key := fmt.Sprintf("tmdb:%d", int(tmdbID))
+	if mediaType == "tv" && tvdbID > 0 {
+		key = fmt.Sprintf("tvdb:%d", int(tvdbID))
+	}
+	t, inLibrary := titles[key]
+	mm := mismatch{Title: t.Title, MediaType: mediaType, TmdbID: int(tmdbID), MediaID: int(id), Status: status}
+
+	switch status {
+	case "available", "partial":
+		switch {
+		case !inLibrary:
+			// Only a mismatch if it isn't playable from Jellyfin either
+			if inJellyfin == nil || inJellyfin[mediaType+":"+key] {
+				return mm, false
+			}
+			mm.Kind, mm.Detail, mm.Fix = mismatchAvailableMissing, "it isn't in "+arrName(mediaType)+" or Jellyfin", "unknown"
+		case t.Files > 0:
+			return mm, false
+		case t.Monitored:
+			// It will be downloaded again, so it's in progress once more
+			mm.Kind, mm.Detail, mm.Fix = mismatchAvailableMissing, "it has no files (still monitored)", "processing"
+		default:
+			mm.Kind, mm.Detail, mm.Fix = mismatchAvailableMissing, "it has no files and isn't monitored", "unknown"
+		}
+	case "processing":
+		switch {
+		case !inLibrary:
+			// Clearing the status lets it be requested again
+			mm.Kind, mm.Detail, mm.Fix = mismatchProcessingAbsent, "it isn't in "+arrName(mediaType), "unknown"
+		case t.Wanted > 0 && t.Files >= t.Wanted:
+			mm.Kind, mm.Detail, mm.Fix = mismatchProcessingDone, "everything monitored has been imported", "available"
+			if t.Partial {
+				mm.Fix = "partial"
+			}
+		default:
+			return mm, false
+		}
+	default:
+		return mm, false
+	}
+	return mm, true
+}
+
+func arrName(mediaType string) string {
+	if mediaType == "movie" {
+		return "Radarr"
+	}
+	return "Sonarr"
+}
+
+// arrTitles loads a service's library keyed like Jellyseerr media: movies by
+// TMDB ID, series by TVDB ID.
+func arrTitles(ctx context.Context, svc arrService) (map[string]arrTitle, error) {
+	endpoint, provider := "/series", "tvdb"
+	if svc.Name == "Radarr" {
+		endpoint, provider = "/movie", "tmdb"
+	}
+	data, err := svc.Request(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	var items []map[string]interface{}
+	json.Unmarshal(data, &items)
+
+	titles := map[string]arrTitle{}
+	for _, item := range items {
+		id, _ := item[provider+"Id"].(float64)
+		t := arrTitle{}
+		t.Title, _ = item["title"].(string)
+		t.Monitored, _ = item["monitored"].(bool)
+		if svc.Name == "Radarr" {
+			if hasFile, _ := item["hasFile"].(bool); hasFile {
+				t.Files = 1
+			}
+			if t.Monitored {
+				t.Wanted = 1
+			}
+		} else {
+			stats, _ := item["statistics"].(map[string]interface{})
+			files, _ := stats["episodeFileCount"].(float64)
+			wanted, _ := stats["episodeCount"].(float64)
+			t.Files, t.Wanted = int(files), int(wanted)
+			seasons, _ := item["seasons"].([]interface{})
+			for _, s := range seasons {
+				season, _ := s.(map[string]interface{})
+				number, _ := season["seasonNumber"].(float64)
+				if monitored, _ := season["monitored"].(bool); number > 0 && !monitored {
+					t.Partial = true
+				}
+			}
+		}
+		titles[fmt.Sprintf("%s:%d", provider, int(id))] = t
+	}
+	return titles, nil
+}
+
+// jellyseerrMedia pages through the media Jellyseerr lists under filter.
+func jellyseerrMedia(ctx context.Context, filter string) ([]map[string]interface{}, error) {
+	var media []map[string]interface{}
+	for skip := 0; ; skip += mediaPageSize {
+		data, err := jellyseerrRequest(ctx, "GET", fmt.Sprintf("/media?take=%d&skip=%d&filter=%s", mediaPageSize, skip, filter), nil)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Results []map[string]interface{} `json:"results"`
+		}
+		json.Unmarshal(data, &page)
+		media = append(media, page.Results...)
+		if len(page.Results) < mediaPageSize {
+			return media, nil
+		}
+	}
+}
+
+// processingRequests maps Jellyseerr media IDs to their approved, not yet
+// available request, so a stuck one can be retried.
+func processingRequests(ctx context.Context) (map[int]int, error) {
+	requests := map[int]int{}
+	for skip := 0; ; skip += mediaPageSize {
+		data, err := jellyseerrRequest(ctx, "GET", fmt.Sprintf("/request?take=%d&skip=%d&filter=processing", mediaPageSize, skip), nil)
+		if err != nil {
+			return requests, err
+		}
+		var page struct {
+			Results []map[string]interface{} `json:"results"`
+		}
+		json.Unmarshal(data, &page)
+		for _, r := range page.Results {
+			id, _ := r["id"].(float64)
+			media, _ := r["media"].(map[string]interface{})
+			mediaID, _ := media["id"].(float64)
+			if mediaID > 0 {
+				requests[int(mediaID)] = int(id)
+			}
+		}
+		if len(page.Results) < mediaPageSize {
+			return requests, nil
+		}
+	}
+}
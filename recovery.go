@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ============================================================================
+// Recovery Hints
+// ============================================================================
+
+// Tool errors mostly pass on what the backend said, which tells a person what
+// went wrong but leaves an assistant guessing what to do about it. For the
+// common failures, the error result names the tool to call next and with which
+// arguments, so an agent can correct itself. It goes in the text and in _meta
+// rather than structured content, which has to match each tool's own output
+// schema.
+
+// Kinds of failure with a known way out
+const (
+	failureStaleRelease   = "stale_release"   // release GUID no longer in the *arr's cache
+	failureInvalidProfile = "invalid_profile" // profile_id doesn't exist
+	failureNotFound       = "not_found"       // the series, movie, or request ID doesn't exist
+	failureServiceDown    = "service_down"    // the backend couldn't be reached or answered 5xx
+	failureAuth           = "auth"            // the backend rejected the API key
+)
+
+// Keys in an error result's _meta
+const (
+	metaRecovery = "recovery" // a toolFailure
+	metaTimeout  = "timeout"  // a toolTimeout
+)
+
+// toolFailure is added to an error result's _meta under metaRecovery.
+// SuggestedArguments are for SuggestedNextTool, which may be the tool that
+// failed.
+type toolFailure struct {
+	Error              string                 `json:"error"`
+	Category           string                 `json:"category"`
+	SuggestedNextTool  string                 `json:"suggested_next_tool"`
+	SuggestedArguments map[string]interface{} `json:"suggested_arguments,omitempty"`
+	Hint               string                 `json:"hint"`
+}
+
+// Services whose own tools start with each prefix
+var toolPrefixes = map[string]string{
+	"sonarr_":     "Sonarr",
+	"radarr_":     "Radarr",
+	"jellyseerr_": "Jellyseerr",
+	"jellyfin_":   "Jellyfin",
+}
+
+// idArguments are the ID arguments whose 404s are recognized, with what they
+// identify and the tool that lists valid IDs.
+var idArguments = []struct{ name, noun, list string }{
+	{"series_id", "Sonarr series", "sonarr_list_series"},
+	{"movie_id", "Radarr movie", "radarr_list_movies"},
+	{"request_id", "Jellyseerr request", "jellyseerr_list_requests"},
+}
+
+var httpStatusPattern = regexp.MustCompile(`HTTP (\d{3})`)
+
+// Errors from the network layer, before any response
+var unreachableErrors = []string{
+	"connection refused", "no such host", "i/o timeout", "Client.Timeout",
+	"connection reset", "no route to host", "network is unreachable",
+}
+
+// withRecoveryHint is tool handler middleware that adds a toolFailure to
+// error results it recognizes. Timeouts already carry their own advice and
+// are left alone.
+func withRecoveryHint(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, req)
+		if err != nil || result == nil || !result.IsError || hasMeta(result, metaTimeout) || len(result.Content) == 0 {
+			return result, err
+		}
+		text, ok := result.Content[0].(mcp.TextContent)
+		if !ok {
+			return result, err
+		}
+		failure, ok := classifyFailure(req.Params.Name, req.GetArguments(), text.Text)
+		if !ok {
+			return result, err
+		}
+		text.Text += "\n\nSuggestion: " + failure.Hint
+		result.Content[0] = text
+		setMeta(result, metaRecovery, failure)
+		return result, err
+	}
+}
+
+// setMeta adds a field to a result's _meta.
+func setMeta(result *mcp.CallToolResult, key string, value interface{}) {
+	if result.Meta == nil {
+		result.Meta = &mcp.Meta{}
+	}
+	if result.Meta.AdditionalFields == nil {
+		result.Meta.AdditionalFields = map[string]any{}
+	}
+	result.Meta.AdditionalFields[key] = value
+}
+
+func hasMeta(result *mcp.CallToolResult, key string) bool {
+	return result.Meta != nil && result.Meta.AdditionalFields[key] != nil
+}
+
+// classifyFailure works out from a tool's error message what went wrong and
+// what to call next.
+func classifyFailure(tool string, args map[string]interface{}, msg string) (toolFailure, bool) {
+	f := toolFailure{Error: msg}
+	status := 0
+	if m := httpStatusPattern.FindStringSubmatch(msg); m != nil {
+		status, _ = strconv.Atoi(m[1])
+	}
+	service := toolService(tool, msg)
+	lower := strings.ToLower(msg)
+
+	switch {
+	case (tool == "sonarr_download_release" || tool == "radarr_download_release") &&
+		(status == 404 || strings.Contains(lower, "release in cache")):
+		f.Category = failureStaleRelease
+		idArg := "series_id"
+		if tool == "radarr_download_release" {
+			idArg = "movie_id"
+		}
+		f.SuggestedNextTool = strings.Replace(tool, "download_release", "get_releases", 1)
+		if id, ok := args[idArg].(float64); ok {
+			f.SuggestedArguments = map[string]interface{}{idArg: id}
+		}
+		f.Hint = fmt.Sprintf("%s only keeps search results for about 30 minutes, so the GUID has expired. Run %s again and download from the fresh results.", service, f.SuggestedNextTool)
+
+	case args["profile_id"] != nil && strings.Contains(lower, "profile") &&
+		(status == 400 || status == 404 || strings.Contains(lower, "not exist") || strings.Contains(lower, "invalid")):
+		f.Category = failureInvalidProfile
+		f.SuggestedNextTool = tool
+		f.SuggestedArguments = map[string]interface{}{}
+		for k, v := range args {
+			if k != "profile_id" {
+				f.SuggestedArguments[k] = v
+			}
+		}
+		f.Hint = fmt.Sprintf("Quality profile %v doesn't exist. Call %s again without profile_id to use the default profile.", args["profile_id"], tool)
+
+	case status == 404:
+		for _, a := range idArguments {
+			if id, ok := args[a.name].(float64); ok {
+				f.Category = failureNotFound
+				f.SuggestedNextTool = a.list
+				f.Hint = fmt.Sprintf("There is no %s with ID %d. Use %s to find the right ID.", a.noun, int(id), a.list)
+				break
+			}
+		}
+		if f.Category == "" {
+			return f, false
+		}
+
+	case status >= 502 && status <= 504 || containsAny(msg, unreachableErrors):
+		if !isDiagnosable(service) {
+			return f, false
+		}
+		f.Category = failureServiceDown
+		f.SuggestedNextTool = "ultimarr_diagnose_connection"
+		f.SuggestedArguments = map[string]interface{}{"service": strings.ToLower(service)}
+		f.Hint = fmt.Sprintf("%s couldn't be reached. Run ultimarr_diagnose_connection to find out why before retrying.", service)
+
+	case status == 401:
+		if !isDiagnosable(service) {
+			return f, false
+		}
+		f.Category = failureAuth
+		f.SuggestedNextTool = "ultimarr_diagnose_connection"
+		f.SuggestedArguments = map[string]interface{}{"service": strings.ToLower(service)}
+		f.Hint = fmt.Sprintf("%s rejected the API key; retrying won't help. Run ultimarr_diagnose_connection to check the credentials.", service)
+
+	default:
+		return f, false
+	}
+	return f, true
+}
+
+// toolService names the service a tool's error came from: the one the tool
+// belongs to, or else the first configured service the message mentions.
+func toolService(tool, msg string) string {
+	for prefix, name := range toolPrefixes {
+		if strings.HasPrefix(tool, prefix) {
+			return name
+		}
+	}
+	for _, t := range connectionTargets() {
+		if strings.Contains(msg, t.Name) {
+			return t.Name
+		}
+	}
+	return ""
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
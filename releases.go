@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// Release Searches
+// ============================================================================
+
+// Interactive searches query every indexer, so they get longer than the
+// usual request timeout
+const releaseSearchTimeout = 3 * time.Minute
+
+// How long results of a search that outlived max_wait_seconds are kept for
+// the follow-up call
+const releaseSearchKeep = 10 * time.Minute
+
+// releaseSearch is an interactive search running in the background. data and
+// err are set before done is closed.
+type releaseSearch struct {
+	done     chan struct{}
+	data     []byte
+	err      error
+	finished time.Time
+}
+
+// lastReleases is the outcome of the latest finished search for a key, shown
+// while a newer one is still running.
+type lastReleases struct {
+	data     []byte
+	finished time.Time
+}
+
+var (
+	releaseSearchesMu sync.Mutex
+	releaseSearches   = map[string]*releaseSearch{}
+	releaseLast       = map[string]lastReleases{}
+)
+
+// maxWait reads the max_wait_seconds argument (0 if unset).
+func maxWait(args map[string]interface{}) time.Duration {
+	if v, ok := args["max_wait_seconds"].(float64); ok && v > 0 {
+		return time.Duration(v * float64(time.Second))
+	}
+	return 0
+}
+
+// searchReleases runs fetch for an interactive search identified by key,
+// waiting at most wait (0 to wait until it finishes). If the deadline passes
+// first, done is false and the search carries on in the background; a later
+// call with the same key picks up the running search or its results instead
+// of starting another one. Until then, the results of the last search for the
+// same key that finished within releaseSearchKeep are returned, if any.
+func searchReleases(ctx context.Context, key string, wait time.Duration, fetch func(ctx context.Context) ([]byte, error)) ([]byte, bool, error) {
+	releaseSearchesMu.Lock()
+	for k, s := range releaseSearches {
+		if !s.finished.IsZero() && time.Since(s.finished) > releaseSearchKeep {
+			delete(releaseSearches, k)
+		}
+	}
+	for k, l := range releaseLast {
+		if time.Since(l.finished) > releaseSearchKeep {
+			delete(releaseLast, k)
+		}
+	}
+	search, ok := releaseSearches[key]
+	if !ok {
+		search = &releaseSearch{done: make(chan struct{})}
+		releaseSearches[key] = search
+		go func() {
+			// The search outlives the call that started it
+			data, err := fetch(context.WithoutCancel(ctx))
+			releaseSearchesMu.Lock()
+			search.data, search.err, search.finished = data, err, time.Now()
+			if err == nil {
+				releaseLast[key] = lastReleases{data, search.finished}
+			}
+			releaseSearchesMu.Unlock()
+			close(search.done)
+		}()
+	}
+	releaseSearchesMu.Unlock()
+
+	var deadline <-chan time.Time
+	if wait > 0 {
+		deadline = time.After(wait)
+	}
+
+	select {
+	case <-search.done:
+		releaseSearchesMu.Lock()
+		if releaseSearches[key] == search {
+			delete(releaseSearches, key)
+		}
+		releaseSearchesMu.Unlock()
+		return search.data, true, search.err
+	case <-deadline:
+		releaseSearchesMu.Lock()
+		last := releaseLast[key]
+		releaseSearchesMu.Unlock()
+		return last.data, false, nil
+	}
+}
+
+// releaseSearchPending is the result for a search still running at its
+// max_wait_seconds deadline with no earlier results to show. The *arr APIs
+// return releases only once every indexer has answered.
+func releaseSearchPending(wait time.Duration) *mcp.CallToolResult {
+	list := releaseList{Pending: true, Releases: []release{}}
+	return mcp.NewToolResultStructured(list, fmt.Sprintf(
+		"Indexers are still being searched after %s, so no releases are available yet. The search is continuing in the background; call again with the same arguments to collect the results (kept for %d minutes).",
+		wait, int(releaseSearchKeep.Minutes())))
+}
+
+// markReleasesPending flags a list built from an earlier search's results
+// while a new search is still running, and says so ahead of lines.
+func markReleasesPending(list *releaseList, lines []string, wait time.Duration) []string {
+	list.Pending = true
+	note := fmt.Sprintf("Indexers are still being searched after %s; these are the results of the previous search. Call again with the same arguments to collect the new results (kept for %d minutes).",
+		wait, int(releaseSearchKeep.Minutes()))
+	return append([]string{note, ""}, lines...)
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// Reminders
+// ============================================================================
+
+// Reminder records interest in a Jellyseerr request so that it can be
+// reported once the media becomes available. User is the HTTP user who set
+// it; it is empty over stdio, where every reminder is shared.
+type Reminder struct {
+	RequestID   int        `json:"requestId"`
+	TmdbID      int        `json:"tmdbId"`
+	MediaType   string     `json:"mediaType"`
+	Title       string     `json:"title"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	AvailableAt *time.Time `json:"availableAt,omitempty"`
+	Partial     bool       `json:"partial,omitempty"`
+	User        string     `json:"user,omitempty"`
+}
+
+// ownsReminder reports whether the caller in ctx can see r. Without a user
+// (stdio) every reminder is visible.
+func ownsReminder(ctx context.Context, r *Reminder) bool {
+	user, ok := userFromContext(ctx)
+	return !ok || r.User == user.Name
+}
+
+var (
+	remindersMu sync.Mutex
+	reminders   []*Reminder
+)
+
+func remindersPath() string {
+	return filepath.Join(config.DataDir, "reminders.json")
+}
+
+// loadReminders reads saved reminders from the data directory and registers
+// the poller that checks pending ones.
+func loadReminders() error {
+	addPoller(pollReminders)
+
+	data, err := os.ReadFile(remindersPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	remindersMu.Lock()
+	defer remindersMu.Unlock()
+	return json.Unmarshal(data, &reminders)
+}
+
+func saveRemindersLocked() error {
+	if err := os.MkdirAll(config.DataDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(reminders, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := remindersPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, remindersPath())
+}
+
+// handleReminderEvent marks matching reminders as available.
+func handleReminderEvent(e Event) {
+	if e.Type != EventMediaAvailable {
+		return
+	}
+
+	remindersMu.Lock()
+	defer remindersMu.Unlock()
+
+	changed := false
+	for _, r := range reminders {
+		if r.AvailableAt != nil && !r.Partial {
+			continue
+		}
+		matches := (e.RequestID != 0 && r.RequestID == e.RequestID) ||
+			(e.TmdbID != 0 && r.TmdbID == e.TmdbID && r.MediaType == e.MediaType)
+		if !matches {
+			continue
+		}
+		if r.AvailableAt == nil {
+			t := e.Time
+			r.AvailableAt = &t
+		}
+		r.Partial = e.Partial
+		changed = true
+	}
+
+	if changed {
+		if err := saveRemindersLocked(); err != nil {
+			log.Printf("Saving reminders: %v", err)
+		}
+	}
+}
+
+// pollReminders checks every reminder that isn't fully available yet and
+// publishes an event for each one whose media has become available.
+func pollReminders(ctx context.Context) {
+	remindersMu.Lock()
+	var pending []Reminder
+	for _, r := range reminders {
+		if r.AvailableAt == nil || r.Partial {
+			pending = append(pending, *r)
+		}
+	}
+	remindersMu.Unlock()
+
+	for _, r := range pending {
+		data, err := jellyseerrRequest(ctx, "GET", fmt.Sprintf("/request/%d", r.RequestID), nil)
+		if err != nil {
+			continue
+		}
+
+		var result map[string]interface{}
+		json.Unmarshal(data, &result)
+
+		media, _ := result["media"].(map[string]interface{})
+		status, _ := media["status"].(float64)
+		if int(status) != 4 && int(status) != 5 {
+			continue
+		}
+		// Only re-publish partial availability once it becomes complete
+		if r.Partial && int(status) == 5 {
+			continue
+		}
+
+		publish(Event{
+			Type:      EventMediaAvailable,
+			RequestID: r.RequestID,
+			TmdbID:    r.TmdbID,
+			MediaType: r.MediaType,
+			Partial:   int(status) == 5,
+		})
+	}
+}
+
+// reminderResult is the structured result of jellyseerr_remind_me. Status is
+// "set", "already_set", or "already_available".
+type reminderResult struct {
+	RequestID int    `json:"requestId"`
+	Title     string `json:"title"`
+	Status    string `json:"status"`
+}
+
+// reminderList is the structured result of jellyseerr_my_reminders.
+type reminderList struct {
+	Ready   []Reminder `json:"ready"`
+	Waiting []Reminder `json:"waiting"`
+}
+
+func handleJellyseerrRemindMe(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	requestID := int(args["request_id"].(float64))
+
+	data, err := jellyseerrRequest(ctx, "GET", fmt.Sprintf("/request/%d", requestID), nil)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var request map[string]interface{}
+	json.Unmarshal(data, &request)
+
+	media, ok := request["media"].(map[string]interface{})
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("Request %d has no media", requestID)), nil
+	}
+	mediaType, _ := media["mediaType"].(string)
+	tmdbID := 0
+	if t, ok := media["tmdbId"].(float64); ok {
+		tmdbID = int(t)
+	}
+	title := jellyseerrTitle(ctx, mediaType, tmdbID)
+	if title == "" {
+		title = fmt.Sprintf("TMDB %d", tmdbID)
+	}
+
+	result := reminderResult{RequestID: requestID, Title: title}
+	if status, ok := media["status"].(float64); ok && int(status) == 4 {
+		result.Status = "already_available"
+		return mcp.NewToolResultStructured(result, fmt.Sprintf("%s is already available.", title)), nil
+	}
+	if status, ok := request["status"].(float64); ok && int(status) == 3 {
+		return mcp.NewToolResultError(fmt.Sprintf("Request %d for %s was declined", requestID, title)), nil
+	}
+
+	user, _ := userFromContext(ctx)
+
+	remindersMu.Lock()
+	defer remindersMu.Unlock()
+
+	for _, r := range reminders {
+		if r.RequestID == requestID && ownsReminder(ctx, r) {
+			result.Status = "already_set"
+			return mcp.NewToolResultStructured(result, fmt.Sprintf("Already reminding about %s (request #%d).", title, requestID)), nil
+		}
+	}
+
+	reminders = append(reminders, &Reminder{
+		RequestID: requestID,
+		TmdbID:    tmdbID,
+		MediaType: mediaType,
+		Title:     title,
+		CreatedAt: time.Now(),
+		User:      user.Name,
+	})
+	if err := saveRemindersLocked(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result.Status = "set"
+	return mcp.NewToolResultStructured(result, fmt.Sprintf("Reminder set for %s (request #%d). It will show up in jellyseerr_my_reminders once available.", title, requestID)), nil
+}
+
+func handleJellyseerrMyReminders(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	clearReady, _ := args["clear_ready"].(bool)
+
+	// Refresh now rather than waiting for the next poll tick
+	pollReminders(ctx)
+
+	remindersMu.Lock()
+	defer remindersMu.Unlock()
+
+	var ready, waiting []string
+	var keep []*Reminder
+	list := reminderList{Ready: []Reminder{}, Waiting: []Reminder{}}
+	for _, r := range reminders {
+		// Other users' reminders are kept but not shown
+		if !ownsReminder(ctx, r) {
+			keep = append(keep, r)
+			continue
+		}
+		line := fmt.Sprintf("  #%d [%s] %s (TMDB: %d)", r.RequestID, strings.ToUpper(r.MediaType), r.Title, r.TmdbID)
+		if r.AvailableAt == nil {
+			waiting = append(waiting, line+" - requested "+r.CreatedAt.Format("2006-01-02"))
+			list.Waiting = append(list.Waiting, *r)
+			keep = append(keep, r)
+			continue
+		}
+
+		state := "available"
+		if r.Partial {
+			state = "partially available"
+		}
+		ready = append(ready, fmt.Sprintf("%s - %s since %s", line, state, r.AvailableAt.Format("2006-01-02 15:04")))
+		list.Ready = append(list.Ready, *r)
+		if !clearReady || r.Partial {
+			keep = append(keep, r)
+		}
+	}
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("Ready (%d):", len(ready)))
+	lines = append(lines, ready...)
+	if len(ready) == 0 {
+		lines = append(lines, "  (none)")
+	}
+	lines = append(lines, "", fmt.Sprintf("Waiting (%d):", len(waiting)))
+	lines = append(lines, waiting...)
+	if len(waiting) == 0 {
+		lines = append(lines, "  (none)")
+	}
+
+	if clearReady && len(keep) != len(reminders) {
+		reminders = keep
+		if err := saveRemindersLocked(); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	return mcp.NewToolResultStructured(list, strings.Join(lines, "\n")), nil
+}
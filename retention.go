@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// Retention Policies
+// ============================================================================
+
+// retentionPolicy is one rule from ULTIMARR_RETENTION. Its criteria are the
+// *_bulk_delete filters, so the candidates a policy reports can be deleted
+// with the same arguments.
+type retentionPolicy struct {
+	Name            string  `json:"name"`
+	Service         string  `json:"service"` // "sonarr" or "radarr"
+	Tag             string  `json:"tag,omitempty"`
+	Genre           string  `json:"genre,omitempty"`
+	Unmonitored     bool    `json:"unmonitored,omitempty"`
+	Ended           bool    `json:"ended,omitempty"`
+	OlderThanDays   int     `json:"olderThanDays,omitempty"`
+	NotWatchedDays  int     `json:"notWatchedDays,omitempty"`
+	Watched         bool    `json:"watched,omitempty"`
+	BelowSizeGB     float64 `json:"belowSizeGb,omitempty"`
+	MinSizeGB       float64 `json:"minSizeGb,omitempty"`
+	BelowResolution int     `json:"belowResolution,omitempty"`
+}
+
+// filters returns the policy as *_bulk_delete arguments.
+func (p retentionPolicy) filters() map[string]interface{} {
+	args := map[string]interface{}{}
+	if p.Tag != "" {
+		args["tag"] = p.Tag
+	}
+	if p.Genre != "" {
+		args["genre"] = p.Genre
+	}
+	if p.Unmonitored {
+		args["unmonitored"] = true
+	}
+	if p.Ended {
+		args["ended"] = true
+	}
+	if p.OlderThanDays > 0 {
+		args["older_than_days"] = float64(p.OlderThanDays)
+	}
+	if p.NotWatchedDays > 0 {
+		args["not_watched_days"] = float64(p.NotWatchedDays)
+	}
+	if p.Watched {
+		args["watched"] = true
+	}
+	if p.BelowSizeGB > 0 {
+		args["below_size_gb"] = p.BelowSizeGB
+	}
+	if p.MinSizeGB > 0 {
+		args["min_size_gb"] = p.MinSizeGB
+	}
+	if p.BelowResolution > 0 {
+		args["below_resolution"] = float64(p.BelowResolution)
+	}
+	return args
+}
+
+// validateRetention checks the policies parsed from ULTIMARR_RETENTION.
+func validateRetention(policies []retentionPolicy) error {
+	seen := map[string]bool{}
+	for i, p := range policies {
+		if p.Name == "" {
+			return fmt.Errorf("policy %d has no name", i+1)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("duplicate policy name %q", p.Name)
+		}
+		seen[p.Name] = true
+		if p.Service != "sonarr" && p.Service != "radarr" {
+			return fmt.Errorf("policy %q: service must be \"sonarr\" or \"radarr\"", p.Name)
+		}
+		if len(p.filters()) == 0 {
+			return fmt.Errorf("policy %q has no criteria and would match the whole library", p.Name)
+		}
+		if p.Ended && p.Service != "sonarr" {
+			return fmt.Errorf("policy %q: ended only applies to sonarr", p.Name)
+		}
+		if p.BelowResolution > 0 && p.Service != "radarr" {
+			return fmt.Errorf("policy %q: belowResolution only applies to radarr", p.Name)
+		}
+	}
+	return nil
+}
+
+// retentionReport is the structured result of ultimarr_retention_report.
+type retentionReport struct {
+	Policies  []policyCandidates `json:"policies"`
+	TotalSize int64              `json:"totalSize"` // counting items matched by several policies once
+}
+
+type policyCandidates struct {
+	Name      string           `json:"name"`
+	Service   string           `json:"service"`
+	Items     []bulkDeleteItem `json:"items"`
+	TotalSize int64            `json:"totalSize"`
+	// Left out because Jellyfin couldn't be matched for a watch filter
+	WatchUnknown []bulkDeleteItem `json:"watchStateUnknown,omitempty"`
+	Error        string           `json:"error,omitempty"`
+}
+
+func handleUltimarrRetentionReport(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	only, _ := args["policy"].(string)
+
+	report := retentionReport{Policies: []policyCandidates{}}
+	candidates := map[string][]bulkDeleteCandidate{}
+	counted := map[string]bool{}
+	var lines []string
+	for _, p := range config.Retention {
+		if only != "" && p.Name != only {
+			continue
+		}
+		service, request := "Sonarr", arrRequestFunc(sonarrRequest)
+		if p.Service == "radarr" {
+			service, request = "Radarr", radarrRequest
+		}
+		pc := policyCandidates{Name: p.Name, Service: service, Items: []bulkDeleteItem{}}
+
+		matches, unknown, err := func() ([]bulkDeleteCandidate, []bulkDeleteCandidate, error) {
+			if _, ok := candidates[service]; !ok {
+				all, err := bulkDeleteCandidates(ctx, service, request)
+				if err != nil {
+					return nil, nil, err
+				}
+				candidates[service] = all
+			}
+			return filterBulkDelete(ctx, p.filters(), service, request, candidates[service])
+		}()
+		if err != nil {
+			pc.Error = err.Error()
+			report.Policies = append(report.Policies, pc)
+			lines = append(lines, fmt.Sprintf("%s (%s): ERROR %v", p.Name, service, err), "")
+			continue
+		}
+
+		var items []string
+		for _, c := range matches {
+			pc.Items = append(pc.Items, bulkDeleteItem{ID: c.ID, Title: c.Title, Size: c.Size})
+			pc.TotalSize += c.Size
+			if key := fmt.Sprintf("%s:%d", service, c.ID); !counted[key] {
+				counted[key] = true
+				report.TotalSize += c.Size
+			}
+			items = append(items, fmt.Sprintf("  [%d] %s (%s)", c.ID, c.Title, formatBytes(c.Size)))
+		}
+		for _, c := range unknown {
+			pc.WatchUnknown = append(pc.WatchUnknown, bulkDeleteItem{ID: c.ID, Title: c.Title, Size: c.Size})
+		}
+		report.Policies = append(report.Policies, pc)
+
+		lines = append(lines, fmt.Sprintf("%s (%s): %d candidate(s), %s", p.Name, service, len(matches), formatBytes(pc.TotalSize)))
+		lines = append(lines, items...)
+		if len(unknown) > 0 {
+			lines = append(lines, fmt.Sprintf("  (%d more left out: watch state unknown, not found in Jellyfin)", len(unknown)))
+		}
+		lines = append(lines, "")
+	}
+	if len(report.Policies) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("No retention policy named %q", only)), nil
+	}
+
+	lines = append(lines, fmt.Sprintf("Reclaimable in total: %s. Nothing has been deleted; use sonarr_bulk_delete or radarr_bulk_delete with the policy's filters to remove candidates.", formatBytes(report.TotalSize)))
+	return mcp.NewToolResultStructured(report, strings.Join(lines, "\n")), nil
+}
+
+func retentionPolicyNames() []string {
+	var names []string
+	for _, p := range config.Retention {
+		names = append(names, p.Name)
+	}
+	return names
+}
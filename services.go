@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ============================================================================
+// Services
+// ============================================================================
+
+// Service is a backend ultimarr integrates with. Adding one means
+// implementing Service in the backend's own file, keeping its settings on
+// the Service value, and listing it in services; startup, tool registration,
+// ultimarr_status, and the connection diagnostics pick it up from there.
+// Demo mode only covers services that have canned responses in demo.go.
+type Service interface {
+	// Name is the display name, e.g. "Sonarr"
+	Name() string
+	// Configure reads the service's settings from the environment into the
+	// receiver and reports whether it is configured
+	Configure() bool
+	// RegisterTools adds the service's own tools. It runs once every
+	// service is configured, so tools that also need another service can
+	// check isConfigured; cross-service ultimarr_* tools are registered
+	// separately
+	RegisterTools(s *server.MCPServer)
+	// HealthCheck makes an authenticated call and returns the version
+	HealthCheck(ctx context.Context) (string, error)
+}
+
+// httpService is a Service reached at a base URL, which lets
+// ultimarr_diagnose_connection probe it and timeouts name it.
+type httpService interface {
+	Service
+	URL() string
+}
+
+// statusReporter is a Service that reports more than its version to
+// ultimarr_status: queue, disk space, health warnings, or request counts.
+type statusReporter interface {
+	Service
+	Status(ctx context.Context, st *serviceStatus)
+}
+
+// arrBackend is a Sonarr-like Service, used by the tools that work across
+// the *arrs.
+type arrBackend interface {
+	Service
+	arr() arrService
+}
+
+// services lists every integration, in the order their tools are registered
+// and their status is shown.
+var services = []Service{
+	jellyseerr,
+	sonarr,
+	radarr,
+	jellyfin,
+	qbittorrent,
+	sabnzbd,
+}
+
+// configured holds the services whose Configure reported them configured.
+var configured []Service
+
+// configureServices configures every service, then registers the tools of
+// the ones that are set up.
+func configureServices(s *server.MCPServer) {
+	configured = nil
+	for _, svc := range services {
+		if svc.Configure() {
+			configured = append(configured, svc)
+		}
+	}
+	for _, svc := range configured {
+		svc.RegisterTools(s)
+	}
+}
+
+// isConfigured reports whether svc was configured at startup.
+func isConfigured(svc Service) bool {
+	for _, c := range configured {
+		if c == svc {
+			return true
+		}
+	}
+	return false
+}
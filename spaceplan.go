@@ -0,0 +1,515 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// Space Planner
+// ============================================================================
+
+// Outstanding content is sized from the library itself: a series' missing
+// episodes at its own average episode size, anything else at the average
+// size per minute of runtime of titles with the same quality profile. Only
+// when a profile has no files yet does the planner fall back to typical
+// sizes for the profile's cutoff resolution.
+
+// Typical size per minute of runtime by cutoff resolution, in MB
+var typicalMBPerMinute = map[int]float64{
+	2160: 200,
+	1080: 50,
+	720:  20,
+	480:  10,
+}
+
+// Runtime assumed for titles without one, in minutes
+const (
+	defaultEpisodeRuntime = 45
+	defaultMovieRuntime   = 120
+)
+
+// Disks with less than this share of their capacity left afterwards are
+// reported as tight
+const tightSpaceRatio = 0.1
+
+// Sonarr/Radarr's default minimum free space when importing, in MB
+const defaultMinFreeMB = 100
+
+// Largest outstanding titles listed per disk
+const spacePlanLargest = 5
+
+// spacePlan is the structured result of ultimarr_space_plan.
+type spacePlan struct {
+	Disks []diskPlan `json:"disks"`
+}
+
+// diskPlan compares the outstanding content stored on one disk with its free
+// space. Left is what would remain after everything is downloaded, and goes
+// negative when it won't fit. Verdict is "fits", "tight", or "won't fit".
+type diskPlan struct {
+	Path    string        `json:"path"`
+	Total   int64         `json:"total,omitempty"`
+	Free    int64         `json:"free"`
+	Needed  int64         `json:"needed"`
+	Left    int64         `json:"left"`
+	MinFree int64         `json:"minFree"` // imports stop below this
+	Verdict string        `json:"verdict"`
+	Folders []folderNeed  `json:"folders"`
+	Largest []plannedItem `json:"largest"`
+}
+
+type folderNeed struct {
+	Service  string `json:"service"`
+	Path     string `json:"path"`
+	Episodes int    `json:"episodes,omitempty"`
+	Movies   int    `json:"movies,omitempty"`
+	Needed   int64  `json:"needed"`
+}
+
+// plannedItem is a title with monitored content still to download. Basis
+// says how its size was estimated.
+type plannedItem struct {
+	Service string `json:"service"`
+	Title   string `json:"title"`
+	Missing int    `json:"missing"` // episodes, or 1 for a movie
+	Size    int64  `json:"size"`
+	Basis   string `json:"basis"`
+	path    string
+}
+
+// arrDisk is a disk as a service reports it in /diskspace, with the root
+// folders on it.
+type arrDisk struct {
+	Path    string
+	Total   int64
+	Free    int64
+	MinFree int64
+	Roots   []rootFolder
+}
+
+type rootFolder struct {
+	Service string
+	Path    string
+	Free    int64
+}
+
+func handleUltimarrSpacePlan(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	only, _ := args["service"].(string)
+
+	disks := map[string]*arrDisk{}
+	var order []string
+	var items []plannedItem
+	var errs []string
+	for _, svc := range arrServices() {
+		if only != "" && !strings.EqualFold(only, svc.Name) {
+			continue
+		}
+		roots, err := rootFolders(ctx, svc)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", svc.Name, err))
+			continue
+		}
+		var planned []plannedItem
+		if svc.Name == "Sonarr" {
+			planned, err = plannedEpisodes(ctx, svc)
+		} else {
+			planned, err = plannedMovies(ctx, svc)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", svc.Name, err))
+			continue
+		}
+		items = append(items, planned...)
+
+		minFree := int64(defaultMinFreeMB)
+		if data, err := svc.Request(ctx, "GET", "/config/mediamanagement", nil); err == nil {
+			var mm map[string]interface{}
+			json.Unmarshal(data, &mm)
+			if mb, ok := mm["minimumFreeSpaceWhenImporting"].(float64); ok {
+				minFree = int64(mb)
+			}
+		}
+		minFree *= 1024 * 1024
+
+		// Root folders on the same disk share its free space, so needs are
+		// added up per disk rather than per folder
+		mounts := diskMounts(ctx, svc)
+		for _, root := range roots {
+			key := "root:" + root.Path
+			disk := &arrDisk{Path: root.Path, Free: root.Free}
+			if m, ok := longestMount(mounts, root.Path); ok {
+				key = fmt.Sprintf("%s:%d", m.Path, m.Total)
+				disk = &arrDisk{Path: m.Path, Total: m.Total, Free: m.Free}
+			}
+			if disks[key] == nil {
+				disks[key] = disk
+				order = append(order, key)
+			}
+			disks[key].Roots = append(disks[key].Roots, root)
+			disks[key].MinFree = max(disks[key].MinFree, minFree)
+		}
+	}
+	if len(disks) == 0 && len(errs) == 0 {
+		return mcp.NewToolResultError("Neither Sonarr nor Radarr is configured"), nil
+	}
+	if len(disks) == 0 {
+		return mcp.NewToolResultError(strings.Join(errs, "\n")), nil
+	}
+
+	plan := spacePlan{Disks: []diskPlan{}}
+	var lines []string
+	unplaced := 0
+	placed := make([]bool, len(items))
+	for _, key := range order {
+		disk := disks[key]
+		dp := diskPlan{Path: disk.Path, Total: disk.Total, Free: disk.Free, MinFree: disk.MinFree, Folders: []folderNeed{}, Largest: []plannedItem{}}
+		for _, root := range disk.Roots {
+			fn := folderNeed{Service: root.Service, Path: root.Path}
+			for i, item := range items {
+				if placed[i] || item.Service != root.Service || !inFolder(item.path, root.Path) {
+					continue
+				}
+				placed[i] = true
+				fn.Needed += item.Size
+				if item.Service == "Sonarr" {
+					fn.Episodes += item.Missing
+				} else {
+					fn.Movies++
+				}
+				dp.Largest = append(dp.Largest, item)
+			}
+			dp.Needed += fn.Needed
+			dp.Folders = append(dp.Folders, fn)
+		}
+		sort.Slice(dp.Largest, func(i, j int) bool { return dp.Largest[i].Size > dp.Largest[j].Size })
+		if len(dp.Largest) > spacePlanLargest {
+			dp.Largest = dp.Largest[:spacePlanLargest]
+		}
+		dp.Left = dp.Free - dp.Needed
+		switch {
+		case dp.Left < dp.MinFree:
+			dp.Verdict = "won't fit"
+		case dp.Total > 0 && float64(dp.Left) < tightSpaceRatio*float64(dp.Total):
+			dp.Verdict = "tight"
+		default:
+			dp.Verdict = "fits"
+		}
+		plan.Disks = append(plan.Disks, dp)
+
+		header := fmt.Sprintf("Disk %s: %s free", dp.Path, formatBytes(dp.Free))
+		if dp.Total > 0 {
+			header += " of " + formatBytes(dp.Total)
+		}
+		lines = append(lines, header)
+		for _, fn := range dp.Folders {
+			what := fmt.Sprintf("%d missing episode(s)", fn.Episodes)
+			if fn.Service == "Radarr" {
+				what = fmt.Sprintf("%d missing movie(s)", fn.Movies)
+			}
+			lines = append(lines, fmt.Sprintf("  %s %s: %s, ~%s", fn.Service, fn.Path, what, formatBytes(fn.Needed)))
+		}
+		switch dp.Verdict {
+		case "won't fit":
+			lines = append(lines, fmt.Sprintf("  WON'T FIT: needs ~%s, %s short of keeping the %s imports need free", formatBytes(dp.Needed), formatBytes(dp.MinFree-dp.Left), formatBytes(dp.MinFree)))
+		case "tight":
+			lines = append(lines, fmt.Sprintf("  TIGHT: needs ~%s, leaving %s (%.0f%% of the disk)", formatBytes(dp.Needed), formatBytes(dp.Left), 100*float64(dp.Left)/float64(dp.Total)))
+		default:
+			lines = append(lines, fmt.Sprintf("  Fits: needs ~%s, leaving %s", formatBytes(dp.Needed), formatBytes(dp.Left)))
+		}
+		if len(dp.Largest) > 0 {
+			lines = append(lines, "  Largest:")
+			for _, item := range dp.Largest {
+				what := item.Title
+				if item.Service == "Sonarr" {
+					what = fmt.Sprintf("%s (%d episode(s))", item.Title, item.Missing)
+				}
+				lines = append(lines, fmt.Sprintf("    %s ~%s, %s", what, formatBytes(item.Size), item.Basis))
+			}
+		}
+		lines = append(lines, "")
+	}
+	for i := range items {
+		if !placed[i] {
+			unplaced++
+		}
+	}
+	if unplaced > 0 {
+		lines = append(lines, fmt.Sprintf("%d title(s) with missing content aren't under a root folder and weren't counted.", unplaced))
+	}
+	for _, e := range errs {
+		lines = append(lines, "ERROR: "+e)
+	}
+	lines = append(lines, "Estimates assume everything monitored and missing gets downloaded at the sizes your library already has; upgrades of existing files aren't counted.")
+	return mcp.NewToolResultStructured(plan, strings.Join(lines, "\n")), nil
+}
+
+// plannedEpisodes lists monitored series with aired, monitored episodes that
+// have no file, skipping specials.
+func plannedEpisodes(ctx context.Context, svc arrService) ([]plannedItem, error) {
+	data, err := svc.Request(ctx, "GET", "/series", nil)
+	if err != nil {
+		return nil, err
+	}
+	var series []map[string]interface{}
+	json.Unmarshal(data, &series)
+
+	rates := profileRates(series, func(s map[string]interface{}) (float64, float64) {
+		stats, _ := s["statistics"].(map[string]interface{})
+		size, _ := stats["sizeOnDisk"].(float64)
+		files, _ := stats["episodeFileCount"].(float64)
+		return size, files * runtimeOr(s, defaultEpisodeRuntime)
+	})
+	typical := typicalRates(ctx, svc)
+
+	var items []plannedItem
+	for _, s := range series {
+		if monitored, _ := s["monitored"].(bool); !monitored {
+			continue
+		}
+		missing := 0
+		seasons, _ := s["seasons"].([]interface{})
+		for _, raw := range seasons {
+			season, _ := raw.(map[string]interface{})
+			number, _ := season["seasonNumber"].(float64)
+			if monitored, _ := season["monitored"].(bool); !monitored || number == 0 {
+				continue
+			}
+			stats, _ := season["statistics"].(map[string]interface{})
+			aired, _ := stats["episodeCount"].(float64)
+			files, _ := stats["episodeFileCount"].(float64)
+			missing += max(int(aired-files), 0)
+		}
+		if missing == 0 {
+			continue
+		}
+
+		item := plannedItem{Service: svc.Name, Missing: missing}
+		item.Title, _ = s["title"].(string)
+		item.path, _ = s["path"].(string)
+		stats, _ := s["statistics"].(map[string]interface{})
+		size, _ := stats["sizeOnDisk"].(float64)
+		files, _ := stats["episodeFileCount"].(float64)
+		profile, _ := s["qualityProfileId"].(float64)
+		runtime := runtimeOr(s, defaultEpisodeRuntime)
+		switch {
+		case files > 0 && size > 0:
+			item.Size = int64(size / files * float64(missing))
+			item.Basis = "series average"
+		case rates[int(profile)] > 0:
+			item.Size = int64(rates[int(profile)] * runtime * float64(missing))
+			item.Basis = "profile average"
+		default:
+			item.Size = int64(typicalRate(typical, int(profile)) * runtime * float64(missing))
+			item.Basis = "typical size"
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// plannedMovies lists monitored movies without a file.
+func plannedMovies(ctx context.Context, svc arrService) ([]plannedItem, error) {
+	data, err := svc.Request(ctx, "GET", "/movie", nil)
+	if err != nil {
+		return nil, err
+	}
+	var movies []map[string]interface{}
+	json.Unmarshal(data, &movies)
+
+	rates := profileRates(movies, func(m map[string]interface{}) (float64, float64) {
+		if hasFile, _ := m["hasFile"].(bool); !hasFile {
+			return 0, 0
+		}
+		size, _ := m["sizeOnDisk"].(float64)
+		return size, runtimeOr(m, defaultMovieRuntime)
+	})
+	typical := typicalRates(ctx, svc)
+
+	var items []plannedItem
+	for _, m := range movies {
+		monitored, _ := m["monitored"].(bool)
+		hasFile, _ := m["hasFile"].(bool)
+		if !monitored || hasFile {
+			continue
+		}
+		item := plannedItem{Service: svc.Name, Missing: 1}
+		item.Title, _ = m["title"].(string)
+		if year, _ := m["year"].(float64); year > 0 {
+			item.Title = fmt.Sprintf("%s (%d)", item.Title, int(year))
+		}
+		item.path, _ = m["path"].(string)
+		profile, _ := m["qualityProfileId"].(float64)
+		runtime := runtimeOr(m, defaultMovieRuntime)
+		if rate := rates[int(profile)]; rate > 0 {
+			item.Size = int64(rate * runtime)
+			item.Basis = "profile average"
+		} else {
+			item.Size = int64(typicalRate(typical, int(profile)) * runtime)
+			item.Basis = "typical size"
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// profileRates works out the average bytes per minute of runtime for each
+// quality profile from the titles that have files. sample returns a title's
+// size on disk and the minutes of runtime it covers.
+func profileRates(titles []map[string]interface{}, sample func(map[string]interface{}) (float64, float64)) map[int]float64 {
+	sizes, minutes := map[int]float64{}, map[int]float64{}
+	for _, t := range titles {
+		size, mins := sample(t)
+		if size <= 0 || mins <= 0 {
+			continue
+		}
+		profile, _ := t["qualityProfileId"].(float64)
+		sizes[int(profile)] += size
+		minutes[int(profile)] += mins
+	}
+	rates := map[int]float64{}
+	for p, mins := range minutes {
+		rates[p] = sizes[p] / mins
+	}
+	return rates
+}
+
+// typicalRates maps each quality profile to typicalMBPerMinute for its
+// cutoff resolution, in bytes. Profiles whose cutoff can't be resolved get
+// the 1080p rate.
+func typicalRates(ctx context.Context, svc arrService) map[int]float64 {
+	rates := map[int]float64{}
+	fallback := typicalMBPerMinute[1080] * 1024 * 1024
+	data, err := svc.Request(ctx, "GET", "/qualityprofile", nil)
+	if err != nil {
+		return rates
+	}
+	var profiles []map[string]interface{}
+	json.Unmarshal(data, &profiles)
+	for _, p := range profiles {
+		id, _ := p["id"].(float64)
+		cutoff, _ := p["cutoff"].(float64)
+		rates[int(id)] = fallback
+		if res := cutoffResolution(p["items"], int(cutoff)); res > 0 {
+			for _, r := range []int{2160, 1080, 720, 480} {
+				if res >= r {
+					rates[int(id)] = typicalMBPerMinute[r] * 1024 * 1024
+					break
+				}
+			}
+		}
+	}
+	return rates
+}
+
+// cutoffResolution finds the resolution of the quality (or the best quality
+// in the group) a profile's cutoff points at.
+func cutoffResolution(raw interface{}, cutoff int) int {
+	items, _ := raw.([]interface{})
+	for _, r := range items {
+		item, _ := r.(map[string]interface{})
+		if q, ok := item["quality"].(map[string]interface{}); ok {
+			if id, _ := q["id"].(float64); int(id) == cutoff {
+				res, _ := q["resolution"].(float64)
+				return int(res)
+			}
+			continue
+		}
+		// A group's ID is the cutoff when the cutoff is a group
+		id, _ := item["id"].(float64)
+		if int(id) != cutoff {
+			continue
+		}
+		best := 0
+		members, _ := item["items"].([]interface{})
+		for _, m := range members {
+			member, _ := m.(map[string]interface{})
+			q, _ := member["quality"].(map[string]interface{})
+			res, _ := q["resolution"].(float64)
+			best = max(best, int(res))
+		}
+		return best
+	}
+	return 0
+}
+
+// typicalRate returns a profile's typical bytes per minute, using the 1080p
+// rate for profiles typicalRates didn't see.
+func typicalRate(rates map[int]float64, profile int) float64 {
+	if r, ok := rates[profile]; ok {
+		return r
+	}
+	return typicalMBPerMinute[1080] * 1024 * 1024
+}
+
+func runtimeOr(title map[string]interface{}, fallback float64) float64 {
+	if r, _ := title["runtime"].(float64); r > 0 {
+		return r
+	}
+	return fallback
+}
+
+func rootFolders(ctx context.Context, svc arrService) ([]rootFolder, error) {
+	data, err := svc.Request(ctx, "GET", "/rootfolder", nil)
+	if err != nil {
+		return nil, err
+	}
+	var raw []map[string]interface{}
+	json.Unmarshal(data, &raw)
+	var roots []rootFolder
+	for _, r := range raw {
+		root := rootFolder{Service: svc.Name}
+		root.Path, _ = r["path"].(string)
+		free, _ := r["freeSpace"].(float64)
+		root.Free = int64(free)
+		roots = append(roots, root)
+	}
+	return roots, nil
+}
+
+// diskMounts lists the mounts a service reports free space for.
+func diskMounts(ctx context.Context, svc arrService) []arrDisk {
+	data, err := svc.Request(ctx, "GET", "/diskspace", nil)
+	if err != nil {
+		return nil
+	}
+	var raw []map[string]interface{}
+	json.Unmarshal(data, &raw)
+	var mounts []arrDisk
+	for _, r := range raw {
+		var d arrDisk
+		d.Path, _ = r["path"].(string)
+		free, _ := r["freeSpace"].(float64)
+		total, _ := r["totalSpace"].(float64)
+		d.Free, d.Total = int64(free), int64(total)
+		mounts = append(mounts, d)
+	}
+	return mounts
+}
+
+// longestMount returns the most specific mount holding folder.
+func longestMount(mounts []arrDisk, folder string) (arrDisk, bool) {
+	var best arrDisk
+	found := false
+	for _, m := range mounts {
+		if inFolder(folder, m.Path) && (!found || len(m.Path) > len(best.Path)) {
+			best, found = m, true
+		}
+	}
+	return best, found
+}
+
+// inFolder reports whether path is dir or inside it.
+func inFolder(path, dir string) bool {
+	dir = strings.TrimRight(dir, "/\\")
+	if dir == "" {
+		return true
+	}
+	return path == dir || strings.HasPrefix(path, dir+"/") || strings.HasPrefix(path, dir+"\\")
+}
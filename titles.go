@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// ============================================================================
+// Title Cache
+// ============================================================================
+
+// TMDB titles don't change, so titles seen in searches and detail lookups are
+// kept to label listings that only carry IDs without an API call per row.
+
+// Titles kept before the cache is cleared and starts over
+const maxCachedTitles = 10000
+
+// Concurrent lookups when resolving titles that aren't cached yet
+const titleLookupWorkers = 8
+
+type titleKey struct {
+	MediaType string
+	TmdbID    int
+}
+
+var (
+	titleMu     sync.Mutex
+	titleCache  = map[titleKey]string{}
+	titlesDirty bool // changed since the disk cache last saved it
+)
+
+// rememberTitle caches the title of a movie or TV show.
+func rememberTitle(mediaType string, tmdbID int, title string) {
+	if title == "" || tmdbID == 0 {
+		return
+	}
+	titleMu.Lock()
+	defer titleMu.Unlock()
+	if len(titleCache) >= maxCachedTitles {
+		titleCache = map[titleKey]string{}
+	}
+	k := titleKey{mediaType, tmdbID}
+	if titleCache[k] != title {
+		titleCache[k] = title
+		titlesDirty = true
+	}
+}
+
+func cachedTitle(mediaType string, tmdbID int) (string, bool) {
+	titleMu.Lock()
+	defer titleMu.Unlock()
+	title, ok := titleCache[titleKey{mediaType, tmdbID}]
+	return title, ok
+}
+
+// prefetchTitles looks up the titles in keys that aren't cached yet,
+// several at a time, so later jellyseerrTitle calls are cache hits.
+func prefetchTitles(ctx context.Context, keys []titleKey) {
+	jobs := make(chan titleKey)
+	var wg sync.WaitGroup
+	for i := 0; i < titleLookupWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range jobs {
+				jellyseerrTitle(ctx, k.MediaType, k.TmdbID)
+			}
+		}()
+	}
+
+	seen := map[titleKey]bool{}
+	for _, k := range keys {
+		if _, ok := cachedTitle(k.MediaType, k.TmdbID); ok || seen[k] {
+			continue
+		}
+		seen[k] = true
+		jobs <- k
+	}
+	close(jobs)
+	wg.Wait()
+}
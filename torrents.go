@@ -0,0 +1,391 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ============================================================================
+// Torrent Health
+// ============================================================================
+
+// Tracker messages that mean a torrent has been removed from the tracker.
+// Trackers word this differently; these cover the common ones.
+var unregisteredMessages = []string{
+	"unregistered",
+	"not registered",
+	"torrent not found",
+	"torrent does not exist",
+	"unknown torrent",
+	"infohash not found",
+	"torrent has been deleted",
+	"trumped",
+	"nuked",
+}
+
+// qBittorrent tracker status for "not working"
+const trackerNotWorking = 4
+
+// Tag added to torrents flagged by ultimarr_replace_unregistered
+const unregisteredTag = "unregistered"
+
+// torrentIssue is a completed torrent that is unregistered, has no working
+// tracker, or is missing its files.
+type torrentIssue struct {
+	Hash     string `json:"hash"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Tracker  string `json:"tracker"`
+	Message  string `json:"message"`
+}
+
+// crossSeedCandidate is a healthy completed torrent seeded on only one
+// tracker, so its data could be cross-seeded to others.
+type crossSeedCandidate struct {
+	Hash    string `json:"hash"`
+	Name    string `json:"name"`
+	Size    int64  `json:"size"`
+	Tracker string `json:"tracker"`
+}
+
+// torrentHealth is the structured result of ultimarr_torrent_health.
+// CrossSeeded counts torrents whose data is already seeded on several trackers.
+type torrentHealth struct {
+	Checked             int                  `json:"checked"`
+	Unregistered        []torrentIssue       `json:"unregistered"`
+	TrackerErrors       []torrentIssue       `json:"trackerErrors"`
+	MissingFiles        []torrentIssue       `json:"missingFiles"`
+	CrossSeeded         int                  `json:"crossSeeded"`
+	CrossSeedCandidates []crossSeedCandidate `json:"crossSeedCandidates"`
+}
+
+// checkTorrentHealth inspects completed torrents, optionally only those in
+// category. Trackers are only fetched for torrents without a working one.
+func checkTorrentHealth(ctx context.Context, category string) (torrentHealth, error) {
+	health := torrentHealth{
+		Unregistered:        []torrentIssue{},
+		TrackerErrors:       []torrentIssue{},
+		MissingFiles:        []torrentIssue{},
+		CrossSeedCandidates: []crossSeedCandidate{},
+	}
+
+	params := url.Values{"filter": {"completed"}}
+	if category != "" {
+		params.Set("category", category)
+	}
+	data, err := qbittorrentRequest(ctx, "GET", "/torrents/info?"+params.Encode(), nil)
+	if err != nil {
+		return health, err
+	}
+	var torrents []map[string]interface{}
+	json.Unmarshal(data, &torrents)
+	health.Checked = len(torrents)
+
+	// Cross-seeds share name and size but sit on different trackers
+	type content struct {
+		Name string
+		Size int64
+	}
+	seededOn := map[content]map[string]bool{}
+	var healthy []crossSeedCandidate
+
+	for _, t := range torrents {
+		hash, _ := t["hash"].(string)
+		name, _ := t["name"].(string)
+		cat, _ := t["category"].(string)
+		state, _ := t["state"].(string)
+		tracker, _ := t["tracker"].(string)
+		size, _ := t["size"].(float64)
+		issue := torrentIssue{Hash: hash, Name: name, Category: cat}
+
+		if state == "missingFiles" || state == "error" {
+			issue.Message = "files missing or unreadable (" + state + ")"
+			health.MissingFiles = append(health.MissingFiles, issue)
+			continue
+		}
+
+		if tracker != "" {
+			key := content{name, int64(size)}
+			if seededOn[key] == nil {
+				seededOn[key] = map[string]bool{}
+			}
+			seededOn[key][trackerHost(tracker)] = true
+			healthy = append(healthy, crossSeedCandidate{Hash: hash, Name: name, Size: int64(size), Tracker: trackerHost(tracker)})
+			continue
+		}
+
+		trackers, err := qbittorrentTrackers(ctx, hash)
+		if err != nil {
+			return health, err
+		}
+		for _, tr := range trackers {
+			status, _ := tr["status"].(float64)
+			if int(status) != trackerNotWorking {
+				continue
+			}
+			issue.Tracker, _ = tr["url"].(string)
+			issue.Tracker = trackerHost(issue.Tracker)
+			issue.Message, _ = tr["msg"].(string)
+			if isUnregistered(issue.Message) {
+				break
+			}
+		}
+		switch {
+		case isUnregistered(issue.Message):
+			health.Unregistered = append(health.Unregistered, issue)
+		case issue.Tracker != "":
+			health.TrackerErrors = append(health.TrackerErrors, issue)
+		}
+	}
+
+	for _, c := range healthy {
+		if len(seededOn[content{c.Name, c.Size}]) > 1 {
+			health.CrossSeeded++
+			continue
+		}
+		health.CrossSeedCandidates = append(health.CrossSeedCandidates, c)
+	}
+	sort.Slice(health.CrossSeedCandidates, func(i, j int) bool {
+		return health.CrossSeedCandidates[i].Size > health.CrossSeedCandidates[j].Size
+	})
+
+	return health, nil
+}
+
+// qbittorrentTrackers returns a torrent's real trackers, without the DHT,
+// PeX and LSD pseudo-entries.
+func qbittorrentTrackers(ctx context.Context, hash string) ([]map[string]interface{}, error) {
+	data, err := qbittorrentRequest(ctx, "GET", "/torrents/trackers?hash="+hash, nil)
+	if err != nil {
+		return nil, err
+	}
+	var trackers []map[string]interface{}
+	json.Unmarshal(data, &trackers)
+
+	var real []map[string]interface{}
+	for _, tr := range trackers {
+		if u, _ := tr["url"].(string); !strings.HasPrefix(u, "** [") {
+			real = append(real, tr)
+		}
+	}
+	return real, nil
+}
+
+func isUnregistered(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, m := range unregisteredMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// trackerHost reduces an announce URL (which may embed a passkey) to its host.
+func trackerHost(announce string) string {
+	if u, err := url.Parse(announce); err == nil && u.Host != "" {
+		return u.Hostname()
+	}
+	return announce
+}
+
+func registerTorrentTools(s *server.MCPServer) {
+	// Torrent Health
+	s.AddTool(
+		mcp.NewTool("ultimarr_torrent_health",
+			mcp.WithDescription("Check completed qBittorrent torrents against their trackers: flag unregistered torrents, trackers that aren't working, and missing files, and list torrents seeded on only one tracker that could be cross-seeded"),
+			mcp.WithString("category", mcp.Description("Only check torrents in this qBittorrent category (e.g. 'tv-sonarr')")),
+			mcp.WithNumber("limit", mcp.Description("Maximum cross-seed candidates to list (default 10)")),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOutputSchema[torrentHealth](),
+		),
+		handleUltimarrTorrentHealth,
+	)
+	s.AddTool(
+		mcp.NewTool("ultimarr_replace_unregistered",
+			mcp.WithDescription("Flag unregistered torrents for replacement: tag them in qBittorrent and mark their grab failed in Sonarr/Radarr, which blocklists the release and searches for another. Without confirm=true this only lists what would be flagged."),
+			mcp.WithArray("hashes", mcp.WithStringItems(), mcp.Description("Only these torrent hashes (default: every unregistered torrent)")),
+			mcp.WithBoolean("confirm", mcp.Description("Must be true to tag and mark failed")),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithOutputSchema[replaceResult](),
+		),
+		withQuietHours(searchAutomatic, handleUltimarrReplaceUnregistered),
+	)
+}
+
+func handleUltimarrTorrentHealth(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	category, _ := args["category"].(string)
+	limit := 10
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+
+	health, err := checkTorrentHealth(ctx, category)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	lines := []string{fmt.Sprintf("Checked %d completed torrents.", health.Checked)}
+	issueSection := func(title string, issues []torrentIssue) {
+		if len(issues) == 0 {
+			return
+		}
+		lines = append(lines, fmt.Sprintf("\n%s (%d):", title, len(issues)))
+		for _, i := range issues {
+			line := fmt.Sprintf("  %s [%s]", i.Name, i.Hash[:min(8, len(i.Hash))])
+			if i.Tracker != "" {
+				line += " - " + i.Tracker
+			}
+			lines = append(lines, line+": "+i.Message)
+		}
+	}
+	issueSection("Unregistered", health.Unregistered)
+	issueSection("Tracker not working", health.TrackerErrors)
+	issueSection("Missing files", health.MissingFiles)
+
+	if len(health.Unregistered)+len(health.TrackerErrors)+len(health.MissingFiles) == 0 {
+		lines = append(lines, "All torrents are registered and have a working tracker.")
+	}
+
+	lines = append(lines, fmt.Sprintf("\nCross-seeding: %d already seeded on several trackers, %d seeded on one tracker only.", health.CrossSeeded, len(health.CrossSeedCandidates)))
+	if len(health.CrossSeedCandidates) > 0 {
+		lines = append(lines, "Largest cross-seed candidates:")
+		for _, c := range health.CrossSeedCandidates[:min(limit, len(health.CrossSeedCandidates))] {
+			lines = append(lines, fmt.Sprintf("  %s (%s) - %s", c.Name, formatBytes(c.Size), c.Tracker))
+		}
+	}
+
+	if len(health.Unregistered) > 0 {
+		lines = append(lines, "\nUse ultimarr_replace_unregistered to get replacements for unregistered torrents.")
+	}
+	return mcp.NewToolResultStructured(health, strings.Join(lines, "\n")), nil
+}
+
+// replacedTorrent is one unregistered torrent handled by
+// ultimarr_replace_unregistered. Service is empty when neither Sonarr nor
+// Radarr grabbed it.
+type replacedTorrent struct {
+	Hash    string `json:"hash"`
+	Name    string `json:"name"`
+	Service string `json:"service,omitempty"`
+	Failed  bool   `json:"failed"`
+	Error   string `json:"error,omitempty"`
+}
+
+// replaceResult is the structured result of ultimarr_replace_unregistered.
+type replaceResult struct {
+	DryRun   bool              `json:"dryRun"`
+	Torrents []replacedTorrent `json:"torrents"`
+}
+
+func handleUltimarrReplaceUnregistered(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	confirm, _ := args["confirm"].(bool)
+	only := map[string]bool{}
+	if hashes, ok := args["hashes"].([]interface{}); ok {
+		for _, h := range hashes {
+			if s, ok := h.(string); ok {
+				only[strings.ToLower(s)] = true
+			}
+		}
+	}
+
+	health, err := checkTorrentHealth(ctx, "")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := replaceResult{DryRun: !confirm, Torrents: []replacedTorrent{}}
+	var hashes []string
+	for _, u := range health.Unregistered {
+		if len(only) > 0 && !only[strings.ToLower(u.Hash)] {
+			continue
+		}
+		t := replacedTorrent{Hash: u.Hash, Name: u.Name}
+		service, historyID, err := findGrab(ctx, u.Hash)
+		if err != nil {
+			t.Error = err.Error()
+		}
+		if service != nil {
+			t.Service = service.Name
+			if confirm {
+				if _, err := service.Request(ctx, "POST", fmt.Sprintf("/history/failed/%d", historyID), nil); err != nil {
+					t.Error = err.Error()
+				} else {
+					t.Failed = true
+				}
+			}
+		}
+		hashes = append(hashes, u.Hash)
+		result.Torrents = append(result.Torrents, t)
+	}
+
+	if len(result.Torrents) == 0 {
+		return mcp.NewToolResultStructured(result, "No unregistered torrents found."), nil
+	}
+
+	if confirm {
+		form := url.Values{"hashes": {strings.Join(hashes, "|")}, "tags": {unregisteredTag}}
+		if _, err := qbittorrentRequest(ctx, "POST", "/torrents/addTags", form); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("tagging torrents: %v", err)), nil
+		}
+	}
+
+	var lines []string
+	if confirm {
+		lines = append(lines, fmt.Sprintf("Tagged %d unregistered torrents %q:", len(result.Torrents), unregisteredTag))
+	} else {
+		lines = append(lines, fmt.Sprintf("Unregistered torrents (%d):", len(result.Torrents)))
+	}
+	for _, t := range result.Torrents {
+		line := "  " + t.Name
+		switch {
+		case t.Error != "":
+			line += " - error: " + t.Error
+		case t.Failed:
+			line += fmt.Sprintf(" - marked failed in %s, which will blocklist it and search for a replacement", t.Service)
+		case t.Service != "":
+			line += fmt.Sprintf(" - will be marked failed in %s", t.Service)
+		default:
+			line += " - not grabbed by Sonarr or Radarr; replace it manually"
+		}
+		lines = append(lines, line)
+	}
+	if !confirm {
+		lines = append(lines, "\nNothing has been changed. Call again with confirm=true to tag these torrents and mark them failed.")
+	}
+	return mcp.NewToolResultStructured(result, strings.Join(lines, "\n")), nil
+}
+
+// findGrab looks up which *arr grabbed a torrent and the ID of its "grabbed"
+// history record. It returns a nil service when none did; an error from one
+// *arr is only reported if none of the others grabbed it either.
+func findGrab(ctx context.Context, hash string) (*arrService, int, error) {
+	var firstErr error
+	for _, svc := range arrServices() {
+		data, err := svc.Request(ctx, "GET", "/history?pageSize=50&downloadId="+strings.ToUpper(hash), nil)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s history: %w", svc.Name, err)
+			}
+			continue
+		}
+		var page struct {
+			Records []map[string]interface{} `json:"records"`
+		}
+		json.Unmarshal(data, &page)
+		if grab := latestGrab(page.Records); grab != nil {
+			id, _ := grab["id"].(float64)
+			return &svc, int(id), nil
+		}
+	}
+	return nil, 0, firstErr
+}
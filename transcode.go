@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// Transcoding
+// ============================================================================
+
+// clientProfile describes what a user's usual playback devices handle without
+// the media server transcoding: the highest bitrate they direct play and
+// whether they decode HEVC and display HDR.
+type clientProfile struct {
+	MaxMbps float64 `json:"maxMbps"`
+	HEVC    bool    `json:"hevc"`
+	HDR     bool    `json:"hdr"`
+}
+
+// Profile used when no user is given or the user has none of their own
+const defaultClientProfile = "default"
+
+var (
+	hevcPattern = regexp.MustCompile(`(?i)\b(x265|h\.?265|hevc)\b`)
+	hdrPattern  = regexp.MustCompile(`(?i)\b(hdr(10)?(\+|plus)?|dv|dovi|dolby[ .]?vision)\b`)
+)
+
+// playbackOptions adds the user argument to the *_get_releases tools when
+// client profiles are configured.
+func playbackOptions() []mcp.ToolOption {
+	if len(config.ClientProfiles) == 0 {
+		return nil
+	}
+	var users []string
+	for name := range config.ClientProfiles {
+		users = append(users, name)
+	}
+	sort.Strings(users)
+	return []mcp.ToolOption{
+		mcp.WithString("user", mcp.Enum(users...),
+			mcp.Description("Who will watch it; releases their clients can't direct play are flagged (default: the 'default' profile)")),
+	}
+}
+
+// checkPlayback flags releases that user's clients would need transcoded.
+// raw holds the search results list was parsed from, and minutes returns the
+// running time a raw release covers (0 if unknown). When Jellyfin is
+// configured its current transcode count is recorded too, since a release
+// that needs transcoding adds to that load.
+func checkPlayback(ctx context.Context, list *releaseList, raw []map[string]interface{}, user string, minutes func(map[string]interface{}) int) {
+	if user == "" {
+		user = defaultClientProfile
+	}
+	profile, ok := config.ClientProfiles[user]
+	if !ok {
+		return
+	}
+
+	for i := range list.Releases {
+		rel := &list.Releases[i]
+		var reasons []string
+		if m := minutes(raw[i]); m > 0 {
+			rel.Mbps = float64(rel.Size) * 8 / float64(m*60) / 1e6
+			if profile.MaxMbps > 0 && rel.Mbps > profile.MaxMbps {
+				reasons = append(reasons, fmt.Sprintf("~%.0f Mbps is above %s's %.0f Mbps", rel.Mbps, user, profile.MaxMbps))
+			}
+		}
+		if !profile.HEVC && hevcPattern.MatchString(rel.Title) {
+			reasons = append(reasons, fmt.Sprintf("HEVC isn't supported by %s's clients", user))
+		}
+		if !profile.HDR && hdrPattern.MatchString(rel.Title) {
+			reasons = append(reasons, "HDR needs tone mapping")
+		}
+		if len(reasons) > 0 {
+			rel.Transcode = strings.Join(reasons, "; ")
+			list.NeedsTranscode++
+		}
+	}
+
+	if isConfigured(jellyfin) && list.NeedsTranscode > 0 {
+		if n, err := jellyfinTranscodes(ctx); err == nil {
+			list.ActiveTranscodes = &n
+		}
+	}
+}
+
+// releaseEpisodes returns how many episodes a Sonarr release covers: the
+// episodes it was matched to, or every episode of the season for a pack.
+func releaseEpisodes(series, r map[string]interface{}) int {
+	for _, field := range []string{"mappedEpisodeNumbers", "episodeNumbers"} {
+		if episodes, _ := r[field].([]interface{}); len(episodes) > 0 {
+			return len(episodes)
+		}
+	}
+	if full, _ := r["fullSeason"].(bool); !full {
+		return 0
+	}
+	season, ok := r["mappedSeasonNumber"].(float64)
+	if !ok {
+		season, _ = r["seasonNumber"].(float64)
+	}
+	seasons, _ := series["seasons"].([]interface{})
+	for _, se := range seasons {
+		se := se.(map[string]interface{})
+		if n, _ := se["seasonNumber"].(float64); n != season {
+			continue
+		}
+		stats, _ := se["statistics"].(map[string]interface{})
+		total, _ := stats["totalEpisodeCount"].(float64)
+		return int(total)
+	}
+	return 0
+}
+
+// jellyfinTranscodes counts sessions Jellyfin is currently transcoding video for.
+func jellyfinTranscodes(ctx context.Context) (int, error) {
+	data, err := jellyfinRequest(ctx, "GET", "/Sessions?activeWithinSeconds=60", nil)
+	if err != nil {
+		return 0, err
+	}
+	var sessions []struct {
+		NowPlayingItem  map[string]interface{}
+		TranscodingInfo *struct {
+			IsVideoDirect bool
+		}
+	}
+	json.Unmarshal(data, &sessions)
+
+	count := 0
+	for _, s := range sessions {
+		if s.NowPlayingItem != nil && s.TranscodingInfo != nil && !s.TranscodingInfo.IsVideoDirect {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ============================================================================
+// HTTP Transport
+// ============================================================================
+
+// With ULTIMARR_HTTP_ADDR the server speaks MCP over streamable HTTP at /mcp
+// instead of stdio, so several people's assistants can share one instance.
+// ULTIMARR_USERS gives each of them a bearer token, which identifies the user
+// and role that quotas are kept for.
+
+// apiUser is a person allowed to use the HTTP transport.
+type apiUser struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type userKey struct{}
+
+// userFromContext returns the user a tool call was made by. Calls over stdio,
+// or over HTTP without ULTIMARR_USERS, have none.
+func userFromContext(ctx context.Context) (apiUser, bool) {
+	u, ok := ctx.Value(userKey{}).(apiUser)
+	return u, ok
+}
+
+func serveHTTP(s *server.MCPServer, addr string) error {
+	// Without users anyone who can reach the address can use every tool
+	if len(config.Users) == 0 && !isLoopback(addr) {
+		return fmt.Errorf("ULTIMARR_USERS is not set, so ULTIMARR_HTTP_ADDR must be a loopback address such as 127.0.0.1:8080, not %q", addr)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", requireToken(server.NewStreamableHTTPServer(s)))
+	log.Printf("Serving MCP over HTTP on %s/mcp", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+// isLoopback reports whether a listen address only accepts local
+// connections. An address without a host listens on every interface.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// requireToken rejects requests without a known bearer token and attaches
+// the token's user to the rest.
+func requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(config.Users) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		user, ok := tokenUser(r.Header.Get("Authorization"))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="ultimarr"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+	})
+}
+
+// tokenUser looks up the user for an Authorization header. Every token is
+// compared, in constant time, so the response time doesn't reveal them.
+func tokenUser(header string) (apiUser, bool) {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
+		return apiUser{}, false
+	}
+	var user apiUser
+	found := false
+	for t, u := range config.Users {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			user, found = u, true
+		}
+	}
+	return user, found
+}
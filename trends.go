@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ============================================================================
+// Request Trends
+// ============================================================================
+
+// Default and maximum number of weeks jellyseerr_request_trends covers
+const (
+	defaultTrendWeeks = 12
+	maxTrendWeeks     = 52
+)
+
+// Genres listed in the trends summary
+const topTrendGenres = 5
+
+// requestTrends is the structured result of jellyseerr_request_trends.
+// FulfillmentRate is the share of requests that weren't declined whose media
+// is now available.
+type requestTrends struct {
+	Since               time.Time        `json:"since"`
+	Total               int              `json:"total"`
+	Weeks               []weekRequests   `json:"weeks"`
+	Genres              []genreCount     `json:"genres"`
+	Requesters          []requesterCount `json:"requesters"`
+	Declined            int              `json:"declined"`
+	Fulfilled           int              `json:"fulfilled"`
+	FulfillmentRate     float64          `json:"fulfillmentRate"`
+	AvgHoursToAvailable float64          `json:"avgHoursToAvailable,omitempty"`
+}
+
+type weekRequests struct {
+	Week   string `json:"week"` // Monday the week starts on, YYYY-MM-DD
+	Movies int    `json:"movies"`
+	TV     int    `json:"tv"`
+}
+
+type genreCount struct {
+	Genre    string `json:"genre"`
+	Requests int    `json:"requests"`
+}
+
+type requesterCount struct {
+	User     string `json:"user"`
+	Requests int    `json:"requests"`
+}
+
+var (
+	genreMu    sync.Mutex
+	genreCache = map[titleKey][]string{}
+)
+
+func handleJellyseerrRequestTrends(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	weeks := defaultTrendWeeks
+	if w, ok := args["weeks"].(float64); ok && w >= 1 {
+		weeks = min(int(w), maxTrendWeeks)
+	}
+
+	now := time.Now()
+	thisWeek := weekStart(now)
+	since := thisWeek.AddDate(0, 0, -7*(weeks-1))
+
+	requests, err := requestsSince(ctx, since)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	trends := requestTrends{Since: since, Total: len(requests), Weeks: []weekRequests{}, Genres: []genreCount{}, Requesters: []requesterCount{}}
+	byWeek := map[string]*weekRequests{}
+	for w := since; !w.After(thisWeek); w = w.AddDate(0, 0, 7) {
+		trends.Weeks = append(trends.Weeks, weekRequests{Week: w.Format("2006-01-02")})
+	}
+	for i := range trends.Weeks {
+		byWeek[trends.Weeks[i].Week] = &trends.Weeks[i]
+	}
+
+	var keys []titleKey
+	users := map[string]int{}
+	var waited time.Duration
+	waits := 0
+	for _, r := range requests {
+		createdAt, _ := r["createdAt"].(string)
+		created, _ := time.Parse(time.RFC3339, createdAt)
+		media, _ := r["media"].(map[string]interface{})
+		mediaType, _ := media["mediaType"].(string)
+		tmdbID, _ := media["tmdbId"].(float64)
+		keys = append(keys, titleKey{mediaType, int(tmdbID)})
+
+		if w := byWeek[weekStart(created).Format("2006-01-02")]; w != nil {
+			if mediaType == "tv" {
+				w.TV++
+			} else {
+				w.Movies++
+			}
+		}
+
+		user := "Unknown"
+		if rb, ok := r["requestedBy"].(map[string]interface{}); ok {
+			if dn, ok := rb["displayName"].(string); ok {
+				user = dn
+			}
+		}
+		users[user]++
+
+		if status, _ := r["status"].(float64); requestStatusNames[int(status)] == "Declined" {
+			trends.Declined++
+			continue
+		}
+		if status, _ := media["status"].(float64); mediaStatusNames[int(status)] != "available" {
+			continue
+		}
+		trends.Fulfilled++
+		// Titles already in the library (e.g. a request for more seasons)
+		// became available before they were requested
+		addedAt, _ := media["mediaAddedAt"].(string)
+		if added, err := time.Parse(time.RFC3339, addedAt); err == nil && added.After(created) {
+			waited += added.Sub(created)
+			waits++
+		}
+	}
+	if n := trends.Total - trends.Declined; n > 0 {
+		trends.FulfillmentRate = float64(trends.Fulfilled) / float64(n)
+	}
+	if waits > 0 {
+		trends.AvgHoursToAvailable = (waited / time.Duration(waits)).Hours()
+	}
+
+	// Genres come from each title's details; requests only carry IDs
+	prefetchGenres(ctx, keys)
+	genres := map[string]int{}
+	for _, k := range keys {
+		genreMu.Lock()
+		titleGenres := genreCache[k]
+		genreMu.Unlock()
+		for _, g := range titleGenres {
+			genres[g]++
+		}
+	}
+	for g, n := range genres {
+		trends.Genres = append(trends.Genres, genreCount{Genre: g, Requests: n})
+	}
+	sort.Slice(trends.Genres, func(i, j int) bool {
+		if trends.Genres[i].Requests != trends.Genres[j].Requests {
+			return trends.Genres[i].Requests > trends.Genres[j].Requests
+		}
+		return trends.Genres[i].Genre < trends.Genres[j].Genre
+	})
+	if len(trends.Genres) > topTrendGenres {
+		trends.Genres = trends.Genres[:topTrendGenres]
+	}
+	for u, n := range users {
+		trends.Requesters = append(trends.Requesters, requesterCount{User: u, Requests: n})
+	}
+	sort.Slice(trends.Requesters, func(i, j int) bool {
+		if trends.Requesters[i].Requests != trends.Requesters[j].Requests {
+			return trends.Requesters[i].Requests > trends.Requesters[j].Requests
+		}
+		return trends.Requesters[i].User < trends.Requesters[j].User
+	})
+
+	lines := []string{fmt.Sprintf("Requests over the last %d week(s) (since %s): %d", weeks, since.Format("2006-01-02"), trends.Total), ""}
+	lines = append(lines, "Per week (movies / TV):")
+	for _, w := range trends.Weeks {
+		lines = append(lines, fmt.Sprintf("  %s  %3d / %-3d %s", w.Week, w.Movies, w.TV, strings.Repeat("#", w.Movies+w.TV)))
+	}
+	if len(trends.Genres) > 0 {
+		var top []string
+		for _, g := range trends.Genres {
+			top = append(top, fmt.Sprintf("%s (%d)", g.Genre, g.Requests))
+		}
+		lines = append(lines, "", "Most requested genres: "+strings.Join(top, ", "))
+	}
+	if len(trends.Requesters) > 0 {
+		var top []string
+		for _, u := range trends.Requesters {
+			top = append(top, fmt.Sprintf("%s (%d)", u.User, u.Requests))
+		}
+		lines = append(lines, "Requests by user: "+strings.Join(top, ", "))
+	}
+	if trends.Total > 0 {
+		lines = append(lines, "", fmt.Sprintf("Fulfilled: %d of %d not declined (%.0f%%); %d declined", trends.Fulfilled, trends.Total-trends.Declined, 100*trends.FulfillmentRate, trends.Declined))
+	}
+	if waits > 0 {
+		lines = append(lines, fmt.Sprintf("Average time from request to available: %s (over %d request(s))", formatHours(trends.AvgHoursToAvailable), waits))
+	}
+	return mcp.NewToolResultStructured(trends, strings.Join(lines, "\n")), nil
+}
+
+// requestsSince pages through Jellyseerr's requests, newest first, until it
+// reaches ones made before since.
+func requestsSince(ctx context.Context, since time.Time) ([]map[string]interface{}, error) {
+	const pageSize = 100
+	var requests []map[string]interface{}
+	for skip := 0; ; skip += pageSize {
+		data, err := jellyseerrRequest(ctx, "GET", fmt.Sprintf("/request?take=%d&skip=%d&sort=added", pageSize, skip), nil)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Results []map[string]interface{} `json:"results"`
+		}
+		json.Unmarshal(data, &page)
+		for _, r := range page.Results {
+			createdAt, _ := r["createdAt"].(string)
+			created, err := time.Parse(time.RFC3339, createdAt)
+			if err != nil {
+				continue
+			}
+			if created.Before(since) {
+				return requests, nil
+			}
+			requests = append(requests, r)
+		}
+		if len(page.Results) < pageSize {
+			return requests, nil
+		}
+	}
+}
+
+// prefetchGenres looks up the genres of the titles in keys that aren't
+// cached yet, several at a time. The details also fill the title cache.
+func prefetchGenres(ctx context.Context, keys []titleKey) {
+	jobs := make(chan titleKey)
+	var wg sync.WaitGroup
+	for i := 0; i < titleLookupWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range jobs {
+				data, err := jellyseerrRequest(ctx, "GET", fmt.Sprintf("/%s/%d", k.MediaType, k.TmdbID), nil)
+				if err != nil {
+					continue
+				}
+				var details struct {
+					Title  string `json:"title"`
+					Name   string `json:"name"`
+					Genres []struct {
+						Name string `json:"name"`
+					} `json:"genres"`
+				}
+				json.Unmarshal(data, &details)
+				rememberTitle(k.MediaType, k.TmdbID, details.Title+details.Name)
+
+				var names []string
+				for _, g := range details.Genres {
+					names = append(names, g.Name)
+				}
+				genreMu.Lock()
+				if len(genreCache) >= maxCachedTitles {
+					genreCache = map[titleKey][]string{}
+				}
+				genreCache[k] = names
+				genreMu.Unlock()
+			}
+		}()
+	}
+
+	seen := map[titleKey]bool{}
+	for _, k := range keys {
+		genreMu.Lock()
+		_, cached := genreCache[k]
+		genreMu.Unlock()
+		if cached || seen[k] {
+			continue
+		}
+		seen[k] = true
+		jobs <- k
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// weekStart returns midnight on the Monday of t's week.
+func weekStart(t time.Time) time.Time {
+	t = t.Local()
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.Local)
+}
+
+// formatHours renders a duration in hours as days and hours.
+func formatHours(h float64) string {
+	if h < 24 {
+		return fmt.Sprintf("%.1f hours", h)
+	}
+	return fmt.Sprintf("%.1f days", h/24)
+}